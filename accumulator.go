@@ -0,0 +1,189 @@
+package beacon
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"sync"
+	"time"
+)
+
+// accumulatorPools is the number of pools of an Accumulator, as in Fortuna
+const accumulatorPools = 32
+
+// maxGenerated bounds the bytes an Accumulator generates before changing its key, as Fortuna does
+const maxGenerated = 1 << 20
+
+// minReseedInterval is the minimum interval between two reseeds of an Accumulator
+const minReseedInterval = 100 * time.Millisecond
+
+// Accumulator is an entropy pool in the style of Fortuna, seeded from the local CSPRNG and fed with pulses: every pulse added is hashed
+// along with fresh local randomness into one of 32 pools in turn, and the pools are folded into the generator's key from time to time,
+// pool i on every 2^i-th reseed. Its output depends on the pulses but stays secret, unlike the values derived from pulses alone, which makes
+// it fit for long-running simulations that must be both unpredictable and influenced by the beacon. It is safe for concurrent use.
+type Accumulator struct {
+	mu      sync.Mutex
+	pools   [accumulatorPools]hash.Hash
+	filled  [accumulatorPools]bool
+	next    int
+	reseeds uint64
+	// lastReseed rate limits reseeds so that pool 0 can't be drained on every read
+	lastReseed time.Time
+
+	key     [32]byte
+	counter [aes.BlockSize]byte
+	block   cipher.Block
+
+	lastErr error
+}
+
+// NewAccumulator returns an accumulator keyed from the local CSPRNG, ready to be read from before any pulse is added
+func NewAccumulator() (*Accumulator, error) {
+	a := &Accumulator{}
+	for i := range a.pools {
+		a.pools[i] = sha256.New()
+	}
+	if _, err := io.ReadFull(rand.Reader, a.key[:]); err != nil {
+		return nil, err
+	}
+	if err := a.rekey(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// rekey sets up the cipher for the current key
+func (a *Accumulator) rekey() error {
+	block, err := aes.NewCipher(a.key[:])
+	if err != nil {
+		return err
+	}
+	a.block = block
+	return nil
+}
+
+// AddPulse folds rec into the accumulator, with 32 bytes of local randomness. The pulse should have been verified: the accumulator only
+// counts on it to influence its output, its secrecy comes from the local randomness.
+func (a *Accumulator) AddPulse(rec Record) error {
+	out, err := rec.outputBytes()
+	if err != nil {
+		return err
+	}
+	var local [32]byte
+	if _, err := io.ReadFull(rand.Reader, local[:]); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p := a.pools[a.next]
+	// the events of Fortuna, each prefixed with its length
+	for _, e := range [][]byte{out, binary.BigEndian.AppendUint64(nil, uint64(rec.Pulse.PulseIndex)), local[:]} {
+		p.Write([]byte{byte(len(e))})
+		p.Write(e)
+	}
+	a.filled[a.next] = true
+	a.next = (a.next + 1) % accumulatorPools
+	return nil
+}
+
+// reseed folds the pools due into the key, if pool 0 was fed since the last reseed and that was long enough ago
+func (a *Accumulator) reseed(now time.Time) error {
+	if !a.filled[0] || now.Sub(a.lastReseed) < minReseedInterval {
+		return nil
+	}
+	a.reseeds++
+	a.lastReseed = now
+
+	h := sha256.New()
+	h.Write(a.key[:])
+	for i := range a.pools {
+		if a.reseeds%(1<<i) != 0 {
+			break
+		}
+		h.Write(a.pools[i].Sum(nil))
+		a.pools[i].Reset()
+		a.filled[i] = false
+	}
+	// SHA-256d, as Fortuna
+	first := h.Sum(nil)
+	a.key = sha256.Sum256(first)
+	clear(first)
+	return a.rekey()
+}
+
+// generate fills p with the key stream, p being at most maxGenerated bytes long
+func (a *Accumulator) generate(p []byte) {
+	var block [aes.BlockSize]byte
+	for len(p) > 0 {
+		a.block.Encrypt(block[:], a.counter[:])
+		n := copy(p, block[:])
+		p = p[n:]
+		// increment the little-endian counter
+		for i := range a.counter {
+			a.counter[i]++
+			if a.counter[i] != 0 {
+				break
+			}
+		}
+	}
+	clear(block[:])
+}
+
+// Read fills p with random bytes. It never fails once the accumulator has been created.
+func (a *Accumulator) Read(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.reseed(time.Now()); err != nil {
+		return 0, err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxGenerated)]
+		a.generate(chunk)
+		p = p[len(chunk):]
+		// change the key after every request, so that a later compromise doesn't reveal past outputs
+		a.generate(a.key[:])
+		if err := a.rekey(); err != nil {
+			return n - len(p), err
+		}
+	}
+	return n, nil
+}
+
+// LastError returns the error of the last pulse Follow failed to add, or nil if the last one was added
+func (a *Accumulator) LastError() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastErr
+}
+
+// Follow watches c and adds every new pulse to the accumulator once c verified it, until ctx is done or the returned watcher is stopped.
+// Pulses failing verification are skipped and reported by LastError.
+func (a *Accumulator) Follow(ctx context.Context, c *Client, opts ...WatcherOption) *Watcher {
+	setErr := func(err error) {
+		a.mu.Lock()
+		a.lastErr = err
+		a.mu.Unlock()
+	}
+	add := func(rec Record) {
+		err := c.Verify(ctx, rec)
+		if err == nil {
+			err = a.AddPulse(rec)
+		}
+		setErr(err)
+	}
+	// clients verifying the records they fetch fail the polls of forged pulses instead of returning them
+	rejected := func(_ Record, err error) {
+		if errors.Is(err, ErrSignatureInvalid) {
+			setErr(err)
+		}
+	}
+	return c.Watch(ctx, append(opts, WithCallback(add), WithErrorCallback(rejected))...)
+}
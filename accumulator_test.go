@@ -0,0 +1,121 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAccumulator(t *testing.T) {
+	recs := fixtureChain(t, 3)
+	a, err := NewAccumulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewAccumulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range recs {
+		if err := a.AddPulse(rec); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.AddPulse(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	x, y, z := make([]byte, 64), make([]byte, 64), make([]byte, 64)
+	a.Read(x)
+	a.Read(y)
+	b.Read(z)
+	if bytes.Equal(x, y) || bytes.Equal(x, z) || bytes.Equal(x, make([]byte, 64)) {
+		t.Error("expected distinct outputs, secret to each accumulator")
+	}
+	if a.reseeds != 1 || a.filled[0] {
+		t.Errorf("expected pool 0 to be folded into the key on the first read, %d reseeds", a.reseeds)
+	}
+
+	// a read larger than what is generated under one key
+	big := make([]byte, 3*maxGenerated+5)
+	if n, err := a.Read(big); n != len(big) || err != nil {
+		t.Errorf("read %d bytes: %v", n, err)
+	}
+	if bytes.Equal(big[:64], big[maxGenerated:maxGenerated+64]) {
+		t.Error("expected the key to change between chunks")
+	}
+
+	badRec := recs[0]
+	badRec.Pulse.OutputValue = "zz"
+	if err := a.AddPulse(badRec); err == nil {
+		t.Error("expected a malformed pulse to be rejected")
+	}
+}
+
+func TestAccumulatorReseedSchedule(t *testing.T) {
+	a, err := NewAccumulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := fixtureRecord(t)
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		for range accumulatorPools {
+			a.AddPulse(rec)
+		}
+		now = now.Add(time.Second)
+		if err := a.reseed(now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// reseeds 1 to 4 drained pool 0 every time, pool 1 on reseeds 2 and 4, pool 2 on reseed 4
+	if a.filled[0] || a.filled[1] || a.filled[2] || !a.filled[3] {
+		t.Errorf("unexpected pools drained after %d reseeds: %v", a.reseeds, a.filled[:4])
+	}
+
+	key := a.key
+	a.AddPulse(rec)
+	a.reseed(now.Add(time.Millisecond))
+	if a.key != key {
+		t.Error("expected reseeds to be rate limited")
+	}
+}
+
+func TestAccumulatorFollow(t *testing.T) {
+	var forge atomic.Bool
+	c := signingClient(t, 50*time.Millisecond, &forge)
+
+	a, err := NewAccumulator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := a.Follow(context.Background(), c, WithRetryInterval(10*time.Millisecond))
+	defer w.Stop()
+
+	waitFor(t, "a pulse to be added", func() bool {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		return a.next > 1
+	})
+	if err := a.LastError(); err != nil {
+		t.Fatal(err)
+	}
+
+	forge.Store(true)
+	waitFor(t, "a forged pulse to be rejected", func() bool { return errors.Is(a.LastError(), ErrSignatureInvalid) })
+}
+
+// waitFor polls cond until it holds, failing the test if it doesn't within two seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for " + what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,75 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AnchorProof proves that a pulse belongs to its chain: every pulse of Path, from the pulse to the trusted Anchor, references the
+// output value of the one before it
+type AnchorProof struct {
+	Anchor Record
+	Path   []Record
+}
+
+// VerifyAnchorProof checks that p links rec to p.Anchor. It is only as trustworthy as the anchor, which the caller must have verified.
+func VerifyAnchorProof(p *AnchorProof, rec Record) error {
+	if len(p.Path) == 0 || !p.Path[0].Equal(rec) {
+		return errors.New("The path doesn't start at the pulse")
+	}
+	if !p.Path[len(p.Path)-1].Equal(p.Anchor) {
+		return errors.New("The path doesn't end at the anchor")
+	}
+	return VerifySkiplist(p.Path)
+}
+
+// VerifyAnchored checks that rec belongs to its chain by following the list values from it to a trusted anchor, so that pulses signed
+// by expired or revoked certificates can be verified without checking their signature. The anchors are pulses the caller verified
+// earlier, such as a recent pulse or a sparse set of archived checkpoints; the one following rec on its chain with the shortest skiplist
+// path to it is used. Without such an anchor, the latest pulse is fetched and verified with Verify to serve as the anchor.
+func (c *Client) VerifyAnchored(ctx context.Context, rec Record, anchors ...Record) (*AnchorProof, error) {
+	var best *Record
+	bestLen := 0
+	for i := range anchors {
+		a := &anchors[i]
+		if a.Pulse.ChainIndex != rec.Pulse.ChainIndex || a.Pulse.PulseIndex < rec.Pulse.PulseIndex {
+			continue
+		}
+		if n := skiplistLength(rec.Pulse.TimeStamp, a.Pulse.TimeStamp, recordPeriod(rec)); best == nil || n < bestLen {
+			best, bestLen = a, n
+		}
+	}
+	if best == nil {
+		last, err := c.LastRecord(ctx)
+		if err != nil && !errors.Is(err, ErrStale) {
+			return nil, fmt.Errorf("Couldn't fetch an anchor: %w", err)
+		}
+		if last.Pulse.ChainIndex != rec.Pulse.ChainIndex {
+			return nil, errors.New("No anchor follows the pulse on its chain")
+		}
+		if err := c.Verify(ctx, last); err != nil {
+			return nil, fmt.Errorf("Couldn't verify the anchor: %w", err)
+		}
+		best = &last
+	}
+
+	p := &AnchorProof{Anchor: *best}
+	if best.Pulse.PulseIndex == rec.Pulse.PulseIndex {
+		if !strings.EqualFold(best.Pulse.OutputValue, rec.Pulse.OutputValue) {
+			return nil, errors.New("The pulse doesn't match the anchor")
+		}
+		p.Path = []Record{rec}
+		return p, nil
+	}
+	path, err := c.SkiplistPath(ctx, rec, *best)
+	if err != nil {
+		return nil, err
+	}
+	p.Path = path
+	if err := VerifyAnchorProof(p, rec); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
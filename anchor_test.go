@@ -0,0 +1,61 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestVerifyAnchored(t *testing.T) {
+	// four days of pulses every ten minutes
+	origin := time.Now().UTC().AddDate(0, 0, -4).Truncate(time.Hour)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithPeriod(10*time.Minute))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	at := func(d time.Duration) beacon.Record {
+		rec, err := c.CurrentRecord(ctx, origin.Add(d))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+	old, near, far := at(25*time.Minute), at(2*time.Hour+10*time.Minute), at(3*24*time.Hour)
+
+	p, err := c.VerifyAnchored(ctx, old, far, near)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Anchor.Equal(near) {
+		t.Errorf("anchored to pulse %d rather than to the nearest anchor %d", p.Anchor.Pulse.PulseIndex, near.Pulse.PulseIndex)
+	}
+	if err := beacon.VerifyAnchorProof(p, old); err != nil {
+		t.Error(err)
+	}
+
+	// without an anchor, the latest pulse serves as one
+	p, err = c.VerifyAnchored(ctx, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := srv.Records(); !p.Anchor.Equal(last[len(last)-1]) {
+		t.Errorf("anchored to pulse %d rather than to the latest one", p.Anchor.Pulse.PulseIndex)
+	}
+
+	if p, err := c.VerifyAnchored(ctx, near, near); err != nil || len(p.Path) != 1 {
+		t.Errorf("expected an anchor to prove itself, got %v", err)
+	}
+
+	tampered := old
+	tampered.Pulse.OutputValue = near.Pulse.OutputValue
+	if _, err := c.VerifyAnchored(ctx, tampered, near); err == nil {
+		t.Error("expected a tampered pulse to fail")
+	}
+	if err := beacon.VerifyAnchorProof(p, near); err == nil {
+		t.Error("expected a proof of another pulse to fail")
+	}
+}
@@ -0,0 +1,202 @@
+// Package archive durably stores beacon records, so every pulse an application used can be retained, queried and re-verified locally
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// ErrNotFound is returned when the archive doesn't hold the requested record, it matches beacon.ErrNotFound
+var ErrNotFound = fmt.Errorf("%w in the archive", beacon.ErrNotFound)
+
+// Store is where an Archive keeps its records. The boltstore package stores them in a bbolt database and the fscache package in a
+// directory; other databases, such as Postgres or DynamoDB, are plugged in by implementing Store and passing it to New. The archive
+// verifies the chain on top of the store, which only has to index records. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores rec, with the bytes of rec.Raw if it retained them, and marks it verified if verified is true. Storing a record with
+	// the chain and pulse index of a stored one is a no-op, except that the stored one is then marked verified if verified is true.
+	Put(rec beacon.Record, verified bool) error
+	// Get returns the record whose pulse timestamp is t. Records that aren't stored are reported by an error matching
+	// beacon.ErrNotFound, by this method and the others.
+	Get(t time.Time) (beacon.Record, error)
+	// GetByIndex returns the record with the given chain and pulse index
+	GetByIndex(chain, pulse int) (beacon.Record, error)
+	// Range returns the records with a pulse timestamp in [from, to], ordered by timestamp
+	Range(from, to time.Time) ([]beacon.Record, error)
+	// Latest returns the record with the most recent pulse timestamp
+	Latest() (beacon.Record, error)
+	// LatestVerified returns the record with the most recent pulse timestamp among those marked verified
+	LatestVerified() (beacon.Record, error)
+}
+
+// Seeker is implemented by the stores finding the earliest record at or after a timestamp without a range query
+type Seeker interface {
+	// Seek returns the record with the earliest pulse timestamp at or after t
+	Seek(t time.Time) (beacon.Record, error)
+}
+
+// Archive stores records, indexed by pulse timestamp and by chain and pulse index, checking the links between them. It is safe for concurrent use.
+type Archive struct {
+	store Store
+	// mu serializes the puts, whose links are checked against the records around them before they are stored
+	mu sync.Mutex
+}
+
+// New returns an archive keeping its records in s
+func New(s Store) *Archive {
+	return &Archive{store: s}
+}
+
+// Close closes the underlying store if it is an io.Closer
+func (a *Archive) Close() error {
+	if c, ok := a.store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// notFound reports the records missing from the store as ErrNotFound
+func notFound(err error) error {
+	if err != nil && errors.Is(err, beacon.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Put stores rec. If the archive already holds the records before or after it in the chain, the links between them are verified and
+// rec is rejected if they don't match. Storing a record that is already archived is a no-op, and storing one that differs from the
+// archived pulse with its chain and pulse index is an error. The raw bytes of rec are stored too if it retained them, and records read
+// back from the archive return them from Raw.
+func (a *Archive) Put(rec beacon.Record) error {
+	return a.put(rec, false)
+}
+
+// PutVerified stores rec as Put does and marks it verified, so LatestVerified may return it. The caller must have verified it, typically
+// with beacon.Client.Verify. Storing a record that is already archived marks it verified.
+func (a *Archive) PutVerified(rec beacon.Record) error {
+	return a.put(rec, true)
+}
+
+func (a *Archive) put(rec beacon.Record, verified bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if old, err := a.store.GetByIndex(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex); err == nil {
+		if !strings.EqualFold(old.Pulse.OutputValue, rec.Pulse.OutputValue) {
+			return fmt.Errorf("Record differs from the archived pulse %d of chain %d", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex)
+		}
+		if !verified {
+			return nil
+		}
+		return a.store.Put(rec, true)
+	} else if !errors.Is(err, beacon.ErrNotFound) {
+		return err
+	}
+
+	prev, err := a.store.GetByIndex(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex-1)
+	if err == nil {
+		if err := rec.VerifyLink(prev); err != nil {
+			return fmt.Errorf("Record doesn't link to the archived previous record: %w", err)
+		}
+	} else if !errors.Is(err, beacon.ErrNotFound) {
+		return err
+	}
+
+	next, err := a.store.GetByIndex(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex+1)
+	if err == nil {
+		if err := next.VerifyLink(rec); err != nil {
+			return fmt.Errorf("Archived next record doesn't link to the record: %w", err)
+		}
+	} else if !errors.Is(err, beacon.ErrNotFound) {
+		return err
+	}
+
+	return a.store.Put(rec, verified)
+}
+
+// Get returns the record whose pulse timestamp is t
+func (a *Archive) Get(t time.Time) (beacon.Record, error) {
+	rec, err := a.store.Get(t)
+	return rec, notFound(err)
+}
+
+// GetByIndex returns the record with the given chain and pulse index
+func (a *Archive) GetByIndex(chain, pulse int) (beacon.Record, error) {
+	rec, err := a.store.GetByIndex(chain, pulse)
+	return rec, notFound(err)
+}
+
+// Range returns the archived records with a pulse timestamp in [from, to], ordered by timestamp
+func (a *Archive) Range(from, to time.Time) ([]beacon.Record, error) {
+	return a.store.Range(from, to)
+}
+
+// Latest returns the archived record with the most recent pulse timestamp
+func (a *Archive) Latest() (beacon.Record, error) {
+	rec, err := a.store.Latest()
+	return rec, notFound(err)
+}
+
+// LatestVerified returns the archived record with the most recent pulse timestamp among those stored with PutVerified, or synced by a
+// Syncer. It makes a trusted anchor for beacon.Client.VerifyAnchored.
+func (a *Archive) LatestVerified() (beacon.Record, error) {
+	rec, err := a.store.LatestVerified()
+	return rec, notFound(err)
+}
+
+// seek returns the archived record with the earliest pulse timestamp at or after t. Stores that can't seek are searched with range
+// queries over windows doubling from a day.
+func (a *Archive) seek(t time.Time) (beacon.Record, error) {
+	if s, ok := a.store.(Seeker); ok {
+		rec, err := s.Seek(t)
+		return rec, notFound(err)
+	}
+	latest, err := a.Latest()
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	for d := 24 * time.Hour; !t.After(latest.Pulse.TimeStamp); d *= 2 {
+		recs, err := a.store.Range(t, t.Add(d-time.Millisecond))
+		if err != nil {
+			return beacon.Record{}, err
+		}
+		if len(recs) > 0 {
+			return recs[0], nil
+		}
+		t = t.Add(d)
+	}
+	return beacon.Record{}, ErrNotFound
+}
+
+// GapReport reports the gaps, chain restarts and inconsistencies of the archived records with a pulse timestamp in [from, to]
+func (a *Archive) GapReport(from, to time.Time) (*beacon.GapReport, error) {
+	recs, err := a.Range(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return beacon.NewGapReport(from, to, recs), nil
+}
+
+type cache struct {
+	a *Archive
+}
+
+// Cache returns a beacon.Cache backed by the archive, so a Client configured with beacon.WithCache answers historical queries locally
+// and archives every record it fetches. Records that can't be archived are silently not cached.
+func (a *Archive) Cache() beacon.Cache {
+	return cache{a}
+}
+
+func (c cache) Get(t time.Time) (beacon.Record, bool) {
+	rec, err := c.a.Get(t)
+	return rec, err == nil
+}
+
+func (c cache) Put(t time.Time, rec beacon.Record) {
+	c.a.Put(rec)
+}
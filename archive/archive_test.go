@@ -0,0 +1,258 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+func testChain(t *testing.T, n int) []beacon.Record {
+	buf, err := ioutil.ReadFile("../testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs []beacon.Record
+	for i := 0; i < n; i++ {
+		var rec beacon.Record
+		if err := json.Unmarshal(buf, &rec); err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 {
+			prev := recs[i-1]
+			rec.Pulse.PulseIndex = prev.Pulse.PulseIndex + 1
+			rec.Pulse.TimeStamp = prev.Pulse.TimeStamp.Add(time.Minute)
+			rec.Pulse.OutputValue = prev.Pulse.OutputValue[1:] + prev.Pulse.OutputValue[:1]
+			for j := range rec.Pulse.ListValues {
+				if rec.Pulse.ListValues[j].Type == "previous" {
+					rec.Pulse.ListValues[j].Value = prev.Pulse.OutputValue
+				}
+			}
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func openTest(t *testing.T) *Archive {
+	return New(&memStore{verified: make(map[int]bool)})
+}
+
+func TestArchive(t *testing.T) {
+	a := openTest(t)
+	recs := testChain(t, 5)
+	for _, i := range []int{0, 2, 1, 4, 3} {
+		if err := a.Put(recs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec, err := a.Get(recs[2].Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex != recs[2].Pulse.PulseIndex {
+		t.Errorf("got pulse %d, expected %d", rec.Pulse.PulseIndex, recs[2].Pulse.PulseIndex)
+	}
+
+	rec, err = a.GetByIndex(recs[3].Pulse.ChainIndex, recs[3].Pulse.PulseIndex)
+	if err != nil || rec.Pulse.OutputValue != recs[3].Pulse.OutputValue {
+		t.Errorf("couldn't get the record by index: %v", err)
+	}
+
+	got, err := a.Range(recs[1].Pulse.TimeStamp, recs[3].Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || beacon.VerifyChain(got) != nil {
+		t.Errorf("unexpected range of %d records", len(got))
+	}
+
+	rec, err = a.Latest()
+	if err != nil || rec.Pulse.PulseIndex != recs[4].Pulse.PulseIndex {
+		t.Errorf("unexpected latest record: %v", err)
+	}
+
+	report, err := a.GapReport(recs[1].Pulse.TimeStamp, recs[4].Pulse.TimeStamp)
+	if err != nil || report.Pulses != 4 || len(report.Gaps) != 0 {
+		t.Errorf("unexpected gap report %+v: %v", report, err)
+	}
+
+	if _, err := a.Get(time.Unix(0, 0)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestArchiveRejectsBrokenChain(t *testing.T) {
+	a := openTest(t)
+	recs := testChain(t, 3)
+	if err := a.Put(recs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	recs[1].Pulse.ListValues[0].Value = recs[2].Pulse.OutputValue
+	if err := a.Put(recs[1]); err == nil {
+		t.Error("expected a record not linking to its predecessor to be rejected")
+	}
+}
+
+// memStore is a Store that can't seek, as the stores plugged in by users
+type memStore struct {
+	mu       sync.Mutex
+	recs     []beacon.Record
+	verified map[int]bool
+}
+
+func (s *memStore) Put(rec beacon.Record, verified bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, found := slices.BinarySearchFunc(s.recs, rec.Pulse.TimeStamp, func(r beacon.Record, t time.Time) int { return r.Pulse.TimeStamp.Compare(t) })
+	if !found {
+		s.recs = slices.Insert(s.recs, i, rec)
+	}
+	if verified {
+		s.verified[rec.Pulse.PulseIndex] = true
+	}
+	return nil
+}
+
+func (s *memStore) find(match func(beacon.Record) bool) (beacon.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.recs) - 1; i >= 0; i-- {
+		if match(s.recs[i]) {
+			return s.recs[i], nil
+		}
+	}
+	return beacon.Record{}, beacon.ErrNotFound
+}
+
+func (s *memStore) Get(t time.Time) (beacon.Record, error) {
+	return s.find(func(r beacon.Record) bool { return r.Pulse.TimeStamp.Equal(t) })
+}
+
+func (s *memStore) GetByIndex(chain, pulse int) (beacon.Record, error) {
+	return s.find(func(r beacon.Record) bool { return r.Pulse.ChainIndex == chain && r.Pulse.PulseIndex == pulse })
+}
+
+func (s *memStore) Range(from, to time.Time) ([]beacon.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var recs []beacon.Record
+	for _, r := range s.recs {
+		if !r.Pulse.TimeStamp.Before(from) && !r.Pulse.TimeStamp.After(to) {
+			recs = append(recs, r)
+		}
+	}
+	return recs, nil
+}
+
+func (s *memStore) Latest() (beacon.Record, error) {
+	return s.find(func(beacon.Record) bool { return true })
+}
+
+func (s *memStore) LatestVerified() (beacon.Record, error) {
+	return s.find(func(r beacon.Record) bool { return s.verified[r.Pulse.PulseIndex] })
+}
+
+// seekStore is a memStore that can seek
+type seekStore struct {
+	*memStore
+}
+
+func (s seekStore) Seek(t time.Time) (beacon.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.recs {
+		if !r.Pulse.TimeStamp.Before(t) {
+			return r, nil
+		}
+	}
+	return beacon.Record{}, beacon.ErrNotFound
+}
+
+func TestArchiveStores(t *testing.T) {
+	recs := testChain(t, 4)
+	for name, a := range map[string]*Archive{"custom": openTest(t), "seeker": New(seekStore{openTest(t).store.(*memStore)})} {
+		t.Run(name, func(t *testing.T) {
+			if err := a.Put(recs[3]); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := a.LatestVerified(); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected no verified record yet, got %v", err)
+			}
+			for _, rec := range recs[1:3] {
+				if err := a.PutVerified(rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if rec, err := a.LatestVerified(); err != nil || rec.Pulse.PulseIndex != recs[2].Pulse.PulseIndex {
+				t.Errorf("unexpected latest verified record: %v", err)
+			}
+			if err := a.PutVerified(recs[3]); err != nil {
+				t.Fatal(err)
+			}
+			if rec, err := a.LatestVerified(); err != nil || rec.Pulse.PulseIndex != recs[3].Pulse.PulseIndex {
+				t.Errorf("expected the archived record to be marked verified: %v", err)
+			}
+
+			forged := recs[0]
+			forged.Pulse.PulseIndex = recs[1].Pulse.PulseIndex
+			if err := a.PutVerified(forged); err == nil {
+				t.Error("expected a record differing from the archived one not to be marked verified")
+			}
+			if err := a.Put(forged); err == nil {
+				t.Error("expected a record differing from the archived one to be rejected")
+			}
+			broken := recs[0]
+			broken.Pulse.OutputValue = recs[3].Pulse.OutputValue
+			if err := a.Put(broken); err == nil {
+				t.Error("expected a record not linking to its successor to be rejected")
+			}
+
+			if rec, err := a.seek(time.Unix(0, 0)); err != nil || rec.Pulse.PulseIndex != recs[1].Pulse.PulseIndex {
+				t.Errorf("unexpected first record: %v", err)
+			}
+			if _, err := a.GetByIndex(0, 0); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+// slowStore widens the window between the link checks of a put and its write
+type slowStore struct {
+	*memStore
+}
+
+func (s slowStore) GetByIndex(chain, pulse int) (beacon.Record, error) {
+	time.Sleep(time.Millisecond)
+	return s.memStore.GetByIndex(chain, pulse)
+}
+
+func TestArchiveConcurrentPuts(t *testing.T) {
+	recs := testChain(t, 2)
+	broken := recs[1]
+	broken.Pulse.ListValues[0].Value = recs[1].Pulse.OutputValue
+	for i := 0; i < 10; i++ {
+		a := New(slowStore{&memStore{verified: make(map[int]bool)}})
+		errs := make(chan error, 2)
+		for _, rec := range []beacon.Record{recs[0], broken} {
+			go func() { errs <- a.Put(rec) }()
+		}
+		failed := 0
+		for range 2 {
+			if <-errs != nil {
+				failed++
+			}
+		}
+		if failed != 1 {
+			t.Fatalf("expected one of two records not linking to each other to be rejected, %d were", failed)
+		}
+	}
+}
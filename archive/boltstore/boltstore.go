@@ -0,0 +1,230 @@
+// Package boltstore keeps archives in a bbolt database, a single file holding the records with their indexes
+package boltstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	recordsBucket = []byte("records")
+	timeBucket    = []byte("time")
+	// rawBucket holds the bytes served by the beacon for the records that retained them, keyed like recordsBucket
+	rawBucket = []byte("raw")
+	// verifiedBucket indexes the verified records by pulse timestamp, like timeBucket
+	verifiedBucket = []byte("verified")
+)
+
+// store is the archive.Store of the archives returned by Open
+type store struct {
+	db *bolt.DB
+}
+
+var _ archive.Seeker = (*store)(nil)
+
+// Open opens the archive stored in the bbolt database at path, creating it if needed
+func Open(path string) (*archive.Archive, error) {
+	s, err := openStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return archive.New(s), nil
+}
+
+func openStore(path string) (*store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't open the archive: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{recordsBucket, timeBucket, rawBucket, verifiedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("Couldn't initialize the archive: %w", err)
+	}
+	return &store{db: db}, nil
+}
+
+// OpenReadOnly opens the archive stored in the bbolt database at path without writing to it, for audits. Its records can't be put.
+func OpenReadOnly(path string) (*archive.Archive, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't open the archive: %w", err)
+	}
+	err = db.View(func(tx *bolt.Tx) error {
+		// the raw and verified buckets are missing from the databases of older versions
+		for _, b := range [][]byte{recordsBucket, timeBucket} {
+			if tx.Bucket(b) == nil {
+				return fmt.Errorf("%s isn't an archive database", path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return archive.New(&store{db: db}), nil
+}
+
+// Close closes the underlying database
+func (s *store) Close() error {
+	return s.db.Close()
+}
+
+func indexKey(chain, pulse int) []byte {
+	k := make([]byte, 16)
+	binary.BigEndian.PutUint64(k, uint64(chain))
+	binary.BigEndian.PutUint64(k[8:], uint64(pulse))
+	return k
+}
+
+func timeKey(t time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixMilli()))
+	return k
+}
+
+// get returns the value of key in the named bucket, nil if either is missing
+func get(tx *bolt.Tx, bucket, key []byte) []byte {
+	if b := tx.Bucket(bucket); b != nil {
+		return b.Get(key)
+	}
+	return nil
+}
+
+func getRecord(b *bolt.Bucket, key []byte) (beacon.Record, bool, error) {
+	if raw := get(b.Tx(), rawBucket, key); raw != nil {
+		rec, err := beacon.ParseRecordLenient(raw)
+		if err != nil {
+			return rec, false, fmt.Errorf("Couldn't decode the archived record: %w", err)
+		}
+		return rec, true, nil
+	}
+
+	buf := b.Get(key)
+	if buf == nil {
+		return beacon.Record{}, false, nil
+	}
+	var rec beacon.Record
+	if err := json.Unmarshal(buf, &rec); err != nil {
+		return rec, false, fmt.Errorf("Couldn't decode the archived record: %w", err)
+	}
+	return rec, true, nil
+}
+
+// view returns the record key finds in a read-only transaction, or ErrNotFound when it returns a nil key
+func (s *store) view(key func(tx *bolt.Tx) []byte) (beacon.Record, error) {
+	var rec beacon.Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		k := key(tx)
+		if k == nil {
+			return archive.ErrNotFound
+		}
+		r, ok, err := getRecord(tx.Bucket(recordsBucket), k)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return archive.ErrNotFound
+		}
+		rec = r
+		return nil
+	})
+	return rec, err
+}
+
+func (s *store) Put(rec beacon.Record, verified bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+		key := indexKey(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex)
+		if records.Get(key) == nil {
+			buf, err := json.Marshal(rec)
+			if err != nil {
+				return fmt.Errorf("Couldn't encode the record: %w", err)
+			}
+			if err := records.Put(key, buf); err != nil {
+				return err
+			}
+			if raw := rec.Raw(); raw != nil {
+				if err := tx.Bucket(rawBucket).Put(key, raw); err != nil {
+					return err
+				}
+			}
+			if err := tx.Bucket(timeBucket).Put(timeKey(rec.Pulse.TimeStamp), key); err != nil {
+				return err
+			}
+		}
+		if verified {
+			return tx.Bucket(verifiedBucket).Put(timeKey(rec.Pulse.TimeStamp), key)
+		}
+		return nil
+	})
+}
+
+func (s *store) Get(t time.Time) (beacon.Record, error) {
+	return s.view(func(tx *bolt.Tx) []byte { return tx.Bucket(timeBucket).Get(timeKey(t)) })
+}
+
+func (s *store) GetByIndex(chain, pulse int) (beacon.Record, error) {
+	return s.view(func(*bolt.Tx) []byte { return indexKey(chain, pulse) })
+}
+
+func (s *store) Range(from, to time.Time) ([]beacon.Record, error) {
+	var recs []beacon.Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+		c := tx.Bucket(timeBucket).Cursor()
+		end := timeKey(to)
+		for k, v := c.Seek(timeKey(from)); k != nil && string(k) <= string(end); k, v = c.Next() {
+			rec, ok, err := getRecord(records, v)
+			if err != nil {
+				return err
+			}
+			if ok {
+				recs = append(recs, rec)
+			}
+		}
+		return nil
+	})
+	return recs, err
+}
+
+func (s *store) Latest() (beacon.Record, error) {
+	return s.view(func(tx *bolt.Tx) []byte {
+		_, key := tx.Bucket(timeBucket).Cursor().Last()
+		return key
+	})
+}
+
+func (s *store) LatestVerified() (beacon.Record, error) {
+	return s.view(func(tx *bolt.Tx) []byte {
+		b := tx.Bucket(verifiedBucket)
+		if b == nil {
+			return nil
+		}
+		_, key := b.Cursor().Last()
+		return key
+	})
+}
+
+// Seek implements archive.Seeker
+func (s *store) Seek(t time.Time) (beacon.Record, error) {
+	return s.view(func(tx *bolt.Tx) []byte {
+		_, key := tx.Bucket(timeBucket).Cursor().Seek(timeKey(t))
+		return key
+	})
+}
@@ -0,0 +1,102 @@
+package boltstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestStore(t *testing.T) {
+	srv := beacontest.NewServer()
+	srv.Close()
+	recs := srv.Records()
+	path := filepath.Join(t.TempDir(), "archive.db")
+
+	a, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 2, 1, 4, 3} {
+		if err := a.Put(recs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.PutVerified(recs[3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the records outlive the database being closed
+	a, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	got, err := a.Range(recs[1].Pulse.TimeStamp, recs[3].Pulse.TimeStamp)
+	if err != nil || len(got) != 3 || !got[0].Equal(recs[1]) || !got[2].Equal(recs[3]) {
+		t.Errorf("unexpected range of %d records: %v", len(got), err)
+	}
+	if rec, err := a.Get(recs[2].Pulse.TimeStamp); err != nil || !rec.Equal(recs[2]) {
+		t.Errorf("couldn't get the record: %v", err)
+	}
+	if rec, err := a.Latest(); err != nil || !rec.Equal(recs[4]) {
+		t.Errorf("unexpected latest record: %v", err)
+	}
+	if rec, err := a.LatestVerified(); err != nil || !rec.Equal(recs[3]) {
+		t.Errorf("unexpected latest verified record: %v", err)
+	}
+	if _, err := a.GetByIndex(0, 0); !errors.Is(err, archive.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	s, err := openStore(filepath.Join(t.TempDir(), "seek.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for _, i := range []int{1, 3} {
+		if err := s.Put(recs[i], false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rec, err := s.Seek(recs[2].Pulse.TimeStamp); err != nil || !rec.Equal(recs[3]) {
+		t.Errorf("unexpected record sought: %v", err)
+	}
+	if _, err := s.Seek(recs[4].Pulse.TimeStamp); !errors.Is(err, archive.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRawBytes(t *testing.T) {
+	a, err := Open(filepath.Join(t.TempDir(), "beacon.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	buf, err := os.ReadFile("../../testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := beacon.ParseRecord(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Put(rec); err != nil {
+		t.Fatal(err)
+	}
+	got, err := a.GetByIndex(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Raw()) != string(buf) || !got.Equal(rec) {
+		t.Error("expected the record to be archived verbatim")
+	}
+}
@@ -0,0 +1,236 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// listTypes are the list values exported as CSV columns, other types are dropped
+var listTypes = []string{"previous", "hour", "day", "month", "year"}
+
+// CSVHeader is the header row of the CSV exports. Hex values are kept as served, timestamps use beacon.TimeStampFormat, and each
+// list value takes a uri and a value column.
+var CSVHeader = []string{
+	"uri", "version", "cipherSuite", "period", "certificateId", "chainIndex", "pulseIndex", "timeStamp", "localRandomValue",
+	"externalSourceId", "externalStatusCode", "externalValue",
+	"previousUri", "previousValue", "hourUri", "hourValue", "dayUri", "dayValue", "monthUri", "monthValue", "yearUri", "yearValue",
+	"precommitmentValue", "statusCode", "signatureValue", "outputValue",
+}
+
+func csvRow(rec beacon.Record) []string {
+	p := &rec.Pulse
+	row := []string{
+		p.URI, p.Version, strconv.Itoa(p.CipherSuite), strconv.Itoa(p.Period), p.CertificateID,
+		strconv.Itoa(p.ChainIndex), strconv.Itoa(p.PulseIndex), p.TimeStamp.UTC().Format(beacon.TimeStampFormat), p.LocalRandomValue,
+		p.External.SourceID, strconv.Itoa(p.External.StatusCode), p.External.Value,
+	}
+	for _, typ := range listTypes {
+		v, _ := rec.ListValue(typ)
+		row = append(row, v.URI, v.Value)
+	}
+	return append(row, p.PrecommitmentValue, strconv.Itoa(p.StatusCode), p.SignatureValue, p.OutputValue)
+}
+
+func parseCSVRow(row []string) (beacon.Record, error) {
+	var rec beacon.Record
+	if len(row) != len(CSVHeader) {
+		return rec, errors.New(fmt.Sprintf("Expected %d columns, got %d", len(CSVHeader), len(row)))
+	}
+	ints := make(map[int]int)
+	for _, i := range []int{2, 3, 5, 6, 10, 23} {
+		n, err := strconv.Atoi(row[i])
+		if err != nil {
+			return rec, fmt.Errorf("Couldn't parse %s: %w", CSVHeader[i], err)
+		}
+		ints[i] = n
+	}
+	ts, err := time.Parse(beacon.TimeStampFormat, row[7])
+	if err != nil {
+		return rec, fmt.Errorf("Couldn't parse timeStamp: %w", err)
+	}
+
+	p := &rec.Pulse
+	p.URI, p.Version, p.CipherSuite, p.Period, p.CertificateID = row[0], row[1], ints[2], ints[3], row[4]
+	p.ChainIndex, p.PulseIndex, p.TimeStamp, p.LocalRandomValue = ints[5], ints[6], ts, row[8]
+	p.External.SourceID, p.External.StatusCode, p.External.Value = row[9], ints[10], row[11]
+	for i, typ := range listTypes {
+		uri, value := row[12+2*i], row[13+2*i]
+		if uri != "" || value != "" {
+			p.ListValues = append(p.ListValues, beacon.ListValue{URI: uri, Type: typ, Value: value})
+		}
+	}
+	p.PrecommitmentValue, p.StatusCode, p.SignatureValue, p.OutputValue = row[22], ints[23], row[24], row[25]
+	return rec, nil
+}
+
+// WriteCSV writes recs to w as CSV, preceded by CSVHeader
+func WriteCSV(w io.Writer, recs iter.Seq[beacon.Record]) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHeader); err != nil {
+		return 0, err
+	}
+	n := 0
+	for rec := range recs {
+		if err := cw.Write(csvRow(rec)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	cw.Flush()
+	return n, cw.Error()
+}
+
+// ReadCSV reads the records of a CSV export. The iteration stops after yielding an error, identifying the faulty line.
+func ReadCSV(r io.Reader) iter.Seq2[beacon.Record, error] {
+	return func(yield func(beacon.Record, error) bool) {
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		header, err := cr.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(beacon.Record{}, fmt.Errorf("Couldn't read the CSV header: %w", err))
+			return
+		}
+		if len(header) != len(CSVHeader) || header[0] != CSVHeader[0] {
+			yield(beacon.Record{}, errors.New("Unexpected CSV header"))
+			return
+		}
+		for line := 2; ; line++ {
+			row, err := cr.Read()
+			if err == io.EOF {
+				return
+			}
+			var rec beacon.Record
+			if err == nil {
+				rec, err = parseCSVRow(row)
+			}
+			if err != nil {
+				yield(beacon.Record{}, fmt.Errorf("Line %d: %w", line, err))
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+	}
+}
+
+// WriteJSONL writes recs to w as JSON Lines, one record per line in the beacon's JSON format
+func WriteJSONL(w io.Writer, recs iter.Seq[beacon.Record]) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	n := 0
+	for rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, bw.Flush()
+}
+
+// ReadJSONL reads the records of a JSON Lines export. The iteration stops after yielding an error, identifying the faulty line.
+func ReadJSONL(r io.Reader) iter.Seq2[beacon.Record, error] {
+	return func(yield func(beacon.Record, error) bool) {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(nil, beacon.DefaultMaxResponseSize)
+		for line := 1; sc.Scan(); line++ {
+			if len(sc.Bytes()) == 0 {
+				continue
+			}
+			var rec beacon.Record
+			if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+				yield(beacon.Record{}, fmt.Errorf("Line %d: %w", line, err))
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil {
+			yield(beacon.Record{}, err)
+		}
+	}
+}
+
+// each iterates over the archived records with a pulse timestamp in [from, to]
+func (a *Archive) each(from, to time.Time) (iter.Seq[beacon.Record], error) {
+	recs, err := a.Range(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(beacon.Record) bool) {
+		for _, rec := range recs {
+			if !yield(rec) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ExportCSV writes the archived records with a pulse timestamp in [from, to] to w as CSV, returning how many were written
+func (a *Archive) ExportCSV(w io.Writer, from, to time.Time) (int, error) {
+	recs, err := a.each(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return WriteCSV(w, recs)
+}
+
+// ExportJSONL writes the archived records with a pulse timestamp in [from, to] to w as JSON Lines, returning how many were written
+func (a *Archive) ExportJSONL(w io.Writer, from, to time.Time) (int, error) {
+	recs, err := a.each(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return WriteJSONL(w, recs)
+}
+
+// importRecords archives recs, checking each one with verify if it isn't nil and marking it verified then, and stops at the first failure
+func (a *Archive) importRecords(recs iter.Seq2[beacon.Record, error], verify func(beacon.Record) error) (int, error) {
+	n := 0
+	for rec, err := range recs {
+		if err != nil {
+			return n, err
+		}
+		if verify != nil {
+			if err := verify(rec); err != nil {
+				return n, fmt.Errorf("Pulse %d of chain %d failed verification: %w", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex, err)
+			}
+		}
+		if err := a.put(rec, verify != nil); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// ImportCSV archives the records of a CSV export, returning how many were read. Each record is checked with verify, typically
+// func(rec beacon.Record) error { return c.Verify(ctx, rec) } for a beacon.Client c, and must link to the archived records around it.
+// A nil verify archives the records unverified. The import stops at the first failure.
+func (a *Archive) ImportCSV(r io.Reader, verify func(beacon.Record) error) (int, error) {
+	return a.importRecords(ReadCSV(r), verify)
+}
+
+// ImportJSONL archives the records of a JSON Lines export, as ImportCSV does
+func (a *Archive) ImportJSONL(r io.Reader, verify func(beacon.Record) error) (int, error) {
+	return a.importRecords(ReadJSONL(r), verify)
+}
+
+// ImportDump archives the records of a bulk dump file, in any of the layouts read by beacon.DecodeRecords, as ImportCSV does.
+// The dump is streamed, so it can be much larger than the memory.
+func (a *Archive) ImportDump(r io.Reader, verify func(beacon.Record) error) (int, error) {
+	return a.importRecords(beacon.DecodeRecords(r), verify)
+}
@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestExportImport(t *testing.T) {
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-time.Hour)))
+	defer srv.Close()
+	c := srv.Client()
+	verify := func(rec beacon.Record) error { return c.Verify(context.Background(), rec) }
+
+	a := openTest(t)
+	recs := srv.Records()[:20]
+	for _, rec := range recs {
+		if err := a.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from, to := recs[0].Pulse.TimeStamp, recs[len(recs)-1].Pulse.TimeStamp
+
+	formats := []struct {
+		name   string
+		export func(*Archive, *bytes.Buffer) (int, error)
+		imp    func(*Archive, *bytes.Buffer) (int, error)
+	}{
+		{"csv", func(a *Archive, b *bytes.Buffer) (int, error) { return a.ExportCSV(b, from, to) }, func(a *Archive, b *bytes.Buffer) (int, error) { return a.ImportCSV(b, verify) }},
+		{"jsonl", func(a *Archive, b *bytes.Buffer) (int, error) { return a.ExportJSONL(b, from, to) }, func(a *Archive, b *bytes.Buffer) (int, error) { return a.ImportJSONL(b, verify) }},
+		{"dump", func(a *Archive, b *bytes.Buffer) (int, error) { return a.ExportJSONL(b, from, to) }, func(a *Archive, b *bytes.Buffer) (int, error) { return a.ImportDump(b, verify) }},
+	}
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if n, err := f.export(a, &buf); err != nil || n != len(recs) {
+				t.Fatalf("exported %d records: %v", n, err)
+			}
+			exported := buf.String()
+
+			b := openTest(t)
+			if n, err := f.imp(b, &buf); err != nil || n != len(recs) {
+				t.Fatalf("imported %d records: %v", n, err)
+			}
+			got, err := b.Range(from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range recs {
+				if !got[i].Equal(recs[i]) {
+					t.Fatalf("record %d changed through the export", i)
+				}
+			}
+
+			// tampering with a record fails its verification
+			out := recs[5].Pulse.OutputValue
+			tampered := strings.Replace(exported, out, strings.ToUpper(out[1:]+out[:1]), 1)
+			if _, err := f.imp(openTest(t), bytes.NewBufferString(tampered)); err == nil || !strings.Contains(err.Error(), "verification") {
+				t.Errorf("expected the tampered record to fail verification, got %v", err)
+			}
+		})
+	}
+}
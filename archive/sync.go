@@ -0,0 +1,338 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// DefaultReverifyInterval is how often a Syncer re-verifies the whole archive by default
+const DefaultReverifyInterval = 24 * time.Hour
+
+// reverifyWindow bounds the records a re-verification pass holds in memory at once
+const reverifyWindow = 24 * time.Hour
+
+// SyncMetrics receives the progress of a Syncer. Implementations must be safe for concurrent use, metrics.Collector is one.
+type SyncMetrics interface {
+	// ObserveArchived is called with every record the syncer archives, backfilled telling whether it filled a gap
+	ObserveArchived(rec beacon.Record, backfilled bool)
+	// ObserveSyncLag is called whenever the syncer archives a record with how far the archive is behind
+	ObserveSyncLag(lag time.Duration)
+	// ObserveMissing is called after every backfill pass with how many pulses the archive still lacks
+	ObserveMissing(n int)
+	// ObserveReverification is called after every re-verification pass with how many records were checked and how many failed
+	ObserveReverification(checked, invalid int)
+}
+
+// SyncStatus is the progress of a Syncer
+type SyncStatus struct {
+	// Latest is the timestamp of the latest archived pulse, Lag how long before the status was taken it was emitted
+	Latest time.Time
+	Lag    time.Duration
+	// Tailed and Backfilled count the records archived as they were published and to fill gaps
+	Tailed, Backfilled int
+	// Missing is how many pulses the archive lacked after the last backfill pass. Pulses the beacon never emitted, during its
+	// outages, remain missing.
+	Missing int
+	// Reverified is when the last re-verification pass ended, Invalid how many archived records it found failing verification
+	Reverified time.Time
+	Invalid    int
+	// Err is the last error the syncer ran into
+	Err error
+}
+
+// Syncer keeps an archive up to date with a beacon: it archives pulses as they are published, backfills the gaps left by outages of
+// the beacon or of the syncer itself, and re-verifies the archived records periodically. Only verified records are archived.
+type Syncer struct {
+	archive  *Archive
+	client   *beacon.Client
+	from     time.Time
+	workers  int
+	reverify time.Duration
+	metrics  SyncMetrics
+	watch    []beacon.WatcherOption
+
+	// backfill is signalled when the archive may have a gap
+	backfill chan struct{}
+	// tried holds the start of the gaps already backfilled, which the beacon couldn't fill
+	tried map[time.Time]bool
+
+	mu     sync.Mutex
+	status SyncStatus
+}
+
+// SyncOption configures a Syncer
+type SyncOption func(*Syncer)
+
+// WithBackfillFrom makes the syncer backfill every pulse since t, rather than only the gaps after the first archived one
+func WithBackfillFrom(t time.Time) SyncOption {
+	return func(s *Syncer) {
+		s.from = t
+	}
+}
+
+// WithBackfillWorkers sets how many concurrent requests backfills make, 4 by default
+func WithBackfillWorkers(n int) SyncOption {
+	return func(s *Syncer) {
+		s.workers = n
+	}
+}
+
+// WithReverifyInterval sets how often the archive is re-verified, DefaultReverifyInterval by default. 0 disables re-verification.
+func WithReverifyInterval(d time.Duration) SyncOption {
+	return func(s *Syncer) {
+		s.reverify = d
+	}
+}
+
+// WithSyncMetrics makes the syncer report its progress to m
+func WithSyncMetrics(m SyncMetrics) SyncOption {
+	return func(s *Syncer) {
+		s.metrics = m
+	}
+}
+
+// WithWatcherOptions configures the watcher tailing the beacon
+func WithWatcherOptions(opts ...beacon.WatcherOption) SyncOption {
+	return func(s *Syncer) {
+		s.watch = opts
+	}
+}
+
+// NewSyncer returns a syncer archiving the pulses c fetches into a
+func NewSyncer(a *Archive, c *beacon.Client, opts ...SyncOption) *Syncer {
+	s := &Syncer{
+		archive:  a,
+		client:   c,
+		workers:  4,
+		reverify: DefaultReverifyInterval,
+		backfill: make(chan struct{}, 1),
+		tried:    make(map[time.Time]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Status returns the progress of the syncer
+func (s *Syncer) Status() SyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.status
+	if !st.Latest.IsZero() {
+		st.Lag = time.Since(st.Latest)
+	}
+	return st
+}
+
+// fail records err as the last error of the syncer
+func (s *Syncer) fail(err error) {
+	s.mu.Lock()
+	s.status.Err = err
+	s.mu.Unlock()
+}
+
+// kick schedules a backfill pass
+func (s *Syncer) kick() {
+	select {
+	case s.backfill <- struct{}{}:
+	default:
+	}
+}
+
+// Run syncs the archive until ctx is done. The first pulse tailed starts a backfill pass, as does every gap the tail runs into.
+func (s *Syncer) Run(ctx context.Context) {
+	if latest, err := s.archive.Latest(); err == nil {
+		s.mu.Lock()
+		s.status.Latest = latest.Pulse.TimeStamp
+		s.mu.Unlock()
+	}
+
+	first := true
+	tail := func(rec beacon.Record) {
+		if _, err := s.put(ctx, rec, false); err != nil {
+			s.fail(err)
+		}
+		if first {
+			first = false
+			s.kick()
+		}
+	}
+	opts := append([]beacon.WatcherOption{beacon.WithCallback(tail), beacon.WithGapCallback(func(beacon.Gap) { s.kick() })}, s.watch...)
+	w := s.client.Watch(ctx, opts...)
+	defer w.Stop()
+
+	var reverify <-chan time.Time
+	if s.reverify > 0 {
+		t := time.NewTicker(s.reverify)
+		defer t.Stop()
+		reverify = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.backfill:
+			if err := s.backfillPass(ctx); err != nil && ctx.Err() == nil {
+				s.fail(err)
+			}
+		case <-reverify:
+			if err := s.reverifyPass(ctx); err != nil && ctx.Err() == nil {
+				s.fail(err)
+			}
+		}
+	}
+}
+
+// put verifies and archives rec, reporting whether it wasn't archived already. Records archived unverified, by a client caching in
+// the archive, are marked verified.
+func (s *Syncer) put(ctx context.Context, rec beacon.Record, backfilled bool) (bool, error) {
+	_, err := s.archive.GetByIndex(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex)
+	archived := err == nil
+	if err := s.client.Verify(ctx, rec); err != nil {
+		return false, fmt.Errorf("Couldn't verify pulse %d of chain %d: %w", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex, err)
+	}
+	if err := s.archive.PutVerified(rec); err != nil {
+		return false, fmt.Errorf("Couldn't archive pulse %d of chain %d: %w", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex, err)
+	}
+	if archived {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	if backfilled {
+		s.status.Backfilled++
+	} else {
+		s.status.Tailed++
+	}
+	if rec.Pulse.TimeStamp.After(s.status.Latest) {
+		s.status.Latest = rec.Pulse.TimeStamp
+	}
+	lag := time.Since(s.status.Latest)
+	s.mu.Unlock()
+	if s.metrics != nil {
+		s.metrics.ObserveArchived(rec, backfilled)
+		s.metrics.ObserveSyncLag(lag)
+	}
+	return true, nil
+}
+
+// backfillPass fetches the pulses missing from the archive, from the first archived one or the backfill start to the latest one
+func (s *Syncer) backfillPass(ctx context.Context) error {
+	latest, err := s.archive.Latest()
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	from := s.from
+	if from.IsZero() {
+		first, err := s.archive.seek(time.Unix(0, 0))
+		if err != nil {
+			return err
+		}
+		from = first.Pulse.TimeStamp
+	}
+	report, err := s.archive.GapReport(from, latest.Pulse.TimeStamp)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	missing := 0
+	for _, g := range report.Gaps {
+		if s.tried[g.Start] {
+			missing += g.Missing
+			continue
+		}
+		filled := 0
+		for rec, err := range s.client.BatchRecords(ctx, g.Start, g.End, s.workers) {
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+			added, err := s.put(ctx, rec, true)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if added {
+				filled++
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if filled < g.Missing {
+			missing += g.Missing - filled
+			// the beacon has no more pulses for the gap, don't ask again on every pass
+			if len(errs) == 0 {
+				s.tried[g.Start] = true
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.status.Missing = missing
+	s.mu.Unlock()
+	if s.metrics != nil {
+		s.metrics.ObserveMissing(missing)
+	}
+	return errors.Join(errs...)
+}
+
+// reverifyPass verifies every archived record again, a day of records at a time
+func (s *Syncer) reverifyPass(ctx context.Context) error {
+	latest, err := s.archive.Latest()
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var first error
+	checked, invalid := 0, 0
+	for from := time.Unix(0, 0); !from.After(latest.Pulse.TimeStamp); from = from.Add(reverifyWindow) {
+		next, err := s.archive.seek(from)
+		if err != nil {
+			return err
+		}
+		// skip straight to the window of the next archived record
+		from = next.Pulse.TimeStamp
+		recs, err := s.archive.Range(from, from.Add(reverifyWindow-time.Millisecond))
+		if err != nil {
+			return err
+		}
+		for _, rec := range recs {
+			checked++
+			if err := s.client.Verify(ctx, rec); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				invalid++
+				if first == nil {
+					first = fmt.Errorf("Archived pulse %d of chain %d failed verification: %w", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex, err)
+				}
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.status.Reverified = time.Now()
+	s.status.Invalid = invalid
+	s.mu.Unlock()
+	if s.metrics != nil {
+		s.metrics.ObserveReverification(checked, invalid)
+	}
+	if invalid > 1 {
+		return fmt.Errorf("%d archived records failed verification, the first: %w", invalid, first)
+	}
+	return first
+}
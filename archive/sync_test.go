@@ -0,0 +1,128 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+// syncMetrics records the observations of a syncer
+type syncMetrics struct {
+	mu                   sync.Mutex
+	tailed, backfilled   int
+	checked, invalid     int
+	reverifications, lag int
+}
+
+func (m *syncMetrics) ObserveArchived(rec beacon.Record, backfilled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if backfilled {
+		m.backfilled++
+	} else {
+		m.tailed++
+	}
+}
+
+func (m *syncMetrics) ObserveSyncLag(time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lag++
+}
+
+func (m *syncMetrics) ObserveMissing(int) {}
+
+func (m *syncMetrics) ObserveReverification(checked, invalid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reverifications++
+	m.checked, m.invalid = checked, invalid
+}
+
+func TestSyncer(t *testing.T) {
+	// three days of hourly pulses, the beacon down for one of them
+	origin := time.Now().Add(-72 * time.Hour).Truncate(time.Hour)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithPeriod(time.Hour), beacontest.WithGap(origin.Add(30*time.Hour)))
+	defer srv.Close()
+	c := srv.Client()
+	recs := srv.Records()
+
+	// the archive was synced for a while, then the syncer stopped; one of its records was tampered with since
+	a := openTest(t)
+	for _, rec := range recs[:10] {
+		if rec.Pulse.PulseIndex == 3 {
+			rec.Pulse.SignatureValue = recs[3].Pulse.SignatureValue
+		}
+		if err := a.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := &syncMetrics{}
+	s := NewSyncer(a, c, WithBackfillFrom(origin), WithReverifyInterval(50*time.Millisecond), WithSyncMetrics(m))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st := s.Status()
+		if st.Missing == 1 && !st.Reverified.IsZero() && st.Invalid == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out syncing, status %+v", st)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	st := s.Status()
+	// a pulse may have been published meanwhile
+	recs = srv.Records()
+	latest := recs[len(recs)-1]
+	if !st.Latest.Equal(latest.Pulse.TimeStamp) || st.Lag <= 0 || st.Lag > time.Hour+time.Second {
+		t.Errorf("expected the archive to be up to date, status %+v", st)
+	}
+	if st.Tailed < 1 || st.Tailed+st.Backfilled != len(recs)-10 {
+		t.Errorf("expected %d records tailed or backfilled, status %+v", len(recs)-10, st)
+	}
+	if !errors.Is(st.Err, beacon.ErrSignatureInvalid) {
+		t.Errorf("expected the tampered record to be reported, got %v", st.Err)
+	}
+
+	archived, err := a.Range(origin, latest.Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != len(recs) {
+		t.Errorf("archived %d records, the beacon published %d", len(archived), len(recs))
+	}
+	report, err := a.GapReport(origin, latest.Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Gaps) != 1 || !report.Gaps[0].Start.Equal(origin.Add(29*time.Hour)) {
+		t.Errorf("expected only the beacon's outage to be left, got %+v", report.Gaps)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tailed != st.Tailed || m.backfilled != st.Backfilled || m.lag != m.tailed+m.backfilled {
+		t.Errorf("unexpected archive metrics %+v", m)
+	}
+	if m.reverifications == 0 || m.invalid != 1 || m.checked == 0 {
+		t.Errorf("unexpected re-verification metrics %+v", m)
+	}
+}
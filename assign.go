@@ -0,0 +1,34 @@
+package beacon
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+)
+
+// Assign maps each identifier to one of groups groups, numbered from 0, with the record's output value as the salt: the group of an
+// identifier is HMAC-SHA512 of the identifier keyed with the output value, modulo groups. Anyone holding the pulse can recompute an
+// assignment, which is independent of the other identifiers and of their order, making it suitable for audit-reproducible cohorts
+// and A/B experiments. Groups are not balanced, each identifier lands in any group with the same probability.
+func Assign(rec Record, ids []string, groups int) (map[string]int, error) {
+	if groups < 1 {
+		return nil, errors.New("There must be at least one group")
+	}
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// the bias of reducing a 512 bit value is negligible for any number of groups
+	n := big.NewInt(int64(groups))
+	assignment := make(map[string]int, len(ids))
+	mac := hmac.New(sha512.New, out)
+	for _, id := range ids {
+		mac.Reset()
+		mac.Write([]byte(id))
+		v := new(big.Int).SetBytes(mac.Sum(nil))
+		assignment[id] = int(v.Mod(v, n).Int64())
+	}
+	return assignment, nil
+}
@@ -0,0 +1,55 @@
+package beacon
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestAssign(t *testing.T) {
+	rec := fixtureRecord(t)
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = "user-" + strconv.Itoa(i)
+	}
+
+	a, err := Assign(rec, ids, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int, 3)
+	for _, id := range ids {
+		g, ok := a[id]
+		if !ok || g < 0 || g >= 3 {
+			t.Fatalf("%s was assigned to group %d", id, g)
+		}
+		counts[g]++
+	}
+	for g, n := range counts {
+		if n < 250 || n > 420 {
+			t.Errorf("group %d got %d of 1000 identifiers", g, n)
+		}
+	}
+
+	// an assignment doesn't depend on the other identifiers
+	b, _ := Assign(rec, ids[500:501], 3)
+	if b[ids[500]] != a[ids[500]] {
+		t.Error("the assignment changed with the set of identifiers")
+	}
+
+	other := rec
+	other.Pulse.OutputValue = rec.Pulse.OutputValue[1:] + rec.Pulse.OutputValue[:1]
+	c, _ := Assign(other, ids, 3)
+	moved := 0
+	for _, id := range ids {
+		if c[id] != a[id] {
+			moved++
+		}
+	}
+	if moved < 500 {
+		t.Errorf("only %d identifiers moved with another pulse", moved)
+	}
+
+	if _, err := Assign(rec, ids, 0); err == nil {
+		t.Error("expected assigning to no group to fail")
+	}
+}
@@ -0,0 +1,152 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// batchResult is the outcome of fetching one pulse of a batch
+type batchResult struct {
+	rec Record
+	err error
+}
+
+// backoff calls fetch until it succeeds or fails with anything else than the beacon rate limiting the client, at most maxRateLimitRetries times.
+// It waits at least as long as the Retry-After header of the rejected response asks.
+func backoff(ctx context.Context, fetch func() (Record, error)) (Record, error) {
+	d := time.Second
+	for i := 0; ; i++ {
+		rec, err := fetch()
+		code := statusCode(err)
+		if i == maxRateLimitRetries || (code != http.StatusTooManyRequests && code != http.StatusServiceUnavailable) {
+			return rec, err
+		}
+		if err := Sleep(ctx, max(d, retryAfter(err))); err != nil {
+			return Record{}, err
+		}
+		d *= 2
+	}
+}
+
+// BatchRecords fetches the records from the first at or after from up to the last at or before to with workers concurrent requests,
+// fetching them by pulse index instead of following next links one at a time. Records are yielded in chain order, each one checked
+// to reference the output value of the record yielded before it unless it starts a new chain, a broken link being yielded as a *LinkError.
+// Requests count against the client's rate limit, and rate limited requests are retried after a backoff. As with Records, the iteration
+// stops after yielding an error.
+func (c *Client) BatchRecords(ctx context.Context, from, to time.Time, workers int) iter.Seq2[Record, error] {
+	if workers < 1 {
+		workers = 1
+	}
+	return func(yield func(Record, error) bool) {
+		first, err := backoff(ctx, func() (Record, error) { return c.NextRecord(ctx, from.Add(-time.Second)) })
+		if errors.Is(err, ErrNotFound) {
+			return
+		}
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		if first.Pulse.TimeStamp.After(to) {
+			return
+		}
+		last, err := backoff(ctx, func() (Record, error) { return c.PreviousRecord(ctx, to.Add(time.Second)) })
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+
+		var prev *Record
+		n := 0
+		emit := func(rec Record, err error) bool {
+			if err == nil && prev != nil && !rec.IsNewChainStart() && !strings.EqualFold(rec.PreviousOutputValue(), prev.Pulse.OutputValue) {
+				err = &LinkError{Index: n, Err: errors.New("Previous output value doesn't match the previous record's output value")}
+			}
+			if err != nil {
+				yield(Record{}, err)
+				return false
+			}
+			prev = &rec
+			n++
+			return yield(rec, nil)
+		}
+
+		for chain := first.Pulse.ChainIndex; chain <= last.Pulse.ChainIndex; chain++ {
+			start, end := 1, -1
+			if chain == first.Pulse.ChainIndex {
+				start = first.Pulse.PulseIndex
+			}
+			if chain == last.Pulse.ChainIndex {
+				end = last.Pulse.PulseIndex
+			}
+			if !c.batchChain(ctx, chain, start, end, workers, emit) {
+				return
+			}
+		}
+	}
+}
+
+// batchChain fetches the pulses of chain from index start up to end, or up to the end of the chain if end is negative, passing them to emit
+// in order. It returns false if emit did.
+func (c *Client) batchChain(ctx context.Context, chain, start, end, workers int, emit func(Record, error) bool) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type job struct {
+		index  int
+		result chan batchResult
+	}
+	jobs := make(chan job)
+	// pending holds the results in index order, bounding how far ahead of the consumer the workers go
+	pending := make(chan chan batchResult, 2*workers)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for i := start; end < 0 || i <= end; i++ {
+			j := job{i, make(chan batchResult, 1)}
+			select {
+			case pending <- j.result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range workers {
+		go func() {
+			for j := range jobs {
+				rec, err := backoff(ctx, func() (Record, error) { return c.PulseByIndex(ctx, uint64(chain), uint64(j.index)) })
+				j.result <- batchResult{rec, err}
+			}
+		}()
+	}
+
+	for result := range pending {
+		var r batchResult
+		select {
+		case r = <-result:
+		case <-ctx.Done():
+			return emit(Record{}, ctx.Err())
+		}
+		if end < 0 && errors.Is(r.err, ErrNotFound) {
+			// past the end of the chain
+			return true
+		}
+		if !emit(r.rec, r.err) {
+			return false
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		emit(Record{}, err)
+		return false
+	}
+	return true
+}
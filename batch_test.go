@@ -0,0 +1,58 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestBatchRecords(t *testing.T) {
+	origin := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	gap := origin.Add(30 * time.Minute)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithGap(gap))
+	defer srv.Close()
+	c := srv.Client(beacon.WithRateLimit(1000, 8))
+	ctx := context.Background()
+
+	from, to := origin.Add(10*time.Minute), origin.Add(70*time.Minute)
+	var want []beacon.Record
+	for rec, err := range c.Records(ctx, from, to) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, rec)
+	}
+
+	var got []beacon.Record
+	for rec, err := range c.BatchRecords(ctx, from, to, 8) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rec)
+	}
+	if len(got) != len(want) || len(got) != 60 {
+		t.Fatalf("got %d records, expected %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Pulse.OutputValue != want[i].Pulse.OutputValue {
+			t.Fatalf("record %d differs", i)
+		}
+	}
+
+	n := 0
+	for _, err := range c.BatchRecords(ctx, from, to, 4) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 5 {
+			break
+		}
+	}
+
+	for range c.BatchRecords(ctx, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour), 4) {
+		t.Error("expected no records after the last pulse")
+	}
+}
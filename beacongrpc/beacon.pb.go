@@ -0,0 +1,534 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: beacon.proto
+
+package beacongrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ListValue references the output value of an earlier pulse
+type ListValue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uri           string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListValue) Reset() {
+	*x = ListValue{}
+	mi := &file_beacon_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListValue) ProtoMessage() {}
+
+func (x *ListValue) ProtoReflect() protoreflect.Message {
+	mi := &file_beacon_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListValue.ProtoReflect.Descriptor instead.
+func (*ListValue) Descriptor() ([]byte, []int) {
+	return file_beacon_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListValue) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *ListValue) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListValue) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// External is the value of an external source of randomness
+type External struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SourceId      string                 `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	StatusCode    int32                  `protobuf:"varint,2,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *External) Reset() {
+	*x = External{}
+	mi := &file_beacon_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *External) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*External) ProtoMessage() {}
+
+func (x *External) ProtoReflect() protoreflect.Message {
+	mi := &file_beacon_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use External.ProtoReflect.Descriptor instead.
+func (*External) Descriptor() ([]byte, []int) {
+	return file_beacon_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *External) GetSourceId() string {
+	if x != nil {
+		return x.SourceId
+	}
+	return ""
+}
+
+func (x *External) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *External) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// Pulse mirrors a Beacon 2.0 pulse, hex values are kept as the beacon publishes them
+type Pulse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Uri         string                 `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	Version     string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	CipherSuite int32                  `protobuf:"varint,3,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	// period between pulses, in milliseconds
+	Period             int32                  `protobuf:"varint,4,opt,name=period,proto3" json:"period,omitempty"`
+	CertificateId      string                 `protobuf:"bytes,5,opt,name=certificate_id,json=certificateId,proto3" json:"certificate_id,omitempty"`
+	ChainIndex         int64                  `protobuf:"varint,6,opt,name=chain_index,json=chainIndex,proto3" json:"chain_index,omitempty"`
+	PulseIndex         int64                  `protobuf:"varint,7,opt,name=pulse_index,json=pulseIndex,proto3" json:"pulse_index,omitempty"`
+	TimeStamp          *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=time_stamp,json=timeStamp,proto3" json:"time_stamp,omitempty"`
+	LocalRandomValue   string                 `protobuf:"bytes,9,opt,name=local_random_value,json=localRandomValue,proto3" json:"local_random_value,omitempty"`
+	External           *External              `protobuf:"bytes,10,opt,name=external,proto3" json:"external,omitempty"`
+	ListValues         []*ListValue           `protobuf:"bytes,11,rep,name=list_values,json=listValues,proto3" json:"list_values,omitempty"`
+	PrecommitmentValue string                 `protobuf:"bytes,12,opt,name=precommitment_value,json=precommitmentValue,proto3" json:"precommitment_value,omitempty"`
+	StatusCode         int32                  `protobuf:"varint,13,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	SignatureValue     string                 `protobuf:"bytes,14,opt,name=signature_value,json=signatureValue,proto3" json:"signature_value,omitempty"`
+	OutputValue        string                 `protobuf:"bytes,15,opt,name=output_value,json=outputValue,proto3" json:"output_value,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Pulse) Reset() {
+	*x = Pulse{}
+	mi := &file_beacon_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pulse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pulse) ProtoMessage() {}
+
+func (x *Pulse) ProtoReflect() protoreflect.Message {
+	mi := &file_beacon_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pulse.ProtoReflect.Descriptor instead.
+func (*Pulse) Descriptor() ([]byte, []int) {
+	return file_beacon_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Pulse) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *Pulse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Pulse) GetCipherSuite() int32 {
+	if x != nil {
+		return x.CipherSuite
+	}
+	return 0
+}
+
+func (x *Pulse) GetPeriod() int32 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *Pulse) GetCertificateId() string {
+	if x != nil {
+		return x.CertificateId
+	}
+	return ""
+}
+
+func (x *Pulse) GetChainIndex() int64 {
+	if x != nil {
+		return x.ChainIndex
+	}
+	return 0
+}
+
+func (x *Pulse) GetPulseIndex() int64 {
+	if x != nil {
+		return x.PulseIndex
+	}
+	return 0
+}
+
+func (x *Pulse) GetTimeStamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TimeStamp
+	}
+	return nil
+}
+
+func (x *Pulse) GetLocalRandomValue() string {
+	if x != nil {
+		return x.LocalRandomValue
+	}
+	return ""
+}
+
+func (x *Pulse) GetExternal() *External {
+	if x != nil {
+		return x.External
+	}
+	return nil
+}
+
+func (x *Pulse) GetListValues() []*ListValue {
+	if x != nil {
+		return x.ListValues
+	}
+	return nil
+}
+
+func (x *Pulse) GetPrecommitmentValue() string {
+	if x != nil {
+		return x.PrecommitmentValue
+	}
+	return ""
+}
+
+func (x *Pulse) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *Pulse) GetSignatureValue() string {
+	if x != nil {
+		return x.SignatureValue
+	}
+	return ""
+}
+
+func (x *Pulse) GetOutputValue() string {
+	if x != nil {
+		return x.OutputValue
+	}
+	return ""
+}
+
+type LastRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LastRequest) Reset() {
+	*x = LastRequest{}
+	mi := &file_beacon_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LastRequest) ProtoMessage() {}
+
+func (x *LastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_beacon_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LastRequest.ProtoReflect.Descriptor instead.
+func (*LastRequest) Descriptor() ([]byte, []int) {
+	return file_beacon_proto_rawDescGZIP(), []int{3}
+}
+
+type TimeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimeRequest) Reset() {
+	*x = TimeRequest{}
+	mi := &file_beacon_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimeRequest) ProtoMessage() {}
+
+func (x *TimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_beacon_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimeRequest.ProtoReflect.Descriptor instead.
+func (*TimeRequest) Descriptor() ([]byte, []int) {
+	return file_beacon_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TimeRequest) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_beacon_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_beacon_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_beacon_proto_rawDescGZIP(), []int{5}
+}
+
+var File_beacon_proto protoreflect.FileDescriptor
+
+const file_beacon_proto_rawDesc = "" +
+	"\n" +
+	"\fbeacon.proto\x12\tbeacon.v2\x1a\x1fgoogle/protobuf/timestamp.proto\"G\n" +
+	"\tListValue\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"^\n" +
+	"\bExternal\x12\x1b\n" +
+	"\tsource_id\x18\x01 \x01(\tR\bsourceId\x12\x1f\n" +
+	"\vstatus_code\x18\x02 \x01(\x05R\n" +
+	"statusCode\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"\xc6\x04\n" +
+	"\x05Pulse\x12\x10\n" +
+	"\x03uri\x18\x01 \x01(\tR\x03uri\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12!\n" +
+	"\fcipher_suite\x18\x03 \x01(\x05R\vcipherSuite\x12\x16\n" +
+	"\x06period\x18\x04 \x01(\x05R\x06period\x12%\n" +
+	"\x0ecertificate_id\x18\x05 \x01(\tR\rcertificateId\x12\x1f\n" +
+	"\vchain_index\x18\x06 \x01(\x03R\n" +
+	"chainIndex\x12\x1f\n" +
+	"\vpulse_index\x18\a \x01(\x03R\n" +
+	"pulseIndex\x129\n" +
+	"\n" +
+	"time_stamp\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\ttimeStamp\x12,\n" +
+	"\x12local_random_value\x18\t \x01(\tR\x10localRandomValue\x12/\n" +
+	"\bexternal\x18\n" +
+	" \x01(\v2\x13.beacon.v2.ExternalR\bexternal\x125\n" +
+	"\vlist_values\x18\v \x03(\v2\x14.beacon.v2.ListValueR\n" +
+	"listValues\x12/\n" +
+	"\x13precommitment_value\x18\f \x01(\tR\x12precommitmentValue\x12\x1f\n" +
+	"\vstatus_code\x18\r \x01(\x05R\n" +
+	"statusCode\x12'\n" +
+	"\x0fsignature_value\x18\x0e \x01(\tR\x0esignatureValue\x12!\n" +
+	"\foutput_value\x18\x0f \x01(\tR\voutputValue\"\r\n" +
+	"\vLastRequest\"=\n" +
+	"\vTimeRequest\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\x0e\n" +
+	"\fWatchRequest2\x88\x02\n" +
+	"\x06Beacon\x120\n" +
+	"\x04Last\x12\x16.beacon.v2.LastRequest\x1a\x10.beacon.v2.Pulse\x12.\n" +
+	"\x02At\x12\x16.beacon.v2.TimeRequest\x1a\x10.beacon.v2.Pulse\x120\n" +
+	"\x04Next\x12\x16.beacon.v2.TimeRequest\x1a\x10.beacon.v2.Pulse\x124\n" +
+	"\bPrevious\x12\x16.beacon.v2.TimeRequest\x1a\x10.beacon.v2.Pulse\x124\n" +
+	"\x05Watch\x12\x17.beacon.v2.WatchRequest\x1a\x10.beacon.v2.Pulse0\x01B/Z-github.com/sherlach/go-nist-beacon/beacongrpcb\x06proto3"
+
+var (
+	file_beacon_proto_rawDescOnce sync.Once
+	file_beacon_proto_rawDescData []byte
+)
+
+func file_beacon_proto_rawDescGZIP() []byte {
+	file_beacon_proto_rawDescOnce.Do(func() {
+		file_beacon_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_beacon_proto_rawDesc), len(file_beacon_proto_rawDesc)))
+	})
+	return file_beacon_proto_rawDescData
+}
+
+var file_beacon_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_beacon_proto_goTypes = []any{
+	(*ListValue)(nil),             // 0: beacon.v2.ListValue
+	(*External)(nil),              // 1: beacon.v2.External
+	(*Pulse)(nil),                 // 2: beacon.v2.Pulse
+	(*LastRequest)(nil),           // 3: beacon.v2.LastRequest
+	(*TimeRequest)(nil),           // 4: beacon.v2.TimeRequest
+	(*WatchRequest)(nil),          // 5: beacon.v2.WatchRequest
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+}
+var file_beacon_proto_depIdxs = []int32{
+	6, // 0: beacon.v2.Pulse.time_stamp:type_name -> google.protobuf.Timestamp
+	1, // 1: beacon.v2.Pulse.external:type_name -> beacon.v2.External
+	0, // 2: beacon.v2.Pulse.list_values:type_name -> beacon.v2.ListValue
+	6, // 3: beacon.v2.TimeRequest.time:type_name -> google.protobuf.Timestamp
+	3, // 4: beacon.v2.Beacon.Last:input_type -> beacon.v2.LastRequest
+	4, // 5: beacon.v2.Beacon.At:input_type -> beacon.v2.TimeRequest
+	4, // 6: beacon.v2.Beacon.Next:input_type -> beacon.v2.TimeRequest
+	4, // 7: beacon.v2.Beacon.Previous:input_type -> beacon.v2.TimeRequest
+	5, // 8: beacon.v2.Beacon.Watch:input_type -> beacon.v2.WatchRequest
+	2, // 9: beacon.v2.Beacon.Last:output_type -> beacon.v2.Pulse
+	2, // 10: beacon.v2.Beacon.At:output_type -> beacon.v2.Pulse
+	2, // 11: beacon.v2.Beacon.Next:output_type -> beacon.v2.Pulse
+	2, // 12: beacon.v2.Beacon.Previous:output_type -> beacon.v2.Pulse
+	2, // 13: beacon.v2.Beacon.Watch:output_type -> beacon.v2.Pulse
+	9, // [9:14] is the sub-list for method output_type
+	4, // [4:9] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_beacon_proto_init() }
+func file_beacon_proto_init() {
+	if File_beacon_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_beacon_proto_rawDesc), len(file_beacon_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_beacon_proto_goTypes,
+		DependencyIndexes: file_beacon_proto_depIdxs,
+		MessageInfos:      file_beacon_proto_msgTypes,
+	}.Build()
+	File_beacon_proto = out.File
+	file_beacon_proto_goTypes = nil
+	file_beacon_proto_depIdxs = nil
+}
@@ -0,0 +1,291 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: beacon.proto
+
+package beacongrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Beacon_Last_FullMethodName     = "/beacon.v2.Beacon/Last"
+	Beacon_At_FullMethodName       = "/beacon.v2.Beacon/At"
+	Beacon_Next_FullMethodName     = "/beacon.v2.Beacon/Next"
+	Beacon_Previous_FullMethodName = "/beacon.v2.Beacon/Previous"
+	Beacon_Watch_FullMethodName    = "/beacon.v2.Beacon/Watch"
+)
+
+// BeaconClient is the client API for Beacon service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Beacon serves pulses that the server verified against the beacon's signing certificate
+type BeaconClient interface {
+	// Last returns the latest pulse
+	Last(ctx context.Context, in *LastRequest, opts ...grpc.CallOption) (*Pulse, error)
+	// At returns the pulse closest to the time
+	At(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*Pulse, error)
+	// Next returns the pulse following the time
+	Next(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*Pulse, error)
+	// Previous returns the pulse preceding the time
+	Previous(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*Pulse, error)
+	// Watch streams every new pulse
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Pulse], error)
+}
+
+type beaconClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBeaconClient(cc grpc.ClientConnInterface) BeaconClient {
+	return &beaconClient{cc}
+}
+
+func (c *beaconClient) Last(ctx context.Context, in *LastRequest, opts ...grpc.CallOption) (*Pulse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pulse)
+	err := c.cc.Invoke(ctx, Beacon_Last_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beaconClient) At(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*Pulse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pulse)
+	err := c.cc.Invoke(ctx, Beacon_At_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beaconClient) Next(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*Pulse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pulse)
+	err := c.cc.Invoke(ctx, Beacon_Next_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beaconClient) Previous(ctx context.Context, in *TimeRequest, opts ...grpc.CallOption) (*Pulse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pulse)
+	err := c.cc.Invoke(ctx, Beacon_Previous_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *beaconClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Pulse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Beacon_ServiceDesc.Streams[0], Beacon_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, Pulse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Beacon_WatchClient = grpc.ServerStreamingClient[Pulse]
+
+// BeaconServer is the server API for Beacon service.
+// All implementations must embed UnimplementedBeaconServer
+// for forward compatibility.
+//
+// Beacon serves pulses that the server verified against the beacon's signing certificate
+type BeaconServer interface {
+	// Last returns the latest pulse
+	Last(context.Context, *LastRequest) (*Pulse, error)
+	// At returns the pulse closest to the time
+	At(context.Context, *TimeRequest) (*Pulse, error)
+	// Next returns the pulse following the time
+	Next(context.Context, *TimeRequest) (*Pulse, error)
+	// Previous returns the pulse preceding the time
+	Previous(context.Context, *TimeRequest) (*Pulse, error)
+	// Watch streams every new pulse
+	Watch(*WatchRequest, grpc.ServerStreamingServer[Pulse]) error
+	mustEmbedUnimplementedBeaconServer()
+}
+
+// UnimplementedBeaconServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBeaconServer struct{}
+
+func (UnimplementedBeaconServer) Last(context.Context, *LastRequest) (*Pulse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Last not implemented")
+}
+func (UnimplementedBeaconServer) At(context.Context, *TimeRequest) (*Pulse, error) {
+	return nil, status.Error(codes.Unimplemented, "method At not implemented")
+}
+func (UnimplementedBeaconServer) Next(context.Context, *TimeRequest) (*Pulse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Next not implemented")
+}
+func (UnimplementedBeaconServer) Previous(context.Context, *TimeRequest) (*Pulse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Previous not implemented")
+}
+func (UnimplementedBeaconServer) Watch(*WatchRequest, grpc.ServerStreamingServer[Pulse]) error {
+	return status.Error(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedBeaconServer) mustEmbedUnimplementedBeaconServer() {}
+func (UnimplementedBeaconServer) testEmbeddedByValue()                {}
+
+// UnsafeBeaconServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BeaconServer will
+// result in compilation errors.
+type UnsafeBeaconServer interface {
+	mustEmbedUnimplementedBeaconServer()
+}
+
+func RegisterBeaconServer(s grpc.ServiceRegistrar, srv BeaconServer) {
+	// If the following call panics, it indicates UnimplementedBeaconServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Beacon_ServiceDesc, srv)
+}
+
+func _Beacon_Last_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeaconServer).Last(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Beacon_Last_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeaconServer).Last(ctx, req.(*LastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Beacon_At_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeaconServer).At(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Beacon_At_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeaconServer).At(ctx, req.(*TimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Beacon_Next_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeaconServer).Next(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Beacon_Next_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeaconServer).Next(ctx, req.(*TimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Beacon_Previous_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BeaconServer).Previous(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Beacon_Previous_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BeaconServer).Previous(ctx, req.(*TimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Beacon_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BeaconServer).Watch(m, &grpc.GenericServerStream[WatchRequest, Pulse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Beacon_WatchServer = grpc.ServerStreamingServer[Pulse]
+
+// Beacon_ServiceDesc is the grpc.ServiceDesc for Beacon service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Beacon_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "beacon.v2.Beacon",
+	HandlerType: (*BeaconServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Last",
+			Handler:    _Beacon_Last_Handler,
+		},
+		{
+			MethodName: "At",
+			Handler:    _Beacon_At_Handler,
+		},
+		{
+			MethodName: "Next",
+			Handler:    _Beacon_Next_Handler,
+		},
+		{
+			MethodName: "Previous",
+			Handler:    _Beacon_Previous_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Beacon_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "beacon.proto",
+}
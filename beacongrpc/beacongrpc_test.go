@@ -0,0 +1,88 @@
+package beacongrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func testClient(t *testing.T, upstream *beacontest.Server) *Client {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterBeaconServer(srv, NewServer(upstream.Client()))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewClient(conn)
+}
+
+func TestRoundTrip(t *testing.T) {
+	upstream := beacontest.NewServer()
+	defer upstream.Close()
+	rec, _ := upstream.Record(5)
+
+	got := FromProto(ToProto(rec))
+	if err := beacon.Verify(got, upstream.Certificate); err != nil {
+		t.Errorf("the converted record failed verification: %v", err)
+	}
+	if !got.Pulse.TimeStamp.Equal(rec.Pulse.TimeStamp) || got.Pulse.OutputValue != rec.Pulse.OutputValue {
+		t.Error("the conversion lost fields")
+	}
+}
+
+func TestService(t *testing.T) {
+	upstream := beacontest.NewServer()
+	defer upstream.Close()
+	c := testClient(t, upstream)
+	ctx := context.Background()
+
+	last, err := c.Last(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := beacon.Verify(last, upstream.Certificate); err != nil {
+		t.Errorf("the served pulse failed verification: %v", err)
+	}
+
+	prev, err := c.Previous(ctx, last.Pulse.TimeStamp)
+	if err != nil || prev.Pulse.PulseIndex != last.Pulse.PulseIndex-1 {
+		t.Errorf("previous pulse: got %d, %v", prev.Pulse.PulseIndex, err)
+	}
+	at, err := c.At(ctx, prev.Pulse.TimeStamp)
+	if err != nil || at.Pulse.PulseIndex != prev.Pulse.PulseIndex {
+		t.Errorf("pulse at %s: got %d, %v", prev.Pulse.TimeStamp, at.Pulse.PulseIndex, err)
+	}
+	if _, err := c.Next(ctx, last.Pulse.TimeStamp.Add(time.Hour)); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a future pulse, got %v", err)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	for _, kind := range []error{beacon.ErrNotFound, beacon.ErrUnavailable, beacon.ErrSignatureInvalid, beacon.ErrMalformedResponse} {
+		err := errorFromStatus(statusFromError(&beacon.Error{Kind: kind, Err: errors.New("upstream")}))
+		if !errors.Is(err, kind) {
+			t.Errorf("expected %v to cross the gRPC boundary, got %v", kind, err)
+		}
+		if kind == beacon.ErrMalformedResponse && errors.Is(err, beacon.ErrSignatureInvalid) {
+			t.Error("expected a malformed response not to be reported as a forged signature")
+		}
+	}
+	if err := errorFromStatus(statusFromError(errors.New("bug"))); errors.Is(err, beacon.ErrMalformedResponse) {
+		t.Errorf("expected other internal errors to stay unclassified, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package beacongrpc
+
+import (
+	"context"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Client fetches pulses from a gRPC beacon server. It implements beacon.BeaconSource.
+type Client struct {
+	c BeaconClient
+}
+
+var _ beacon.BeaconSource = (*Client)(nil)
+
+// NewClient returns a client using conn
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{c: NewBeaconClient(conn)}
+}
+
+// malformed reports whether s is the status of a malformed upstream response
+func malformed(s *status.Status) bool {
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason == reasonMalformed {
+			return true
+		}
+	}
+	return false
+}
+
+// errorFromStatus converts a gRPC status back to a beacon error
+func errorFromStatus(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	var kind error
+	switch s.Code() {
+	case codes.NotFound:
+		kind = beacon.ErrNotFound
+	case codes.Unavailable:
+		kind = beacon.ErrUnavailable
+	case codes.DataLoss:
+		kind = beacon.ErrSignatureInvalid
+	case codes.Internal:
+		if !malformed(s) {
+			return err
+		}
+		kind = beacon.ErrMalformedResponse
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	case codes.Canceled:
+		return context.Canceled
+	default:
+		return err
+	}
+	return &beacon.Error{Kind: kind, Err: err}
+}
+
+func record(pb *Pulse, err error) (beacon.Record, error) {
+	if err != nil {
+		return beacon.Record{}, errorFromStatus(err)
+	}
+	return FromProto(pb), nil
+}
+
+// Last fetches the latest pulse
+func (c *Client) Last(ctx context.Context) (beacon.Record, error) {
+	return record(c.c.Last(ctx, &LastRequest{}))
+}
+
+// At fetches the pulse closest to t
+func (c *Client) At(ctx context.Context, t time.Time) (beacon.Record, error) {
+	return record(c.c.At(ctx, &TimeRequest{Time: timestamppb.New(t)}))
+}
+
+// Next fetches the pulse following t
+func (c *Client) Next(ctx context.Context, t time.Time) (beacon.Record, error) {
+	return record(c.c.Next(ctx, &TimeRequest{Time: timestamppb.New(t)}))
+}
+
+// Previous fetches the pulse preceding t
+func (c *Client) Previous(ctx context.Context, t time.Time) (beacon.Record, error) {
+	return record(c.c.Previous(ctx, &TimeRequest{Time: timestamppb.New(t)}))
+}
+
+// Watch calls fn with every new pulse until ctx is done or the stream fails
+func (c *Client) Watch(ctx context.Context, fn func(beacon.Record)) error {
+	stream, err := c.c.Watch(ctx, &WatchRequest{})
+	if err != nil {
+		return errorFromStatus(err)
+	}
+	for {
+		pb, err := stream.Recv()
+		if err != nil {
+			return errorFromStatus(err)
+		}
+		fn(FromProto(pb))
+	}
+}
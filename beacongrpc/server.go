@@ -0,0 +1,180 @@
+// Package beacongrpc exposes beacon pulses over gRPC, so services in any language can consume verified pulses from a single Go sidecar
+// instead of each implementing parsing and signature verification
+package beacongrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative beacon.proto
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto converts a record to its protobuf form
+func ToProto(rec beacon.Record) *Pulse {
+	p := &rec.Pulse
+	pb := &Pulse{
+		Uri:              p.URI,
+		Version:          p.Version,
+		CipherSuite:      int32(p.CipherSuite),
+		Period:           int32(p.Period),
+		CertificateId:    p.CertificateID,
+		ChainIndex:       int64(p.ChainIndex),
+		PulseIndex:       int64(p.PulseIndex),
+		TimeStamp:        timestamppb.New(p.TimeStamp),
+		LocalRandomValue: p.LocalRandomValue,
+		External: &External{
+			SourceId:   p.External.SourceID,
+			StatusCode: int32(p.External.StatusCode),
+			Value:      p.External.Value,
+		},
+		PrecommitmentValue: p.PrecommitmentValue,
+		StatusCode:         int32(p.StatusCode),
+		SignatureValue:     p.SignatureValue,
+		OutputValue:        p.OutputValue,
+	}
+	for _, v := range p.ListValues {
+		pb.ListValues = append(pb.ListValues, &ListValue{Uri: v.URI, Type: v.Type, Value: v.Value})
+	}
+	return pb
+}
+
+// FromProto converts a pulse in protobuf form back to a record
+func FromProto(pb *Pulse) beacon.Record {
+	var rec beacon.Record
+	p := &rec.Pulse
+	p.URI = pb.GetUri()
+	p.Version = pb.GetVersion()
+	p.CipherSuite = int(pb.GetCipherSuite())
+	p.Period = int(pb.GetPeriod())
+	p.CertificateID = pb.GetCertificateId()
+	p.ChainIndex = int(pb.GetChainIndex())
+	p.PulseIndex = int(pb.GetPulseIndex())
+	if pb.GetTimeStamp() != nil {
+		p.TimeStamp = pb.GetTimeStamp().AsTime()
+	}
+	p.LocalRandomValue = pb.GetLocalRandomValue()
+	p.External.SourceID = pb.GetExternal().GetSourceId()
+	p.External.StatusCode = int(pb.GetExternal().GetStatusCode())
+	p.External.Value = pb.GetExternal().GetValue()
+	for _, v := range pb.GetListValues() {
+		p.ListValues = append(p.ListValues, beacon.ListValue{URI: v.GetUri(), Type: v.GetType(), Value: v.GetValue()})
+	}
+	p.PrecommitmentValue = pb.GetPrecommitmentValue()
+	p.StatusCode = int(pb.GetStatusCode())
+	p.SignatureValue = pb.GetSignatureValue()
+	p.OutputValue = pb.GetOutputValue()
+	return rec
+}
+
+// Server implements BeaconServer on top of a beacon client. Every pulse is verified before being served.
+type Server struct {
+	UnimplementedBeaconServer
+	client *beacon.Client
+}
+
+// NewServer returns a server fetching pulses with c. Register it with RegisterBeaconServer.
+func NewServer(c *beacon.Client) *Server {
+	return &Server{client: c}
+}
+
+// reasonMalformed is the reason of the ErrorInfo detailing the Internal statuses of malformed upstream responses
+const reasonMalformed = "MALFORMED_RESPONSE"
+
+// statusFromError converts a beacon error to a gRPC status
+func statusFromError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, beacon.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, beacon.ErrUnavailable), errors.Is(err, beacon.ErrStale):
+		code = codes.Unavailable
+	case errors.Is(err, beacon.ErrSignatureInvalid):
+		code = codes.DataLoss
+	case errors.Is(err, beacon.ErrMalformedResponse):
+		// a response the server couldn't parse isn't a forged one
+		s, _ := status.New(codes.Internal, err.Error()).WithDetails(&errdetails.ErrorInfo{Reason: reasonMalformed, Domain: "beacongrpc"})
+		return s.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
+
+// verified verifies the fetched record before converting it
+func (s *Server) verified(ctx context.Context, rec beacon.Record, err error) (*Pulse, error) {
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	if err := s.client.Verify(ctx, rec); err != nil {
+		return nil, statusFromError(err)
+	}
+	return ToProto(rec), nil
+}
+
+func requestTime(req *TimeRequest) (time.Time, error) {
+	if req.GetTime() == nil {
+		return time.Time{}, status.Error(codes.InvalidArgument, "Missing time")
+	}
+	return req.GetTime().AsTime(), nil
+}
+
+// Last returns the latest pulse
+func (s *Server) Last(ctx context.Context, _ *LastRequest) (*Pulse, error) {
+	rec, err := s.client.LastRecord(ctx)
+	return s.verified(ctx, rec, err)
+}
+
+// At returns the pulse closest to the requested time
+func (s *Server) At(ctx context.Context, req *TimeRequest) (*Pulse, error) {
+	t, err := requestTime(req)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := s.client.CurrentRecord(ctx, t)
+	return s.verified(ctx, rec, err)
+}
+
+// Next returns the pulse following the requested time
+func (s *Server) Next(ctx context.Context, req *TimeRequest) (*Pulse, error) {
+	t, err := requestTime(req)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := s.client.NextRecord(ctx, t)
+	return s.verified(ctx, rec, err)
+}
+
+// Previous returns the pulse preceding the requested time
+func (s *Server) Previous(ctx context.Context, req *TimeRequest) (*Pulse, error) {
+	t, err := requestTime(req)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := s.client.PreviousRecord(ctx, t)
+	return s.verified(ctx, rec, err)
+}
+
+// Watch streams every new pulse until the client goes away. Pulses failing verification are skipped.
+func (s *Server) Watch(_ *WatchRequest, stream Beacon_WatchServer) error {
+	ctx := stream.Context()
+	w := s.client.Watch(ctx)
+	defer w.Stop()
+	for rec := range w.Records() {
+		if err := s.client.Verify(ctx, rec); err != nil {
+			continue
+		}
+		if err := stream.Send(ToProto(rec)); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
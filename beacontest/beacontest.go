@@ -0,0 +1,425 @@
+// Package beacontest provides a fake beacon for testing code that uses the beacon package without reaching the live NIST service.
+//
+// The fake serves the Beacon 2.0 REST paths with a deterministic chain of records signed by a test key, and can be told to skip pulses,
+// stop publishing new pulses or return malformed responses. A Replay serves recorded records instead, such as real responses saved
+// to fixture files, in process.
+package beacontest
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+//go:embed key.pem
+var keyPEM []byte
+
+// URIPrefix is the prefix of the uri field of the served records
+const URIPrefix = "https://beacontest.invalid/beacon/2.0"
+
+// Status codes set on the served records
+const (
+	StatusNewChain = beacon.StatusNewChain
+	StatusGap      = beacon.StatusGap
+)
+
+var skiplist = []string{"hour", "day", "month", "year"}
+
+// Server is a fake beacon. Its URL can be passed to beacon.WithBaseURL, or its HTTPClient can be used to redirect any beacon request to it.
+type Server struct {
+	*httptest.Server
+
+	// Key is the private key signing the records
+	Key *rsa.PrivateKey
+	// Certificate holds the public key of Key, it is served on the certificate endpoint
+	Certificate *x509.Certificate
+	// CertificateID is the id of Certificate as it appears in the records
+	CertificateID string
+
+	certPEM []byte
+	origin  time.Time
+	period  time.Duration
+	chain   int
+	gaps    map[int64]bool
+
+	mu        sync.Mutex
+	records   []beacon.Record
+	next      time.Time
+	skipped   bool
+	firsts    map[string]beacon.ListValue
+	stale     time.Duration
+	malformed bool
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithOrigin sets the timestamp of the first pulse of the chain. It defaults to one hour before the server is started.
+func WithOrigin(t time.Time) Option {
+	return func(s *Server) {
+		s.origin = t
+	}
+}
+
+// WithPeriod sets the interval between pulses, one minute by default
+func WithPeriod(d time.Duration) Option {
+	return func(s *Server) {
+		s.period = d
+	}
+}
+
+// WithChainIndex sets the chain index of the served records, 1 by default
+func WithChainIndex(chain int) Option {
+	return func(s *Server) {
+		s.chain = chain
+	}
+}
+
+// WithGap makes the beacon skip the pulse that should have been published at t. The following pulse carries the StatusGap status code.
+func WithGap(t time.Time) Option {
+	return func(s *Server) {
+		s.gaps[t.UnixMilli()] = true
+	}
+}
+
+// NewServer starts a fake beacon. Records with the same origin, period, chain index and gaps are identical across servers.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		period: time.Minute,
+		chain:  1,
+		gaps:   make(map[int64]bool),
+		firsts: make(map[string]beacon.ListValue),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.origin.IsZero() {
+		s.origin = time.Now().Add(-time.Hour)
+	}
+	s.origin = s.origin.Truncate(s.period).UTC()
+	s.next = s.origin
+
+	gaps := make(map[int64]bool)
+	for t := range s.gaps {
+		gaps[time.UnixMilli(t).Truncate(s.period).UnixMilli()] = true
+	}
+	s.gaps = gaps
+
+	s.loadKey()
+	s.Server = httptest.NewServer(handler(s))
+	return s
+}
+
+func (s *Server) loadKey() {
+	rest := keyPEM
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+			if err != nil {
+				panic("beacontest: invalid embedded key: " + err.Error())
+			}
+			s.Key = key
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				panic("beacontest: invalid embedded certificate: " + err.Error())
+			}
+			s.Certificate = cert
+			s.certPEM = pem.EncodeToMemory(block)
+			sum := sha512.Sum512(cert.Raw)
+			s.CertificateID = strings.ToUpper(hex.EncodeToString(sum[:]))
+		}
+	}
+}
+
+// Client returns a beacon client pointed at the server
+func (s *Server) Client(opts ...beacon.Option) *beacon.Client {
+	return beacon.NewClient(append([]beacon.Option{beacon.WithBaseURL(s.URL)}, opts...)...)
+}
+
+type redirect struct {
+	host *url.URL
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.host.Scheme
+	req.URL.Host = r.host.Host
+	req.Host = r.host.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// HTTPClient returns an http client sending every request to the server, whatever its host. Paths starting with /beacon/2.0 are
+// served like the NIST ones, hence beacon.NewClient(beacon.WithHTTPClient(s.HTTPClient())) queries the fake while keeping the default base URL.
+func (s *Server) HTTPClient() *http.Client {
+	u, _ := url.Parse(s.URL)
+	return &http.Client{Transport: redirect{u}}
+}
+
+// SetStale makes the beacon behave as if it stopped publishing pulses d ago. Zero resumes normal publishing.
+func (s *Server) SetStale(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stale = d
+}
+
+// SetMalformed makes every record response an invalid document
+func (s *Server) SetMalformed(malformed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.malformed = malformed
+}
+
+func hash(parts ...[]byte) string {
+	h := sha512.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+func localRandomValue(chain, pulse int) []byte {
+	b := make([]byte, 16)
+	binary.BigEndian.PutUint64(b, uint64(chain))
+	binary.BigEndian.PutUint64(b[8:], uint64(pulse))
+	return []byte(hash([]byte("beacontest local random value"), b))
+}
+
+func periodStart(t time.Time, typ string) time.Time {
+	switch typ {
+	case "hour":
+		return t.Truncate(time.Hour)
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// generate extends the chain with every pulse due up to t
+func (s *Server) generate(t time.Time) {
+	for ; !s.next.After(t); s.next = s.next.Add(s.period) {
+		if s.gaps[s.next.UnixMilli()] {
+			s.skipped = true
+			continue
+		}
+		s.records = append(s.records, s.makeRecord(s.next))
+		s.skipped = false
+	}
+}
+
+func (s *Server) makeRecord(ts time.Time) beacon.Record {
+	zero := strings.Repeat("0", 128)
+	index := len(s.records) + 1
+
+	var rec beacon.Record
+	p := &rec.Pulse
+	p.URI = fmt.Sprintf("%s/chain/%d/pulse/%d", URIPrefix, s.chain, index)
+	p.Version = "2.0"
+	p.CipherSuite = 0
+	p.Period = int(s.period / time.Millisecond)
+	p.CertificateID = s.CertificateID
+	p.ChainIndex = s.chain
+	p.PulseIndex = index
+	p.TimeStamp = ts
+	p.LocalRandomValue = string(localRandomValue(s.chain, index))
+	p.External.SourceID = zero
+	p.External.Value = zero
+	p.PrecommitmentValue = hash(localRandomValue(s.chain, index+1))
+
+	prev := beacon.ListValue{Type: "previous", Value: zero}
+	if index == 1 {
+		p.StatusCode |= StatusNewChain
+	} else {
+		last := s.records[index-2]
+		prev.URI = last.Pulse.URI
+		prev.Value = last.Pulse.OutputValue
+	}
+	if s.skipped {
+		p.StatusCode |= StatusGap
+	}
+	p.ListValues = append(p.ListValues, prev)
+	for _, typ := range skiplist {
+		v, ok := s.firsts[typ]
+		if !ok {
+			v = beacon.ListValue{Value: zero}
+		}
+		v.Type = typ
+		p.ListValues = append(p.ListValues, v)
+	}
+
+	signed, err := rec.SignedBytes()
+	if err != nil {
+		panic("beacontest: " + err.Error())
+	}
+	sum := sha512.Sum512(signed)
+	sig, err := rsa.SignPKCS1v15(nil, s.Key, crypto.SHA512, sum[:])
+	if err != nil {
+		panic("beacontest: " + err.Error())
+	}
+	p.SignatureValue = strings.ToUpper(hex.EncodeToString(sig))
+	out, err := rec.ComputeOutputValue()
+	if err != nil {
+		panic("beacontest: " + err.Error())
+	}
+	p.OutputValue = strings.ToUpper(hex.EncodeToString(out))
+
+	for _, typ := range skiplist {
+		if index == 1 || !periodStart(ts, typ).Equal(periodStart(s.records[index-2].Pulse.TimeStamp, typ)) {
+			s.firsts[typ] = beacon.ListValue{URI: p.URI, Type: typ, Value: p.OutputValue}
+		}
+	}
+	return rec
+}
+
+// published returns the records published so far
+func (s *Server) published() []beacon.Record {
+	cutoff := time.Now().Add(-s.stale)
+	s.generate(cutoff)
+	n := sort.Search(len(s.records), func(i int) bool {
+		return s.records[i].Pulse.TimeStamp.After(cutoff)
+	})
+	return s.records[:n]
+}
+
+// Records returns every record published so far, oldest first
+func (s *Server) Records() []beacon.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]beacon.Record(nil), s.published()...)
+}
+
+// Record returns the published record with the given pulse index
+func (s *Server) Record(pulse int) (beacon.Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recs := s.published()
+	if pulse < 1 || pulse > len(recs) {
+		return beacon.Record{}, false
+	}
+	return recs[pulse-1], true
+}
+
+// source is what the fake beacon serves: a Server or a Replay
+type source interface {
+	// serve writes the published record at the index returned by pick, or a 404 if it is out of range
+	serve(w http.ResponseWriter, pick func([]beacon.Record) int)
+	// certificate returns the PEM encoding of the certificate with the given id
+	certificate(id string) ([]byte, bool)
+}
+
+// handler serves the Beacon 2.0 REST paths from s
+func handler(s source) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pulse/last", func(w http.ResponseWriter, r *http.Request) {
+		s.serve(w, func(recs []beacon.Record) int {
+			return len(recs) - 1
+		})
+	})
+	mux.HandleFunc("GET /pulse/time/{ts}", func(w http.ResponseWriter, r *http.Request) {
+		t, ok := beacon.ParseTimeParam(r.PathValue("ts"))
+		s.serve(w, func(recs []beacon.Record) int {
+			if !ok {
+				return -1
+			}
+			return sort.Search(len(recs), func(i int) bool { return recs[i].Pulse.TimeStamp.After(t) }) - 1
+		})
+	})
+	mux.HandleFunc("GET /pulse/time/previous/{ts}", func(w http.ResponseWriter, r *http.Request) {
+		t, ok := beacon.ParseTimeParam(r.PathValue("ts"))
+		s.serve(w, func(recs []beacon.Record) int {
+			if !ok {
+				return -1
+			}
+			return sort.Search(len(recs), func(i int) bool { return !recs[i].Pulse.TimeStamp.Before(t) }) - 1
+		})
+	})
+	mux.HandleFunc("GET /pulse/time/next/{ts}", func(w http.ResponseWriter, r *http.Request) {
+		t, ok := beacon.ParseTimeParam(r.PathValue("ts"))
+		s.serve(w, func(recs []beacon.Record) int {
+			if !ok {
+				return -1
+			}
+			return sort.Search(len(recs), func(i int) bool { return recs[i].Pulse.TimeStamp.After(t) })
+		})
+	})
+	mux.HandleFunc("GET /chain/{chain}/pulse/{pulse}", func(w http.ResponseWriter, r *http.Request) {
+		chain, err1 := strconv.Atoi(r.PathValue("chain"))
+		pulse, err2 := strconv.Atoi(r.PathValue("pulse"))
+		s.serve(w, func(recs []beacon.Record) int {
+			if err1 != nil || err2 != nil {
+				return -1
+			}
+			return slices.IndexFunc(recs, func(rec beacon.Record) bool {
+				return rec.Pulse.ChainIndex == chain && rec.Pulse.PulseIndex == pulse
+			})
+		})
+	})
+	mux.HandleFunc("GET /certificate/{id}", func(w http.ResponseWriter, r *http.Request) {
+		pem, ok := s.certificate(r.PathValue("id"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		w.Write(pem)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/beacon/2.0")
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) certificate(id string) ([]byte, bool) {
+	return s.certPEM, strings.EqualFold(id, s.CertificateID)
+}
+
+func (s *Server) serve(w http.ResponseWriter, pick func([]beacon.Record) int) {
+	s.mu.Lock()
+	recs := s.published()
+	i := pick(recs)
+	malformed := s.malformed
+	var rec beacon.Record
+	if i >= 0 && i < len(recs) {
+		rec = recs[i]
+	}
+	s.mu.Unlock()
+
+	if i < 0 || i >= len(recs) {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if malformed {
+		w.Write([]byte(`{"pulse": {"uri": `))
+		return
+	}
+	json.NewEncoder(w).Encode(rec)
+}
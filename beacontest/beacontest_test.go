@@ -0,0 +1,83 @@
+package beacontest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+func TestServerRecordsVerify(t *testing.T) {
+	s := NewServer(WithOrigin(time.Now().Add(-10 * time.Minute)))
+	defer s.Close()
+
+	c := s.Client()
+	rec, err := c.LastRecord(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := beacon.NewCertificateManager(c).ForRecord(context.Background(), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := beacon.Verify(rec, cert); err != nil {
+		t.Error(err)
+	}
+
+	recs := s.Records()
+	if len(recs) < 10 {
+		t.Fatalf("expected at least 10 records, got %d", len(recs))
+	}
+	if err := beacon.VerifyChain(recs); err != nil {
+		t.Error(err)
+	}
+	if recs[0].Pulse.StatusCode != StatusNewChain {
+		t.Errorf("expected the first record to start a new chain, got status %d", recs[0].Pulse.StatusCode)
+	}
+}
+
+func TestServerDeterministic(t *testing.T) {
+	origin := time.Now().Add(-5 * time.Minute)
+	a := NewServer(WithOrigin(origin))
+	defer a.Close()
+	b := NewServer(WithOrigin(origin))
+	defer b.Close()
+
+	ra, _ := a.Record(3)
+	rb, _ := b.Record(3)
+	if ra.Pulse.OutputValue == "" || ra.Pulse.OutputValue != rb.Pulse.OutputValue {
+		t.Error("servers with the same origin served different records")
+	}
+}
+
+func TestServerGap(t *testing.T) {
+	origin := time.Now().Add(-10 * time.Minute).Truncate(time.Minute)
+	s := NewServer(WithOrigin(origin), WithGap(origin.Add(3*time.Minute)))
+	defer s.Close()
+
+	rec, err := s.Client().NextRecord(context.Background(), origin.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rec.Pulse.TimeStamp.Equal(origin.Add(4*time.Minute)) || rec.Pulse.StatusCode != StatusGap {
+		t.Errorf("expected the pulse after the gap, got %s with status %d", rec.Pulse.TimeStamp, rec.Pulse.StatusCode)
+	}
+}
+
+func TestServerStaleAndMalformed(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := s.Client()
+
+	s.SetStale(10 * time.Minute)
+	if _, err := c.LastRecord(context.Background()); err == nil {
+		t.Error("expected a stale beacon to be reported")
+	}
+	s.SetStale(0)
+
+	s.SetMalformed(true)
+	if _, err := c.LastRecord(context.Background()); err == nil {
+		t.Error("expected a malformed response to fail")
+	}
+}
@@ -0,0 +1,184 @@
+package beacontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// Mode selects whether a Recorder answers from its cassette or from the network
+type Mode int
+
+const (
+	// ModeAuto replays the cassette if its file exists and records it otherwise
+	ModeAuto Mode = iota
+	// ModeReplay answers every request from the cassette, failing those it holds no exchange for
+	ModeReplay
+	// ModeRecord sends every request to the network and records the exchange, replacing the cassette on Save
+	ModeRecord
+)
+
+// ErrNotRecorded is returned by a replaying Recorder for a request missing from its cassette
+var ErrNotRecorded = errors.New("No recorded exchange for the request")
+
+// Interaction is an exchange of a cassette. Request headers aren't recorded, so credentials sent to the beacon don't end up on disk.
+type Interaction struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper capturing the exchanges of a client with the live beacon to a cassette file, then replaying them
+// so integration tests exercise real payloads without depending on the beacon's availability. It is safe for concurrent use.
+type Recorder struct {
+	path      string
+	next      http.RoundTripper
+	recording bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	// replayed counts the exchanges replayed by method and url, repeated requests being answered by the recorded ones in turn
+	replayed map[string]int
+}
+
+// NewRecorder returns a recorder of the cassette at path in the given mode, sending the recorded requests through next, or
+// http.DefaultTransport if nil
+func NewRecorder(path string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{path: path, next: next, replayed: make(map[string]int)}
+	buf, err := os.ReadFile(path)
+	switch {
+	case mode == ModeRecord || mode == ModeAuto && errors.Is(err, os.ErrNotExist):
+		r.recording = true
+		return r, nil
+	case err != nil:
+		return nil, fmt.Errorf("Couldn't read the cassette: %w", err)
+	}
+	if err := json.Unmarshal(buf, &r.interactions); err != nil {
+		return nil, fmt.Errorf("Couldn't decode the cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Recording reports whether the recorder sends its requests to the network
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// RoundTrip records the exchange of req with the network, or replays the recorded one
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.recording {
+		return r.record(req)
+	}
+	key := req.Method + " " + req.URL.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matches []Interaction
+	for _, in := range r.interactions {
+		if in.Method+" "+in.URL == key {
+			matches = append(matches, in)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNotRecorded, key)
+	}
+	// the last exchange answers any further request
+	in := matches[min(r.replayed[key], len(matches)-1)]
+	r.replayed[key]++
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        in.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(in.Body))),
+		ContentLength: int64(len(in.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	// the transport then negotiates compression itself and decompresses the body, so the cassette holds readable payloads
+	req = req.Clone(req.Context())
+	req.Header.Del("Accept-Encoding")
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	header := resp.Header.Clone()
+	// the clock skew estimated from a replayed date would be off by the age of the cassette
+	header.Del("Date")
+	r.mu.Lock()
+	r.interactions = append(r.interactions, Interaction{req.Method, req.URL.String(), resp.StatusCode, header, string(body)})
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// Save writes the recorded exchanges to the cassette file atomically. It does nothing when replaying.
+func (r *Recorder) Save() error {
+	if !r.recording {
+		return nil
+	}
+	r.mu.Lock()
+	buf, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Couldn't create the cassette directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("Couldn't write the cassette: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("Couldn't write the cassette: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("Couldn't write the cassette: %w", err)
+	}
+	if err := os.Rename(f.Name(), r.path); err != nil {
+		return fmt.Errorf("Couldn't write the cassette: %w", err)
+	}
+	return nil
+}
+
+// HTTPClient returns an http client whose requests go through the recorder
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Client returns a beacon client whose requests go through the recorder. When replaying, the staleness check is disabled, the
+// cassette being older than the beacon's latest pulse; opts may enable it again.
+func (r *Recorder) Client(opts ...beacon.Option) *beacon.Client {
+	base := []beacon.Option{beacon.WithHTTPClient(r.HTTPClient())}
+	if !r.recording {
+		base = append(base, beacon.WithoutStaleness())
+	}
+	return beacon.NewClient(append(base, opts...)...)
+}
@@ -0,0 +1,78 @@
+package beacontest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+func TestRecorder(t *testing.T) {
+	s := NewServer(WithOrigin(time.Now().Add(-10 * time.Minute)))
+	recs := s.Records()
+	path := filepath.Join(t.TempDir(), "cassettes", "beacon.json")
+	ctx := context.Background()
+
+	// the first run records the exchanges with the live beacon, the following ones replay them
+	for _, live := range []bool{true, false} {
+		r, err := NewRecorder(path, ModeAuto, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Recording() != live {
+			t.Fatalf("expected recording to be %v", live)
+		}
+		c := r.Client(beacon.WithBaseURL(s.URL), beacon.WithHeader("X-Api-Key", "secret"))
+		last, err := c.LastRecord(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !last.Equal(recs[len(recs)-1]) {
+			t.Errorf("unexpected latest pulse %d", last.Pulse.PulseIndex)
+		}
+		if err := c.Verify(ctx, last); err != nil {
+			t.Errorf("couldn't verify the record: %v", err)
+		}
+		if rec, err := c.PulseByIndex(ctx, uint64(recs[2].Pulse.ChainIndex), uint64(recs[2].Pulse.PulseIndex)); err != nil || !rec.Equal(recs[2]) {
+			t.Errorf("couldn't get the record by index: %v", err)
+		}
+		if _, err := c.PulseByIndex(ctx, 9, 1); !errors.Is(err, beacon.ErrNotFound) {
+			t.Errorf("expected the recorded 404 to be replayed, got %v", err)
+		}
+		if err := r.Save(); err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf, []byte("secret")) {
+		t.Error("expected the request headers not to be recorded")
+	}
+	if !bytes.Contains(buf, []byte(recs[2].Pulse.OutputValue)) {
+		t.Error("expected the cassette to hold the decompressed payloads")
+	}
+
+	r, err := NewRecorder(path, ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.HTTPClient().Get(s.URL + "/pulse/time/0"); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("expected ErrNotRecorded, got %v", err)
+	}
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay, nil); err == nil {
+		t.Error("expected replaying a missing cassette to fail")
+	}
+	if r, err := NewRecorder(path, ModeRecord, http.DefaultTransport); err != nil || !r.Recording() {
+		t.Errorf("expected to record over the cassette: %v", err)
+	}
+}
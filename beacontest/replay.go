@@ -0,0 +1,130 @@
+package beacontest
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// Replay serves recorded records, such as responses of the live beacon saved to files, to a client without any network: its requests
+// are answered in process. Every record is served as published, whatever the time, so tests replaying the same fixtures are
+// deterministic and run offline.
+type Replay struct {
+	handler http.Handler
+	records []beacon.Record
+	// certs holds the PEM encoded certificates by upper case id
+	certs map[string][]byte
+}
+
+// NewReplay returns a replay of recs, along with the PEM encoded certificates signing them so the replayed records can be verified
+func NewReplay(recs []beacon.Record, certs ...[]byte) *Replay {
+	r := &Replay{records: slices.Clone(recs), certs: make(map[string][]byte)}
+	slices.SortStableFunc(r.records, func(a, b beacon.Record) int { return a.Pulse.TimeStamp.Compare(b.Pulse.TimeStamp) })
+	for _, buf := range certs {
+		for rest := buf; ; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			sum := sha512.Sum512(block.Bytes)
+			r.certs[strings.ToUpper(hex.EncodeToString(sum[:]))] = pem.EncodeToMemory(block)
+		}
+	}
+	r.handler = handler(r)
+	return r
+}
+
+// LoadReplay returns a replay of the fixture files matching the glob patterns: records from .json and .jsonl files, either a single
+// response of the beacon or a dump in any layout read by beacon.DecodeRecords, and certificates from .pem files
+func LoadReplay(patterns ...string) (*Replay, error) {
+	var recs []beacon.Record
+	var certs [][]byte
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			buf, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("Couldn't read the fixture: %w", err)
+			}
+			switch filepath.Ext(path) {
+			case ".pem":
+				certs = append(certs, buf)
+			case ".json", ".jsonl":
+				// a single response is served verbatim
+				if rec, err := beacon.ParseRecordLenient(buf); err == nil && !rec.Pulse.TimeStamp.IsZero() {
+					recs = append(recs, rec)
+					continue
+				}
+				for rec, err := range beacon.DecodeRecords(bytes.NewReader(buf)) {
+					if err != nil {
+						return nil, fmt.Errorf("Couldn't decode the fixture %s: %w", path, err)
+					}
+					recs = append(recs, rec)
+				}
+			}
+		}
+	}
+	return NewReplay(recs, certs...), nil
+}
+
+// Records returns the replayed records, oldest first
+func (r *Replay) Records() []beacon.Record {
+	return slices.Clone(r.records)
+}
+
+// RoundTrip answers req from the fixtures, whatever its host. Paths starting with /beacon/2.0 are served like the NIST ones.
+func (r *Replay) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	r.handler.ServeHTTP(w, req)
+	resp := w.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// HTTPClient returns an http client answering every request from the fixtures
+func (r *Replay) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Client returns a beacon client answered from the fixtures. The staleness check is disabled, the fixtures being older than the
+// beacon's latest pulse; opts may enable it again.
+func (r *Replay) Client(opts ...beacon.Option) *beacon.Client {
+	return beacon.NewClient(append([]beacon.Option{beacon.WithHTTPClient(r.HTTPClient()), beacon.WithoutStaleness()}, opts...)...)
+}
+
+func (r *Replay) certificate(id string) ([]byte, bool) {
+	buf, ok := r.certs[strings.ToUpper(id)]
+	return buf, ok
+}
+
+func (r *Replay) serve(w http.ResponseWriter, pick func([]beacon.Record) int) {
+	i := pick(r.records)
+	if i < 0 || i >= len(r.records) {
+		http.NotFound(w, nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if raw := r.records[i].Raw(); raw != nil {
+		w.Write(raw)
+		return
+	}
+	json.NewEncoder(w).Encode(r.records[i])
+}
@@ -0,0 +1,75 @@
+package beacontest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+func TestReplay(t *testing.T) {
+	s := NewServer(WithOrigin(time.Now().Add(-10 * time.Minute)))
+	recs := s.Records()
+	s.Close()
+
+	// the fixtures: a dump of the fake's records and its certificate, and a recorded response of the beacon
+	dir := t.TempDir()
+	dump, err := json.Marshal(recs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := os.ReadFile("../testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, buf := range map[string][]byte{"dump.json": dump, "cert.pem": s.certPEM, "recorded.json": recorded, "notes.txt": nil} {
+		if err := os.WriteFile(filepath.Join(dir, name), buf, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r, err := LoadReplay(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.Records()); n != len(recs)+1 {
+		t.Fatalf("expected %d records, got %d", len(recs)+1, n)
+	}
+
+	c := r.Client(beacon.WithRawResponses(), beacon.WithLenientParsing())
+	ctx := context.Background()
+	last, err := c.LastRecord(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(recs[len(recs)-1]) {
+		t.Errorf("expected the latest fixture, got pulse %d", last.Pulse.PulseIndex)
+	}
+	if err := c.Verify(ctx, last); err != nil {
+		t.Errorf("expected the replayed record to verify against the fixture certificate: %v", err)
+	}
+	rec, err := c.PulseByIndex(ctx, uint64(recs[3].Pulse.ChainIndex), uint64(recs[3].Pulse.PulseIndex))
+	if err != nil || !rec.Equal(recs[3]) {
+		t.Errorf("couldn't get the record by index: %v", err)
+	}
+
+	old, err := beacon.ParseRecordLenient(recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the certificate of the recorded response isn't a fixture
+	rec, err = r.Client(beacon.WithRawResponses(), beacon.WithLenientParsing(), beacon.WithoutVerification()).CurrentRecord(ctx, old.Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rec.Raw()) != string(recorded) {
+		t.Error("expected the recorded response to be replayed verbatim")
+	}
+	if _, err := c.PreviousRecord(ctx, old.Pulse.TimeStamp); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("expected no record before the fixtures, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package beaconws pushes every new verified pulse to WebSocket clients as JSON, for dashboards and browser-based draw ceremonies.
+// Clients reconnecting with a since query parameter get the pulses they missed within the last hour, by default, replayed first.
+package beaconws
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// DefaultMaxReplay is how far back a hub replays pulses unless it is created WithMaxReplay
+const DefaultMaxReplay = time.Hour
+
+// subscriberBuffer is how many pulses may be queued for a slow client before it is disconnected
+const subscriberBuffer = 16
+
+// Hub watches the beacon and broadcasts every pulse that passes verification to the connected clients.
+// It is an http.Handler accepting WebSocket connections. Set since to a timestamp, in seconds or milliseconds since the epoch,
+// to replay the pulses emitted after it before the live ones. A since older than the hub's replay window is rejected, so that
+// a client can't make the hub fetch the whole chain from the beacon.
+type Hub struct {
+	client    *beacon.Client
+	maxReplay time.Duration
+
+	mu   sync.Mutex
+	subs map[chan beacon.Record]struct{}
+}
+
+// HubOption configures a Hub
+type HubOption func(*Hub)
+
+// WithMaxReplay sets how far back the hub replays pulses to reconnecting clients, DefaultMaxReplay by default
+func WithMaxReplay(d time.Duration) HubOption {
+	return func(h *Hub) {
+		h.maxReplay = d
+	}
+}
+
+// NewHub returns a hub fetching and verifying pulses with c. Call Run to start broadcasting.
+func NewHub(c *beacon.Client, opts ...HubOption) *Hub {
+	h := &Hub{client: c, maxReplay: DefaultMaxReplay, subs: make(map[chan beacon.Record]struct{})}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Run watches the beacon and broadcasts new pulses until ctx is done
+func (h *Hub) Run(ctx context.Context) {
+	w := h.client.Watch(ctx)
+	defer w.Stop()
+	for rec := range w.Records() {
+		if err := h.client.Verify(ctx, rec); err != nil {
+			continue
+		}
+		h.broadcast(rec)
+	}
+}
+
+func (h *Hub) broadcast(rec beacon.Record) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- rec:
+		default:
+			// the client can't keep up, drop it
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (h *Hub) subscribe() chan beacon.Record {
+	ch := make(chan beacon.Record, subscriberBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[ch] = struct{}{}
+	return ch
+}
+
+func (h *Hub) unsubscribe(ch chan beacon.Record) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, ok := beacon.ParseTimeParam(v)
+		if !ok {
+			http.Error(w, "Invalid since timestamp", http.StatusBadRequest)
+			return
+		}
+		if time.Since(t) > h.maxReplay {
+			http.Error(w, "The since timestamp is older than the replay window of "+h.maxReplay.String(), http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+	// clients only listen, reading discards their messages and notices when they go away
+	ctx := conn.CloseRead(r.Context())
+
+	// subscribe before replaying so no pulse is missed in between
+	ch := h.subscribe()
+	defer h.unsubscribe(ch)
+
+	var last time.Time
+	if !since.IsZero() {
+		for rec, err := range h.client.Records(ctx, since.Add(time.Second), time.Now()) {
+			if err != nil {
+				conn.Close(websocket.StatusInternalError, "Couldn't replay the missed pulses")
+				return
+			}
+			if h.client.Verify(ctx, rec) != nil {
+				continue
+			}
+			if wsjson.Write(ctx, conn, rec) != nil {
+				return
+			}
+			last = rec.Pulse.TimeStamp
+		}
+	}
+
+	for {
+		select {
+		case rec, ok := <-ch:
+			if !ok {
+				conn.Close(websocket.StatusPolicyViolation, "Client too slow")
+				return
+			}
+			if !rec.Pulse.TimeStamp.After(last) {
+				continue
+			}
+			if wsjson.Write(ctx, conn, rec) != nil {
+				return
+			}
+			last = rec.Pulse.TimeStamp
+		case <-ctx.Done():
+			conn.Close(websocket.StatusNormalClosure, "")
+			return
+		}
+	}
+}
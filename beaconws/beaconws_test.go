@@ -0,0 +1,67 @@
+package beaconws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestHubReplaysThenPushes(t *testing.T) {
+	upstream := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-5*time.Second)), beacontest.WithPeriod(time.Second))
+	defer upstream.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hub := NewHub(upstream.Client())
+	go hub.Run(ctx)
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	recs := upstream.Records()
+	since := recs[1].Pulse.TimeStamp
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/?since=" + strconv.FormatInt(since.Unix(), 10)
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+
+	// the replayed pulses come first, in order, then the live ones
+	want := recs[2].Pulse.PulseIndex
+	for want <= recs[len(recs)-1].Pulse.PulseIndex+1 {
+		var rec beacon.Record
+		if err := wsjson.Read(ctx, conn, &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Pulse.PulseIndex != want {
+			t.Fatalf("got pulse %d, expected %d", rec.Pulse.PulseIndex, want)
+		}
+		if err := beacon.Verify(rec, upstream.Certificate); err != nil {
+			t.Fatal(err)
+		}
+		want++
+	}
+}
+
+func TestHubReplayWindow(t *testing.T) {
+	upstream := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-5*time.Second)), beacontest.WithPeriod(time.Second))
+	defer upstream.Close()
+	srv := httptest.NewServer(NewHub(upstream.Client(), WithMaxReplay(time.Minute)))
+	defer srv.Close()
+
+	since := time.Now().Add(-2 * time.Minute)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/?since=" + strconv.FormatInt(since.Unix(), 10)
+	_, resp, err := websocket.Dial(context.Background(), url, nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected a since beyond the replay window to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package beacon
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// binaryVersion is the version byte leading the binary encoding of records, bumped whenever fields are added
+const binaryVersion = 1
+
+// MarshalBinary encodes the record compactly: a version byte followed by the fields in the order of the pulse, hex values as raw bytes.
+func (rec Record) MarshalBinary() ([]byte, error) {
+	p := &rec.Pulse
+	s := &serializer{}
+	s.buf.WriteByte(binaryVersion)
+	s.string(p.URI)
+	s.string(p.Version)
+	s.uint32(p.CipherSuite)
+	s.uint32(p.Period)
+	s.hex("certificate id", p.CertificateID)
+	s.uint64(p.ChainIndex)
+	s.uint64(p.PulseIndex)
+	s.uint64(int(p.TimeStamp.UnixMilli()))
+	s.hex("local random value", p.LocalRandomValue)
+	s.hex("external source id", p.External.SourceID)
+	s.uint32(p.External.StatusCode)
+	s.hex("external value", p.External.Value)
+	s.uint32(len(p.ListValues))
+	for _, v := range p.ListValues {
+		s.string(v.URI)
+		s.string(v.Type)
+		s.hex(v.Type+" list value", v.Value)
+	}
+	s.hex("precommitment value", p.PrecommitmentValue)
+	s.uint32(p.StatusCode)
+	s.hex("signature", p.SignatureValue)
+	s.hex("output value", p.OutputValue)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.buf.Bytes(), nil
+}
+
+type deserializer struct {
+	buf []byte
+	err error
+}
+
+func (d *deserializer) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errors.New("Truncated binary record")
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *deserializer) uint32() int {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(b))
+}
+
+func (d *deserializer) uint64() int {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int(binary.BigEndian.Uint64(b))
+}
+
+func (d *deserializer) bytes() []byte {
+	return d.next(d.uint32())
+}
+
+func (d *deserializer) string() string {
+	return string(d.bytes())
+}
+
+func (d *deserializer) hex() string {
+	return strings.ToUpper(hex.EncodeToString(d.bytes()))
+}
+
+// UnmarshalBinary decodes a record encoded by MarshalBinary. Hex values are restored in upper case, as served by the beacon.
+func (rec *Record) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("Empty binary record")
+	}
+	if data[0] != binaryVersion {
+		return errors.New(fmt.Sprintf("Unsupported binary record version %d", data[0]))
+	}
+
+	var r Record
+	p := &r.Pulse
+	d := &deserializer{buf: data[1:]}
+	p.URI = d.string()
+	p.Version = d.string()
+	p.CipherSuite = d.uint32()
+	p.Period = d.uint32()
+	p.CertificateID = d.hex()
+	p.ChainIndex = d.uint64()
+	p.PulseIndex = d.uint64()
+	p.TimeStamp = time.UnixMilli(int64(d.uint64())).UTC()
+	p.LocalRandomValue = d.hex()
+	p.External.SourceID = d.hex()
+	p.External.StatusCode = d.uint32()
+	p.External.Value = d.hex()
+	n := d.uint32()
+	if n > len(d.buf) {
+		return errors.New("Truncated binary record")
+	}
+	for i := 0; i < n && d.err == nil; i++ {
+		p.ListValues = append(p.ListValues, ListValue{URI: d.string(), Type: d.string(), Value: d.hex()})
+	}
+	p.PrecommitmentValue = d.hex()
+	p.StatusCode = d.uint32()
+	p.SignatureValue = d.hex()
+	p.OutputValue = d.hex()
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.buf) != 0 {
+		return errors.New("Trailing data after the binary record")
+	}
+	*rec = r
+	return nil
+}
@@ -0,0 +1,33 @@
+package beacon
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	rec := fixtureRecord(t)
+	buf, err := rec.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got Record
+	if err := got.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, rec) {
+		t.Errorf("round trip changed the record:\n%+v\n%+v", got, rec)
+	}
+
+	for _, n := range []int{0, 1, len(buf) / 2, len(buf) - 1} {
+		if err := got.UnmarshalBinary(buf[:n]); err == nil {
+			t.Errorf("expected a record truncated to %d bytes to fail", n)
+		}
+	}
+
+	buf[0] = 99
+	if err := got.UnmarshalBinary(buf); err == nil {
+		t.Error("expected an unknown version to fail")
+	}
+}
@@ -0,0 +1,143 @@
+package beacon
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// BinaryListValue is a list value of a BinaryPulse
+type BinaryListValue struct {
+	URI   string
+	Type  string
+	Value [64]byte
+}
+
+// BinaryPulse is a pulse holding its values as bytes rather than hex strings: 512-bit values are fixed-size arrays, which keep their
+// leading zeros and can't be mistaken for numbers, and the signature is sized after the signing key
+type BinaryPulse struct {
+	URI         string
+	Version     string
+	CipherSuite int
+	Period      time.Duration
+	// CertificateID is the SHA-512 hash of the signing certificate
+	CertificateID [64]byte
+	ChainIndex    uint64
+	PulseIndex    uint64
+	TimeStamp     time.Time
+	// SeedValue is the local random value of the pulse, the seed value of the 1.0 protocol
+	SeedValue          [64]byte
+	ExternalSourceID   [64]byte
+	ExternalStatusCode int
+	ExternalValue      [64]byte
+	// ListValues are the list values of the pulse in document order, the previous output value among them
+	ListValues         []BinaryListValue
+	PrecommitmentValue [64]byte
+	StatusCode         int
+	Signature          []byte
+	OutputValue        [64]byte
+}
+
+// BinaryPulse decodes the record's values into a BinaryPulse, failing if one doesn't have its expected size
+func (rec *Record) BinaryPulse() (BinaryPulse, error) {
+	p := &rec.Pulse
+	b := BinaryPulse{
+		URI:                p.URI,
+		Version:            p.Version,
+		CipherSuite:        p.CipherSuite,
+		Period:             time.Duration(p.Period) * time.Millisecond,
+		ChainIndex:         uint64(p.ChainIndex),
+		PulseIndex:         uint64(p.PulseIndex),
+		TimeStamp:          p.TimeStamp,
+		ExternalStatusCode: p.External.StatusCode,
+		StatusCode:         p.StatusCode,
+		Signature:          make([]byte, len(p.SignatureValue)/2),
+	}
+	for _, f := range [...]struct {
+		dst        []byte
+		name, hexv string
+	}{
+		{b.CertificateID[:], "certificate id", p.CertificateID},
+		{b.SeedValue[:], "local random value", p.LocalRandomValue},
+		{b.ExternalSourceID[:], "external source id", p.External.SourceID},
+		{b.ExternalValue[:], "external value", p.External.Value},
+		{b.PrecommitmentValue[:], "precommitment value", p.PrecommitmentValue},
+		{b.OutputValue[:], "output value", p.OutputValue},
+	} {
+		if err := decodeFixed(f.dst, f.hexv); err != nil {
+			return BinaryPulse{}, fmt.Errorf("Couldn't decode the record's %s: %w", f.name, err)
+		}
+	}
+	if err := decodeHex(b.Signature, p.SignatureValue); err != nil {
+		return BinaryPulse{}, fmt.Errorf("Couldn't decode the record's signature: %w", err)
+	}
+	if len(p.ListValues) > 0 {
+		b.ListValues = make([]BinaryListValue, len(p.ListValues))
+	}
+	for i, lv := range p.ListValues {
+		b.ListValues[i] = BinaryListValue{URI: lv.URI, Type: lv.Type}
+		if err := decodeFixed(b.ListValues[i].Value[:], lv.Value); err != nil {
+			return BinaryPulse{}, fmt.Errorf("Couldn't decode the record's %s list value: %w", lv.Type, err)
+		}
+	}
+	return b, nil
+}
+
+// Record encodes the pulse back into a record, reversing Record.BinaryPulse, with its values hex encoded in upper case as the beacon
+// serves them
+func (b *BinaryPulse) Record() Record {
+	var rec Record
+	p := &rec.Pulse
+	p.URI = b.URI
+	p.Version = b.Version
+	p.CipherSuite = b.CipherSuite
+	p.Period = int(b.Period / time.Millisecond)
+	p.CertificateID = encodeHex(b.CertificateID[:])
+	p.ChainIndex = int(b.ChainIndex)
+	p.PulseIndex = int(b.PulseIndex)
+	p.TimeStamp = b.TimeStamp
+	p.LocalRandomValue = encodeHex(b.SeedValue[:])
+	p.External = External{SourceID: encodeHex(b.ExternalSourceID[:]), StatusCode: b.ExternalStatusCode, Value: encodeHex(b.ExternalValue[:])}
+	for _, lv := range b.ListValues {
+		p.ListValues = append(p.ListValues, ListValue{URI: lv.URI, Type: lv.Type, Value: encodeHex(lv.Value[:])})
+	}
+	p.PrecommitmentValue = encodeHex(b.PrecommitmentValue[:])
+	p.StatusCode = b.StatusCode
+	p.SignatureValue = encodeHex(b.Signature)
+	p.OutputValue = encodeHex(b.OutputValue[:])
+	return rec
+}
+
+// ListValue returns the list value of the given type, such as "previous" or "hour"
+func (b *BinaryPulse) ListValue(typ string) ([64]byte, bool) {
+	for _, lv := range b.ListValues {
+		if lv.Type == typ {
+			return lv.Value, true
+		}
+	}
+	return [64]byte{}, false
+}
+
+// PreviousOutputValue returns the output value of the previous pulse, unless the pulse lacks it such as the first one of a chain
+func (b *BinaryPulse) PreviousOutputValue() ([64]byte, bool) {
+	return b.ListValue("previous")
+}
+
+// OutputInt returns the output value as an unsigned integer, as the 1.0 client exposed it
+func (b *BinaryPulse) OutputInt() *big.Int {
+	return new(big.Int).SetBytes(b.OutputValue[:])
+}
+
+// SeedInt returns the seed value as an unsigned integer, as the 1.0 client exposed it
+func (b *BinaryPulse) SeedInt() *big.Int {
+	return new(big.Int).SetBytes(b.SeedValue[:])
+}
+
+// PreviousOutputInt returns the previous output value as an unsigned integer, nil if the pulse lacks it
+func (b *BinaryPulse) PreviousOutputInt() *big.Int {
+	v, ok := b.PreviousOutputValue()
+	if !ok {
+		return nil
+	}
+	return new(big.Int).SetBytes(v[:])
+}
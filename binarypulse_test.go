@@ -0,0 +1,92 @@
+package beacon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBinaryPulse(t *testing.T) {
+	rec := fixtureRecord(t)
+	// a value with leading zero bytes, which a big.Int wouldn't keep
+	rec.Pulse.OutputValue = "0000" + rec.Pulse.OutputValue[4:]
+	b, err := rec.BinaryPulse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &rec.Pulse
+	if b.URI != p.URI || b.ChainIndex != uint64(p.ChainIndex) || b.PulseIndex != uint64(p.PulseIndex) || !b.TimeStamp.Equal(p.TimeStamp) ||
+		b.Period != time.Duration(p.Period)*time.Millisecond || b.StatusCode != p.StatusCode {
+		t.Errorf("unexpected fields %+v", b)
+	}
+	for name, tc := range map[string]struct {
+		got  []byte
+		want string
+	}{
+		"output value": {b.OutputValue[:], p.OutputValue},
+		"seed value":   {b.SeedValue[:], p.LocalRandomValue},
+		"signature":    {b.Signature, p.SignatureValue},
+	} {
+		if want, _ := hex.DecodeString(tc.want); !bytes.Equal(tc.got, want) {
+			t.Errorf("the %s was decoded into %x, expected %x", name, tc.got, want)
+		}
+	}
+	if b.OutputValue[0] != 0 || b.OutputValue[1] != 0 {
+		t.Error("expected the leading zeros of the output value to be kept")
+	}
+	if !strings.EqualFold(b.OutputInt().Text(16), strings.TrimLeft(p.OutputValue, "0")) ||
+		!strings.EqualFold(b.SeedInt().Text(16), strings.TrimLeft(p.LocalRandomValue, "0")) {
+		t.Error("unexpected integer values")
+	}
+
+	prev, ok := b.PreviousOutputValue()
+	if !ok || !strings.EqualFold(hex.EncodeToString(prev[:]), rec.PreviousOutputValue()) {
+		t.Errorf("unexpected previous output value %x", prev)
+	}
+	if b.PreviousOutputInt() == nil {
+		t.Error("expected the previous output value as an integer")
+	}
+	first := fixtureRecord(t)
+	first.Pulse.ListValues = nil
+	if b, err := first.BinaryPulse(); err != nil || b.PreviousOutputInt() != nil {
+		t.Errorf("expected the first pulse of a chain to lack a previous output value: %v", err)
+	}
+
+	rec.Pulse.SignatureValue = rec.Pulse.SignatureValue[1:]
+	if _, err := rec.BinaryPulse(); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("expected the odd signature to be reported, got %v", err)
+	}
+}
+
+func TestBinaryPulseRoundTrip(t *testing.T) {
+	key, cert, _ := testCertificate(t)
+	want := fixtureRecord(t)
+	signRecord(t, key, &want)
+
+	b, err := want.BinaryPulse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := b.Record()
+	if !rec.Equal(want) {
+		t.Error("expected the record to survive the round trip")
+	}
+	signed, err := rec.SignedBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig, _ := want.SignedBytes(); !bytes.Equal(signed, orig) {
+		t.Error("the round trip changed the signed bytes")
+	}
+	if err := Verify(rec, cert); err != nil {
+		t.Errorf("expected the converted record to verify: %v", err)
+	}
+	again, err := rec.BinaryPulse()
+	if err != nil || !reflect.DeepEqual(again, b) {
+		t.Errorf("expected the converted record to decode identically: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package beacon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"slices"
+)
+
+// DecodeResponse decodes the JSON body of a response into v the way a client does: the body must be labelled as JSON, it is
+// decompressed, and no more than limit bytes of it are read, DefaultMaxResponseSize if limit isn't positive. Failures are reported as *Error.
+// It is meant for the adapters of other beacons, so that they handle responses like the client does.
+func DecodeResponse(r *http.Response, url string, limit int64, v any) error {
+	return readBody(r, url, limit, jsonDecoder(v))
+}
+
+// bulkKeys are the keys of the objects wrapping lists of pulses in bulk responses and dump files
+var bulkKeys = []string{"pulses", "skipList"}
+
+// recordReader bounds how much a decoder reads ahead of the last value it decoded, so that a single oversized value can't exhaust the
+// memory while any number of values can be streamed
+type recordReader struct {
+	r     io.Reader
+	dec   *json.Decoder
+	read  int64
+	limit int64
+}
+
+func (r *recordReader) Read(p []byte) (int, error) {
+	if r.read-r.dec.InputOffset() > r.limit {
+		return 0, errTooLarge
+	}
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	return n, err
+}
+
+// DecodeRecords streams the records of a bulk response or dump file without loading it in memory: a JSON array of records, an object
+// listing them under "pulses" or "skipList", or records one after the other as in JSON Lines. The elements of the lists may be records
+// or bare pulses. A record larger than DefaultMaxResponseSize is rejected. The records aren't validated, see Record.Validate.
+// The iteration stops after yielding an error, identifying the faulty record.
+func DecodeRecords(r io.Reader) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		rr := &recordReader{r: r, limit: DefaultMaxResponseSize}
+		dec := json.NewDecoder(rr)
+		rr.dec = dec
+		n := 0
+		fail := func(err error) {
+			if errors.Is(err, errTooLarge) {
+				err = fmt.Errorf("the record exceeds %d bytes", rr.limit)
+			}
+			yield(Record{}, fmt.Errorf("Record %d: %w", n+1, err))
+		}
+
+		// list streams the elements of an array whose opening bracket was consumed
+		list := func() bool {
+			for dec.More() {
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					fail(err)
+					return false
+				}
+				rec, err := decodeElement(raw)
+				if err != nil {
+					fail(err)
+					return false
+				}
+				n++
+				if !yield(rec, nil) {
+					return false
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				fail(err)
+				return false
+			}
+			return true
+		}
+
+		// object streams a record or the lists of a wrapper object whose opening brace was consumed
+		object := func() bool {
+			var rec Record
+			isRecord := false
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					fail(err)
+					return false
+				}
+				key, _ := tok.(string)
+				switch {
+				case key == "pulse":
+					isRecord = true
+					err = dec.Decode(&rec.Pulse)
+				case slices.Contains(bulkKeys, key):
+					if tok, err = dec.Token(); err == nil && tok != json.Delim('[') {
+						err = fmt.Errorf("expected a list of pulses under %q", key)
+					}
+					if err == nil {
+						if !list() {
+							return false
+						}
+						continue
+					}
+				default:
+					var skip json.RawMessage
+					err = dec.Decode(&skip)
+				}
+				if err != nil {
+					fail(err)
+					return false
+				}
+			}
+			if _, err := dec.Token(); err != nil {
+				fail(err)
+				return false
+			}
+			if isRecord {
+				n++
+				return yield(rec, nil)
+			}
+			return true
+		}
+
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				fail(err)
+				return
+			}
+			ok := false
+			switch tok {
+			case json.Delim('['):
+				ok = list()
+			case json.Delim('{'):
+				ok = object()
+			default:
+				fail(fmt.Errorf("unexpected %v", tok))
+			}
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// decodeElement decodes an element of a list of records, which may be a record or a bare pulse
+func decodeElement(raw json.RawMessage) (Record, error) {
+	var rec Record
+	var probe struct {
+		Pulse json.RawMessage `json:"pulse"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return rec, err
+	}
+	if probe.Pulse != nil {
+		raw = probe.Pulse
+	}
+	err := json.Unmarshal(raw, &rec.Pulse)
+	return rec, err
+}
@@ -0,0 +1,109 @@
+package beacon
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRecords(t *testing.T) {
+	recs := fixtureChain(t, 3)
+	var docs, pulses []string
+	for _, rec := range recs {
+		buf, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, string(buf))
+		buf, err = json.Marshal(rec.Pulse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pulses = append(pulses, string(buf))
+	}
+
+	for name, dump := range map[string]string{
+		"array":     "[" + strings.Join(docs, ",") + "]",
+		"wrapped":   `{"version": "2.0", "pulses": [` + strings.Join(pulses, ",") + "]}",
+		"skiplist":  `{"skipList": [` + strings.Join(docs, ",") + "]}",
+		"jsonl":     strings.Join(docs, "\n") + "\n",
+		"mixed":     docs[0] + "\n[" + pulses[1] + "]\n" + docs[2],
+		"no spaces": strings.Join(docs, ""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			i := 0
+			for rec, err := range DecodeRecords(strings.NewReader(dump)) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i >= len(recs) || !rec.Equal(recs[i]) {
+					t.Fatalf("record %d wasn't decoded", i+1)
+				}
+				i++
+			}
+			if i != len(recs) {
+				t.Errorf("decoded %d records instead of %d", i, len(recs))
+			}
+		})
+	}
+
+	for name, dump := range map[string]string{
+		"truncated":  "[" + docs[0] + "," + docs[1][:40],
+		"not a list": `{"pulses": 3}`,
+		"scalar":     "42",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var err error
+			for _, err = range DecodeRecords(strings.NewReader(dump)) {
+				if err != nil {
+					break
+				}
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), "Record ") {
+				t.Errorf("expected the faulty record to be reported, got %v", err)
+			}
+		})
+	}
+}
+
+// endlessDump serves an array of records that never ends
+type endlessDump struct {
+	doc  []byte
+	left []byte
+}
+
+func (d *endlessDump) Read(p []byte) (int, error) {
+	if len(d.left) == 0 {
+		d.left = d.doc
+	}
+	n := copy(p, d.left)
+	d.left = d.left[n:]
+	return n, nil
+}
+
+func TestDecodeRecordsStreams(t *testing.T) {
+	buf, err := json.Marshal(fixtureRecord(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := io.MultiReader(strings.NewReader("["), &endlessDump{doc: append(buf, ',')})
+	n := 0
+	for _, err := range DecodeRecords(dump) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 1000 {
+			break
+		}
+	}
+
+	// a single value can't grow without bound
+	huge := io.MultiReader(strings.NewReader(`[{"pulse": {"uri": "`), &endlessDump{doc: []byte("a")})
+	for _, err = range DecodeRecords(huge) {
+		break
+	}
+	if err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected an oversized record to be rejected, got %v", err)
+	}
+}
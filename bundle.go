@@ -0,0 +1,83 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// bundleVersion is the version of the proof bundle layout
+const bundleVersion = 1
+
+// Bundle is a self-contained proof that a record was emitted by the beacon: along with the record it carries the exact bytes covered by
+// the signature and the signing certificate, so that auditors can check it with any RSA implementation. It marshals to JSON; SignedBytes
+// and SignatureValue are hex encoded, the certificate is PEM encoded.
+type Bundle struct {
+	Version        int    `json:"version"`
+	Algorithm      string `json:"algorithm"`
+	Record         Record `json:"record"`
+	SignedBytes    string `json:"signedBytes"`
+	SignatureValue string `json:"signatureValue"`
+	Certificate    string `json:"certificate"`
+}
+
+// ProofBundle bundles the record with its signed bytes and cert, the certificate that signed it
+func (rec *Record) ProofBundle(cert *x509.Certificate) (*Bundle, error) {
+	signed, err := rec.SignedBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{
+		Version:        bundleVersion,
+		Algorithm:      "RSASSA-PKCS1-v1_5 SHA-512",
+		Record:         *rec,
+		SignedBytes:    strings.ToUpper(hex.EncodeToString(signed)),
+		SignatureValue: rec.Pulse.SignatureValue,
+		Certificate:    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+	}, nil
+}
+
+// ProofBundle bundles rec with the certificate that signed it, after verifying it
+func (c *Client) ProofBundle(ctx context.Context, rec Record) (*Bundle, error) {
+	cert, err := c.certificate(ctx, rec)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(rec, cert); err != nil {
+		return nil, err
+	}
+	return rec.ProofBundle(cert)
+}
+
+// VerifyBundle checks that the bundle is consistent and that its record is correctly signed by the bundled certificate.
+// It doesn't establish that the certificate belongs to the beacon: auditors must compare it with one obtained out-of-band.
+func VerifyBundle(b *Bundle) error {
+	if b.Version != bundleVersion {
+		return errors.New(fmt.Sprintf("Unsupported bundle version: %d", b.Version))
+	}
+	cert, err := ParseCertificatePEM([]byte(b.Certificate))
+	if err != nil {
+		return fmt.Errorf("Couldn't parse the bundle's certificate: %w", err)
+	}
+
+	signed, err := b.Record.SignedBytes()
+	if err != nil {
+		return err
+	}
+	raw, err := hex.DecodeString(b.SignedBytes)
+	if err != nil {
+		return fmt.Errorf("Couldn't decode the bundle's signed bytes: %w", err)
+	}
+	if !bytes.Equal(signed, raw) {
+		return errors.New("The bundle's signed bytes don't match its record")
+	}
+	if !strings.EqualFold(b.SignatureValue, b.Record.Pulse.SignatureValue) {
+		return errors.New("The bundle's signature doesn't match its record")
+	}
+	return Verify(b.Record, cert)
+}
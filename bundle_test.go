@@ -0,0 +1,52 @@
+package beacon_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestProofBundle(t *testing.T) {
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	rec, err := c.LastRecord(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.ProofBundle(ctx, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded beacon.Bundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := beacon.VerifyBundle(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := decoded
+	tampered.Record.Pulse.LocalRandomValue = tampered.Record.Pulse.PrecommitmentValue
+	if err := beacon.VerifyBundle(&tampered); err == nil {
+		t.Error("expected a tampered record to fail")
+	}
+
+	rec.Pulse.SignatureValue = "00" + rec.Pulse.SignatureValue[2:]
+	if b, err := rec.ProofBundle(srv.Certificate); err != nil {
+		t.Fatal(err)
+	} else if err := beacon.VerifyBundle(b); !errors.Is(err, beacon.ErrSignatureInvalid) {
+		t.Errorf("expected ErrSignatureInvalid for a forged signature, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package beacon
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache stores records keyed by their pulse timestamp. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the record whose pulse timestamp is t, if it is cached
+	Get(t time.Time) (Record, bool)
+	// Put caches rec under its pulse timestamp t
+	Put(t time.Time, rec Record)
+}
+
+// CoordinatedCache is implemented by the caches whose misses may block, such as caches shared by replicas that wait for the one
+// fetching a missing record. The client passes them the context of its request, and releases the misses it failed to fetch.
+type CoordinatedCache interface {
+	Cache
+	// GetContext returns the record whose pulse timestamp is t as Get does, waiting no longer than ctx allows
+	GetContext(ctx context.Context, t time.Time) (Record, bool)
+	// Release tells the cache the record it missed under t couldn't be fetched, so that nobody waits for it any longer
+	Release(t time.Time)
+}
+
+type memoryEntry struct {
+	rec     Record
+	expires time.Time
+}
+
+// MemoryCache is an in-memory Cache keeping each record until its pulse interval has elapsed
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[int64]memoryEntry
+	swept   time.Time
+}
+
+// NewMemoryCache returns an empty in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[int64]memoryEntry)}
+}
+
+// Get returns the record whose pulse timestamp is t, if it is cached and hasn't expired
+func (m *MemoryCache) Get(t time.Time) (Record, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[t.Unix()]
+	if !ok {
+		return Record{}, false
+	}
+	if time.Now().After(e.expires) {
+		delete(m.entries, t.Unix())
+		return Record{}, false
+	}
+	return e.rec, true
+}
+
+// Put caches rec under t for one pulse period
+func (m *MemoryCache) Put(t time.Time, rec Record) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	period := recordPeriod(rec)
+	if now.Sub(m.swept) > period {
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			}
+		}
+		m.swept = now
+	}
+	m.entries[t.Unix()] = memoryEntry{rec: rec, expires: now.Add(period)}
+}
+
+// DefaultLRUSize is how many records an LRU cache created with a non-positive size holds, about a week of pulses
+const DefaultLRUSize = 10000
+
+type lruEntry struct {
+	key int64
+	rec Record
+	// expires is zero for historical records, which never change
+	expires time.Time
+}
+
+// LRUCache is an in-memory Cache holding a bounded number of records, evicting the least recently used one when full.
+// Historical records are immutable and are kept until evicted, while the latest record expires once its pulse period has elapsed.
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[int64]*list.Element
+}
+
+// NewLRUCache returns an empty LRU cache holding up to size records, DefaultLRUSize if size isn't positive
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		size = DefaultLRUSize
+	}
+	return &LRUCache{size: size, order: list.New(), entries: make(map[int64]*list.Element)}
+}
+
+// Get returns the record whose pulse timestamp is t, if it is cached and hasn't expired
+func (l *LRUCache) Get(t time.Time) (Record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.entries[t.Unix()]
+	if !ok {
+		return Record{}, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		l.order.Remove(el)
+		delete(l.entries, e.key)
+		return Record{}, false
+	}
+	l.order.MoveToFront(el)
+	return e.rec, true
+}
+
+// Put caches rec under t, for one pulse period if it is the latest record and until evicted otherwise
+func (l *LRUCache) Put(t time.Time, rec Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := &lruEntry{key: t.Unix(), rec: rec}
+	if now := time.Now(); now.Before(rec.NextPulseTime()) {
+		e.expires = now.Add(recordPeriod(rec))
+	}
+	if el, ok := l.entries[e.key]; ok {
+		el.Value = e
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[e.key] = l.order.PushFront(e)
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns how many records are cached, including expired ones not yet evicted
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,137 @@
+package beacon
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientCache(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write(buf)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithCache(NewMemoryCache()))
+	ts := time.Unix(1577836800, 0)
+	for i := 0; i < 3; i++ {
+		if _, err := c.CurrentRecord(context.Background(), ts.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.NextRecord(context.Background(), ts.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 1 {
+		t.Errorf("expected a single request, got %d", hits)
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.Period = 1
+
+	m := NewMemoryCache()
+	m.Put(rec.Pulse.TimeStamp, rec)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := m.Get(rec.Pulse.TimeStamp); ok {
+		t.Error("expected the record to expire after its pulse period")
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	rec := fixtureRecord(t)
+	l := NewLRUCache(2)
+	ts := rec.Pulse.TimeStamp
+	for i := range 3 {
+		r := rec
+		r.Pulse.TimeStamp = ts.Add(time.Duration(i) * time.Minute)
+		l.Put(r.Pulse.TimeStamp, r)
+		if i == 0 {
+			// using the oldest record makes the second one the least recently used
+			continue
+		}
+		if _, ok := l.Get(ts); !ok {
+			t.Fatal("expected the first record to be kept")
+		}
+	}
+	if l.Len() != 2 {
+		t.Errorf("expected 2 records, got %d", l.Len())
+	}
+	if _, ok := l.Get(ts.Add(time.Minute)); ok {
+		t.Error("expected the least recently used record to be evicted")
+	}
+	if _, ok := l.Get(ts.Add(2 * time.Minute)); !ok {
+		t.Error("expected the last record to be kept")
+	}
+
+	// historical records never expire, the latest one does after its period
+	latest := rec
+	latest.Pulse.Period = 1
+	latest.Pulse.TimeStamp = time.Now()
+	l.Put(latest.Pulse.TimeStamp, latest)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := l.Get(latest.Pulse.TimeStamp); ok {
+		t.Error("expected the latest record to expire after its pulse period")
+	}
+	if _, ok := l.Get(ts.Add(2 * time.Minute)); !ok {
+		t.Error("expected the historical record to be kept")
+	}
+}
+
+func TestClientCachePeriod(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	// a beacon pulsing every 10 seconds
+	cache := NewLRUCache(0)
+	ts := time.Unix(1577836800, 0)
+	for i := 0; i < 2; i++ {
+		rec := fixtureRecord(t)
+		rec.Pulse.Period = 10000
+		rec.Pulse.PulseIndex += i
+		rec.Pulse.TimeStamp = ts.Add(time.Duration(i) * 10 * time.Second)
+		cache.Put(rec.Pulse.TimeStamp, rec)
+	}
+
+	rec, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithCache(cache)).CurrentRecord(context.Background(), ts.Add(15*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex != 1001 {
+		t.Errorf("expected the pulse at %s, got pulse %d", ts.Add(10*time.Second), rec.Pulse.PulseIndex)
+	}
+
+	cache = NewLRUCache(0)
+	rec.Pulse.TimeStamp = ts.Add(20 * time.Second)
+	cache.Put(rec.Pulse.TimeStamp, rec)
+	if _, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithCache(cache), WithPeriod(10*time.Second)).CurrentRecord(context.Background(), ts.Add(25*time.Second)); err != nil {
+		t.Errorf("expected the declared period to find the cached record, got %v", err)
+	}
+}
+
+func TestRetryInterval(t *testing.T) {
+	rec := fixtureRecord(t)
+	for period, want := range map[int]time.Duration{
+		60000:   5 * time.Second,
+		6000:    500 * time.Millisecond,
+		3600000: DefaultRetryInterval,
+	} {
+		rec.Pulse.Period = period
+		if got := rec.RetryInterval(); got != want {
+			t.Errorf("period %dms: expected to retry every %s, got %s", period, want, got)
+		}
+	}
+}
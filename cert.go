@@ -0,0 +1,230 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha512"
+	"crypto/x509"
+	"embed"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+//go:embed certs
+var certsDir embed.FS
+
+// embeddedCerts holds the certificates embedded in the package, a variable so tests can embed others
+var embeddedCerts fs.FS = certsDir
+
+// ParseCertificatePEM parses the first certificate of a PEM encoded buffer
+func ParseCertificatePEM(buf []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, buf = pem.Decode(buf)
+		if block == nil {
+			return nil, errors.New("Couldn't find a PEM encoded certificate")
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// matchesID reports whether cert is the certificate with the given id. Beacon 2.0 ids are the SHA-512 hash of the certificate,
+// other ids are opaque and match any certificate.
+func matchesID(cert *x509.Certificate, id string) bool {
+	if len(id) != hexValueSize {
+		return true
+	}
+	sum := sha512.Sum512(cert.Raw)
+	return strings.EqualFold(hex.EncodeToString(sum[:]), id)
+}
+
+// Certificate fetches the signing certificate with the given id from the beacon. A certificate that doesn't hash to its id is rejected
+// as malformed, so that whichever beacon or mirror serves it, records are only verified against the certificate they name.
+func (c *Client) Certificate(ctx context.Context, id string) (*x509.Certificate, error) {
+	url := c.url("/certificate/" + id)
+	var cert *x509.Certificate
+	intermediates := x509.NewCertPool()
+	err := c.get(ctx, url, decoder{certificateMediaTypes, func(r io.Reader) error {
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		cert, err = ParseCertificatePEM(buf)
+		if err == nil && !matchesID(cert, id) {
+			err = errors.New("The certificate doesn't hash to its id " + id)
+		}
+		if err == nil && c.roots != nil {
+			// the certificates following the first one are its issuers
+			intermediates.AppendCertsFromPEM(buf)
+		}
+		return err
+	}})
+	if err == nil && c.roots != nil {
+		if _, verr := cert.Verify(x509.VerifyOptions{
+			Roots:         c.roots,
+			Intermediates: intermediates,
+			// the certificate was valid when it was issued, records signed since are checked against its era by registries
+			CurrentTime: cert.NotBefore,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); verr != nil {
+			return nil, &Error{Kind: ErrSignatureInvalid, URL: url, Err: fmt.Errorf("The certificate %s isn't trusted: %w", id, verr)}
+		}
+	}
+	if errors.Is(err, ErrMalformedResponse) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get the certificate from the API: %w", err)
+	}
+	return cert, nil
+}
+
+// CertificateManager keeps track of the beacon's signing certificates. Certificates are looked up by the certificateId of the records they signed,
+// first in its cache, then on the beacon, and finally in the set of certificates embedded in the package so archived records can be verified offline.
+// It is safe for concurrent use.
+type CertificateManager struct {
+	client *Client
+
+	mu    sync.RWMutex
+	certs map[string]*x509.Certificate
+}
+
+// NewCertificateManager returns a manager fetching unknown certificates with c. If c is nil, only cached and embedded certificates are used.
+func NewCertificateManager(c *Client) *CertificateManager {
+	return &CertificateManager{
+		client: c,
+		certs:  make(map[string]*x509.Certificate),
+	}
+}
+
+// Add caches cert as the certificate with the given id
+func (m *CertificateManager) Add(id string, cert *x509.Certificate) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.certs[strings.ToLower(id)] = cert
+}
+
+// Certificate returns the certificate with the given id
+func (m *CertificateManager) Certificate(ctx context.Context, id string) (*x509.Certificate, error) {
+	id = strings.ToLower(id)
+
+	m.mu.RLock()
+	cert, ok := m.certs[id]
+	m.mu.RUnlock()
+	if ok {
+		return cert, nil
+	}
+
+	var fetchErr error
+	if m.client != nil {
+		cert, fetchErr = m.client.Certificate(ctx, id)
+		if fetchErr == nil {
+			m.Add(id, cert)
+			return cert, nil
+		}
+	}
+
+	cert, err := embeddedCertificate(id)
+	if err != nil {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return nil, err
+	}
+	m.Add(id, cert)
+	return cert, nil
+}
+
+// ForRecord returns the certificate that signed rec
+func (m *CertificateManager) ForRecord(ctx context.Context, rec Record) (*x509.Certificate, error) {
+	return m.Certificate(ctx, rec.Pulse.CertificateID)
+}
+
+func embeddedCertificate(id string) (*x509.Certificate, error) {
+	buf, err := fs.ReadFile(embeddedCerts, path.Join("certs", id+".pem"))
+	if err != nil {
+		return nil, &Error{Kind: ErrNotFound, Err: errors.New("Unknown certificate: " + id)}
+	}
+	cert, err := ParseCertificatePEM(buf)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse the embedded certificate %s: %w", id, err)
+	}
+	if !matchesID(cert, id) {
+		return nil, errors.New("The embedded certificate " + id + " doesn't hash to its id")
+	}
+	return cert, nil
+}
+
+// WithCertificate makes the client verify every record against cert, whatever its certificate id, instead of fetching certificates
+func WithCertificate(cert *x509.Certificate) Option {
+	return func(c *Client) {
+		c.pinned = cert
+	}
+}
+
+// WithCertPool makes the client verify records only against certs, keyed by certificate id, instead of fetching certificates.
+// This allows audits relying solely on certificates obtained out-of-band, covering as many eras as needed.
+func WithCertPool(certs map[string]*x509.Certificate) Option {
+	return func(c *Client) {
+		c.pool = make(map[string]*x509.Certificate, len(certs))
+		for id, cert := range certs {
+			c.pool[strings.ToLower(id)] = cert
+		}
+	}
+}
+
+// WithCertificateRoots makes the client reject the fetched certificates that aren't issued under roots, directly or through the
+// intermediate certificates served after them. Beacons whose signing certificates come from a public key infrastructure, such as the
+// ICP-Brasil certificates of the Inmetro beacon, can so be trusted without pinning each of their certificates.
+func WithCertificateRoots(roots *x509.CertPool) Option {
+	return func(c *Client) {
+		c.roots = roots
+	}
+}
+
+// certificate returns the certificate rec must be verified against
+func (c *Client) certificate(ctx context.Context, rec Record) (*x509.Certificate, error) {
+	switch {
+	case c.pinned != nil:
+		return c.pinned, nil
+	case c.registry != nil:
+		return c.registry.ForRecord(rec)
+	case c.pool != nil:
+		cert, ok := c.pool[strings.ToLower(rec.Pulse.CertificateID)]
+		if !ok {
+			return nil, &Error{Kind: ErrNotFound, Err: errors.New("Certificate not in the pool: " + rec.Pulse.CertificateID)}
+		}
+		return cert, nil
+	default:
+		return c.certs.ForRecord(ctx, rec)
+	}
+}
+
+// Verify checks rec against the certificate that signed it: the one configured with WithCertificate or WithCertPool,
+// or else the one fetched and cached by the client's certificate manager
+func (c *Client) Verify(ctx context.Context, rec Record) error {
+	err := c.verify(ctx, rec)
+	if err != nil {
+		c.log.Error("Record failed verification", pulseAttrs(rec), "err", err)
+	}
+	if c.metrics != nil {
+		c.metrics.ObserveVerification(err)
+	}
+	return err
+}
+
+func (c *Client) verify(ctx context.Context, rec Record) error {
+	cert, err := c.certificate(ctx, rec)
+	if err != nil {
+		return err
+	}
+	return Verify(rec, cert)
+}
@@ -0,0 +1,238 @@
+package beacon
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func testCertificate(t *testing.T) (*rsa.PrivateKey, *x509.Certificate, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "beacon test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertificateManager(t *testing.T) {
+	_, cert, buf := testCertificate(t)
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path != "/certificate/abcd" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(buf)
+	}))
+	defer srv.Close()
+
+	m := NewCertificateManager(NewClient(WithBaseURL(srv.URL)))
+	for i := 0; i < 3; i++ {
+		got, err := m.Certificate(context.Background(), "ABCD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(cert) {
+			t.Error("got a different certificate")
+		}
+	}
+	if hits != 1 {
+		t.Errorf("expected the certificate to be fetched once, got %d requests", hits)
+	}
+
+	if _, err := m.Certificate(context.Background(), "ef01"); err == nil {
+		t.Error("expected an unknown certificate to fail")
+	}
+}
+
+func TestClientVerifyWithCertificate(t *testing.T) {
+	key, cert, _ := testCertificate(t)
+	_, other, _ := testCertificate(t)
+	rec := fixtureRecord(t)
+	signRecord(t, key, &rec)
+	ctx := context.Background()
+
+	if err := NewClient(WithCertificate(cert)).Verify(ctx, rec); err != nil {
+		t.Error(err)
+	}
+	if err := NewClient(WithCertificate(other)).Verify(ctx, rec); err == nil {
+		t.Error("expected verification against another certificate to fail")
+	}
+
+	pool := map[string]*x509.Certificate{rec.Pulse.CertificateID: cert}
+	if err := NewClient(WithCertPool(pool)).Verify(ctx, rec); err != nil {
+		t.Error(err)
+	}
+	if err := NewClient(WithCertPool(map[string]*x509.Certificate{"ab": cert})).Verify(ctx, rec); err == nil {
+		t.Error("expected a certificate missing from the pool to fail")
+	}
+}
+
+func TestCertificateID(t *testing.T) {
+	_, cert, buf := testCertificate(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf)
+	}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL))
+
+	sum := sha512.Sum512(cert.Raw)
+	if _, err := c.Certificate(context.Background(), strings.ToUpper(hex.EncodeToString(sum[:]))); err != nil {
+		t.Errorf("the certificate wasn't accepted under its id: %v", err)
+	}
+	sum[0]++
+	if _, err := c.Certificate(context.Background(), hex.EncodeToString(sum[:])); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("expected a certificate served under another id to be rejected, got %v", err)
+	}
+}
+
+// issueCertificate returns a certificate for a new key, issued by parent or self-signed if parent is nil
+func issueCertificate(t *testing.T, parent *x509.Certificate, parentKey *rsa.PrivateKey, ca bool) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "beacon test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  ca,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+func TestWithCertificateRoots(t *testing.T) {
+	rootKey, root := issueCertificate(t, nil, nil, true)
+	interKey, inter := issueCertificate(t, root, rootKey, true)
+	_, leaf := issueCertificate(t, inter, interKey, false)
+	_, self, selfPEM := testCertificate(t)
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	encode := func(certs ...*x509.Certificate) []byte {
+		var buf []byte
+		for _, cert := range certs {
+			buf = append(buf, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+		return buf
+	}
+	id := func(cert *x509.Certificate) string {
+		sum := sha512.Sum512(cert.Raw)
+		return hex.EncodeToString(sum[:])
+	}
+
+	for name, tc := range map[string]struct {
+		served  []byte
+		id      string
+		trusted bool
+	}{
+		"chain":                {encode(leaf, inter), id(leaf), true},
+		"missing intermediate": {encode(leaf), id(leaf), false},
+		"self-signed":          {selfPEM, id(self), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tc.served)
+			}))
+			defer srv.Close()
+
+			_, err := NewClient(WithBaseURL(srv.URL), WithCertificateRoots(roots)).Certificate(context.Background(), tc.id)
+			if tc.trusted && err != nil {
+				t.Errorf("expected the certificate to be trusted, got %v", err)
+			}
+			if !tc.trusted && !errors.Is(err, ErrSignatureInvalid) {
+				t.Errorf("expected the certificate to be rejected, got %v", err)
+			}
+			if _, err := NewClient(WithBaseURL(srv.URL)).Certificate(context.Background(), tc.id); err != nil {
+				t.Errorf("expected the certificate to be accepted without roots, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEmbeddedCertificates(t *testing.T) {
+	// the certificates shipped with the package must hash to the ids they are filed under
+	pems, err := fs.Glob(certsDir, "certs/*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range pems {
+		id := strings.TrimSuffix(path.Base(name), ".pem")
+		if _, err := embeddedCertificate(id); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	key, cert, buf := testCertificate(t)
+	_, other, otherBuf := testCertificate(t)
+	sum := sha512.Sum512(cert.Raw)
+	id := hex.EncodeToString(sum[:])
+	otherSum := sha512.Sum512(other.Raw)
+	forged := hex.EncodeToString(otherSum[:])
+	forged = forged[:len(forged)-1] + "0"
+	defer func(fsys fs.FS) { embeddedCerts = fsys }(embeddedCerts)
+	embeddedCerts = fstest.MapFS{
+		"certs/" + id + ".pem":     {Data: buf},
+		"certs/" + forged + ".pem": {Data: otherBuf},
+	}
+
+	// a pulse of a past era is verified offline against the embedded certificate of its id
+	rec := fixtureRecord(t)
+	rec.Pulse.CertificateID = strings.ToUpper(id)
+	rec.Pulse.TimeStamp = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	signRecord(t, key, &rec)
+	c := NewClient(WithBaseURL("http://127.0.0.1:0"), WithRetry(RetryPolicy{MaxAttempts: 1}))
+	if err := c.Verify(context.Background(), rec); err != nil {
+		t.Errorf("couldn't verify the pulse through the embedded certificate: %v", err)
+	}
+
+	if _, err := embeddedCertificate(forged); err == nil {
+		t.Error("expected an embedded certificate that doesn't hash to its id to be rejected")
+	}
+}
@@ -0,0 +1,74 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LinkError reports a broken link in a chain of records
+type LinkError struct {
+	// Index of the record in the chain whose link to its predecessor is broken
+	Index int
+	Err   error
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("Chain broken at record %d: %s", e.Index, e.Err)
+}
+
+func (e *LinkError) Unwrap() error {
+	return e.Err
+}
+
+// ListValue returns the record's list entry of the given type ("previous", "hour", "day", "month" or "year"), which references
+// the output value of an earlier pulse by its uri
+func (rec *Record) ListValue(typ string) (ListValue, bool) {
+	for _, v := range rec.Pulse.ListValues {
+		if v.Type == typ {
+			return v, true
+		}
+	}
+	return ListValue{}, false
+}
+
+// listValue returns the value of the record's list entry of the given type, or an empty string if it has none
+func (rec *Record) listValue(typ string) string {
+	v, _ := rec.ListValue(typ)
+	return v.Value
+}
+
+// PreviousOutputValue returns the output value of the previous pulse as referenced by the record
+func (rec *Record) PreviousOutputValue() string {
+	return rec.listValue("previous")
+}
+
+// VerifyLink checks that rec directly follows prev: its previous output value must match prev's output value and its timestamp must be one period after prev's,
+// or later if rec flags the gap an outage of the beacon left
+func (rec *Record) VerifyLink(prev Record) error {
+	if !strings.EqualFold(rec.PreviousOutputValue(), prev.Pulse.OutputValue) {
+		return errors.New("Previous output value doesn't match the previous record's output value")
+	}
+
+	period := time.Duration(prev.Pulse.Period) * time.Millisecond
+	want := prev.Pulse.TimeStamp.Add(period)
+	if rec.IsGap() && rec.Pulse.TimeStamp.After(want) {
+		return nil
+	}
+	if !rec.Pulse.TimeStamp.Equal(want) {
+		return errors.New(fmt.Sprintf("Timestamp doesn't advance by the period: expected=%s, got=%s", want.Format(time.RFC3339), rec.Pulse.TimeStamp.Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// VerifyChain checks every link of a chain of consecutive records, ordered from oldest to newest. The returned error is a *LinkError identifying the first broken link.
+func VerifyChain(recs []Record) error {
+	for i := 1; i < len(recs); i++ {
+		if err := recs[i].VerifyLink(recs[i-1]); err != nil {
+			return &LinkError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fixtureChain returns n linked records starting with the fixture record
+func fixtureChain(t *testing.T, n int) []Record {
+	recs := []Record{fixtureRecord(t)}
+	for i := 1; i < n; i++ {
+		prev := recs[i-1]
+		rec := fixtureRecord(t)
+		rec.Pulse.PulseIndex = prev.Pulse.PulseIndex + 1
+		rec.Pulse.TimeStamp = prev.Pulse.TimeStamp.Add(time.Minute)
+		rec.Pulse.OutputValue = prev.Pulse.OutputValue[1:] + prev.Pulse.OutputValue[:1]
+		for j := range rec.Pulse.ListValues {
+			if rec.Pulse.ListValues[j].Type == "previous" {
+				rec.Pulse.ListValues[j].Value = prev.Pulse.OutputValue
+			}
+		}
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestVerifyChain(t *testing.T) {
+	recs := fixtureChain(t, 5)
+	if err := VerifyChain(recs); err != nil {
+		t.Fatal(err)
+	}
+
+	recs[3].Pulse.TimeStamp = recs[3].Pulse.TimeStamp.Add(time.Second)
+	var lerr *LinkError
+	if err := VerifyChain(recs); !errors.As(err, &lerr) || lerr.Index != 3 {
+		t.Errorf("expected a broken link at 3, got %v", err)
+	}
+}
+
+func TestVerifyLinkGap(t *testing.T) {
+	recs := fixtureChain(t, 2)
+	recs[1].Pulse.TimeStamp = recs[1].Pulse.TimeStamp.Add(10 * time.Minute)
+	if err := recs[1].VerifyLink(recs[0]); err == nil {
+		t.Error("expected a late pulse not flagging a gap to be rejected")
+	}
+	recs[1].Pulse.StatusCode |= StatusGap
+	if err := recs[1].VerifyLink(recs[0]); err != nil {
+		t.Errorf("expected a pulse after an outage to link, got %v", err)
+	}
+	recs[1].Pulse.TimeStamp = recs[0].Pulse.TimeStamp
+	if err := recs[1].VerifyLink(recs[0]); err == nil {
+		t.Error("expected a pulse flagging a gap to still follow its predecessor")
+	}
+}
+
+func TestVerifyLinkOutput(t *testing.T) {
+	recs := fixtureChain(t, 2)
+	recs[0].Pulse.OutputValue = recs[1].Pulse.OutputValue
+	if err := recs[1].VerifyLink(recs[0]); err == nil {
+		t.Error("expected a mismatching previous output value to be rejected")
+	}
+}
+
+func TestListAndExternalValues(t *testing.T) {
+	rec := fixtureRecord(t)
+
+	hour, ok := rec.ListValue("hour")
+	if !ok || hour.URI != "https://beacon.nist.gov/beacon/2.0/chain/2/pulse/960" || len(hour.Value) != 128 {
+		t.Errorf("unexpected hour list value %+v", hour)
+	}
+	if _, ok := rec.ListValue("decade"); ok {
+		t.Error("found a list value of an unknown type")
+	}
+	if len(rec.Pulse.External.SourceID) != 128 || rec.Pulse.External.StatusCode != 0 {
+		t.Errorf("unexpected external value %+v", rec.Pulse.External)
+	}
+}
@@ -0,0 +1,536 @@
+package beacon
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBaseURL is the root of the NIST Randomness Beacon 2.0 REST API
+const DefaultBaseURL = "https://beacon.nist.gov/beacon/2.0"
+
+// DefaultStalePeriods is how many pulse periods old the last record may be before LastRecord reports the beacon as stale,
+// unless the client is configured with WithStaleness
+const DefaultStalePeriods = 2
+
+// DefaultUserAgent identifies the library in the requests of clients created without WithUserAgent
+const DefaultUserAgent = "go-nist-beacon"
+
+// Client fetches records from a beacon. It is safe for concurrent use, and several differently configured clients can be used side by side.
+type Client struct {
+	baseURL string
+	mirrors []string
+	// hedge is the delay after which record requests are raced against the next mirror, if not zero
+	hedge   time.Duration
+	http    *http.Client
+	timeout time.Duration
+	// staleness is negative when derived from the period of the last record
+	staleness time.Duration
+	cache     Cache
+	retry     RetryPolicy
+	limiter   *limiter
+	// paused holds off requests to the hosts that rate limited the client
+	paused    pauses
+	transport transportConfig
+	metrics   Metrics
+	certs     *CertificateManager
+	pinned    *x509.Certificate
+	// roots, if set, are the roots the fetched certificates must be issued under
+	roots     *x509.CertPool
+	pool      map[string]*x509.Certificate
+	registry  *CertificateRegistry
+	log       *slog.Logger
+	userAgent string
+	header    http.Header
+	// maxResponseSize bounds the body of responses, DefaultMaxResponseSize if zero
+	maxResponseSize int64
+	noCompression   bool
+	keepRaw         bool
+	lenient         bool
+	noVerify        bool
+	// flight coalesces concurrent requests for the same record
+	flight flight
+
+	// period of the last fetched record, in nanoseconds
+	period atomic.Int64
+	// ref is the last fetched record, from which the cache key of the pulses looked up by index is guessed
+	ref atomic.Pointer[Record]
+	// skew of the local clock relative to the Date header of the last response, in nanoseconds, valid once skewKnown is set
+	skew      atomic.Int64
+	skewKnown atomic.Bool
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL points the client at another beacon deployment, a mirror or a test server
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = url
+	}
+}
+
+// WithMirrors sets hosts serving the same API as the base URL, such as institutional mirrors or a proxy. When the beacon is unavailable,
+// requests fail over to the mirrors in the given order.
+func WithMirrors(urls ...string) Option {
+	return func(c *Client) {
+		c.mirrors = urls
+	}
+}
+
+// WithHedging races record requests across the beacon and its mirrors, for latency-sensitive callers: when no verified record arrived
+// delay after the last request was sent, or that request found its host unavailable, the same path is requested from the next mirror.
+// The first verified record wins and the other requests are cancelled, as they are when a host answers the pulse isn't found. It trades extra requests for hiding the tail latency of a slow host, and needs
+// WithMirrors.
+func WithHedging(delay time.Duration) Option {
+	return func(c *Client) {
+		c.hedge = delay
+	}
+}
+
+// WithHTTPClient makes the client use cli for all requests, it adds the possibility to use a proxy to fetch the data for example.
+// The transport options don't apply to cli.
+func WithHTTPClient(cli *http.Client) Option {
+	return func(c *Client) {
+		c.http = cli
+	}
+}
+
+// WithTimeout bounds every request made by the client, DefaultTimeout by default, each retry getting its own timeout.
+// A zero duration disables the timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithStaleness sets how old the last record may be before LastRecord reports the beacon as stale, instead of DefaultStalePeriods
+// pulse periods. A zero duration disables the check. Historical queries are never checked for staleness.
+func WithStaleness(d time.Duration) Option {
+	return func(c *Client) {
+		c.staleness = d
+	}
+}
+
+// WithoutStaleness disables the staleness check of LastRecord, which is useful when replaying an archive or a beacon that is known to be behind
+func WithoutStaleness() Option {
+	return WithStaleness(0)
+}
+
+// WithCache makes the client look records up in cache before fetching them, and store every fetched record in it
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request, DefaultUserAgent by default
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithHeader adds a header to every request, such as an API key or an identifier required by an egress proxy.
+// It can be given several times, including for the same key.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		c.header.Add(key, value)
+	}
+}
+
+// WithoutVerification makes the client return the records it fetches without verifying their signature and output value against
+// the beacon's certificate, for beacons whose certificates can't be obtained or callers verifying the records themselves
+func WithoutVerification() Option {
+	return func(c *Client) {
+		c.noVerify = true
+	}
+}
+
+// WithRawResponses makes the client retain the bytes of every record it fetches, available with Record.Raw
+func WithRawResponses() Option {
+	return func(c *Client) {
+		c.keepRaw = true
+	}
+}
+
+// NewClient returns a client for the NIST beacon configured with the given options
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:   DefaultBaseURL,
+		timeout:   DefaultTimeout,
+		staleness: -1,
+		transport: defaultTransport,
+		userAgent: DefaultUserAgent,
+		log:       slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.http == nil {
+		c.http = &http.Client{Transport: c.transport.build()}
+	}
+	c.certs = NewCertificateManager(c)
+	return c
+}
+
+// BaseURL returns the base URL of the beacon API the client queries
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *Client) url(path string) string {
+	return c.baseURL + path
+}
+
+// DefaultPeriod is the pulse interval assumed until a record with a period has been fetched, unless the client is configured with WithPeriod
+const DefaultPeriod = time.Minute
+
+// WithPeriod declares the pulse interval of a beacon that doesn't pulse every DefaultPeriod, for the requests made before the client
+// has fetched a record. The period of the fetched records takes precedence.
+func WithPeriod(d time.Duration) Option {
+	return func(c *Client) {
+		c.period.Store(int64(d))
+	}
+}
+
+func recordPeriod(rec Record) time.Duration {
+	if rec.Pulse.Period <= 0 {
+		return DefaultPeriod
+	}
+	return time.Duration(rec.Pulse.Period) * time.Millisecond
+}
+
+// pulsePeriod returns the pulse interval of the beacon, as last observed by the client
+func (c *Client) pulsePeriod() time.Duration {
+	if p := c.period.Load(); p > 0 {
+		return time.Duration(p)
+	}
+	return DefaultPeriod
+}
+
+// fetchRecord returns the cached record whose pulse timestamp is key(period) for the beacon's period, or fetches it from path
+func (c *Client) fetchRecord(ctx context.Context, key func(period time.Duration) time.Time, path string) (Record, error) {
+	// missed is the key the cache missed, released if the record can't be fetched
+	var missed time.Time
+	if c.cache != nil {
+		period := c.pulsePeriod()
+		rec, ok := c.cacheGet(ctx, key(period))
+		if ok && recordPeriod(rec) != period {
+			// the period was assumed, the cached record tells the actual one
+			period = recordPeriod(rec)
+			c.period.Store(int64(period))
+			rec, ok = c.cacheGet(ctx, key(period))
+		}
+		if c.metrics != nil {
+			c.metrics.ObserveCache(ok)
+		}
+		if ok {
+			return rec, nil
+		}
+		missed = key(period)
+	}
+
+	rec, err := c.GetRecord(ctx, c.url(path))
+	if err != nil {
+		if cc, ok := c.cache.(CoordinatedCache); ok && !missed.IsZero() {
+			cc.Release(missed)
+		}
+		return rec, err
+	}
+
+	c.stored(rec)
+	return rec, nil
+}
+
+// stored records rec as the last fetched record and caches it
+func (c *Client) stored(rec Record) {
+	c.period.Store(int64(recordPeriod(rec)))
+	c.ref.Store(&rec)
+	if c.cache != nil {
+		c.cache.Put(rec.Pulse.TimeStamp, rec)
+	}
+}
+
+// cacheGet looks t up in the client's cache, within ctx if the cache coordinates its misses
+func (c *Client) cacheGet(ctx context.Context, t time.Time) (Record, bool) {
+	if cc, ok := c.cache.(CoordinatedCache); ok {
+		return cc.GetContext(ctx, t)
+	}
+	return c.cache.Get(t)
+}
+
+// get fetches the body served at url and decodes it with d. If the beacon is unavailable, the same path is requested from each mirror in turn.
+func (c *Client) get(ctx context.Context, url string, d decoder) error {
+	err := c.getRetry(ctx, url, d)
+	if err == nil || len(c.mirrors) == 0 || !errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	path, ok := strings.CutPrefix(url, c.baseURL)
+	if !ok {
+		return err
+	}
+	for _, m := range c.mirrors {
+		c.log.Warn("Beacon unavailable, failing over to a mirror", "url", url, "mirror", m, "err", err)
+		if merr := c.getRetry(ctx, m+path, d); merr == nil || !errors.Is(merr, ErrUnavailable) {
+			return merr
+		}
+	}
+	return err
+}
+
+// getRetry fetches the body served at url, retrying according to the client's retry policy
+func (c *Client) getRetry(ctx context.Context, url string, d decoder) error {
+	for i := 0; ; i++ {
+		err := c.getOnce(ctx, url, d)
+		// an attempt timing out is retried, unless ctx itself is done
+		if err == nil || i+1 >= c.retry.MaxAttempts || ctx.Err() != nil || !c.retry.retryable(err) {
+			return err
+		}
+		delay := max(c.retry.Delay(i), retryAfter(err))
+		c.log.Warn("Retrying beacon request", "url", url, "attempt", i+1, "delay", delay, "err", err)
+		if err := Sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) getOnce(ctx context.Context, url string, d decoder) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("Couldn't build the API request: %w", err)
+	}
+	if err := c.paused.wait(ctx, req.URL.Host); err != nil {
+		return err
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if !c.noCompression && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	start := time.Now()
+	r, err := c.http.Do(req)
+	if c.metrics != nil {
+		status := 0
+		if err == nil {
+			status = r.StatusCode
+		}
+		c.metrics.ObserveRequest(c.endpoint(url), status, time.Since(start))
+	}
+	if err != nil {
+		return &Error{Kind: ErrUnavailable, URL: url, Err: err}
+	}
+	defer r.Body.Close()
+	c.observeDate(r.Header.Get("Date"))
+
+	if r.StatusCode != http.StatusOK {
+		e := statusError(r.StatusCode, url)
+		resp := NewResponseError(r, url)
+		policy := c.retry
+		if policy.MaxAttempts == 0 {
+			policy = DefaultRetryPolicy
+		}
+		resp.Retryable = policy.retryable(e)
+		e.Err = resp
+		pause, ok := parseRetryAfter(r.Header)
+		if ok {
+			e.RetryAfter = pause
+		}
+		// the whole client holds off, not only this request
+		if r.StatusCode == http.StatusTooManyRequests || ok {
+			if !ok {
+				pause = defaultRateLimitPause
+			}
+			c.log.Warn("Beacon asked to hold off requests", "url", url, "status", r.StatusCode, "pause", pause)
+			c.paused.pause(req.URL.Host, pause)
+		}
+		return e
+	}
+	return readBody(r, url, c.maxResponseSize, d)
+}
+
+// GetRecord fetches and decodes the record served at url. Concurrent calls for the same url share a single request to the beacon.
+func (c *Client) GetRecord(ctx context.Context, url string) (Record, error) {
+	return c.flight.do(ctx, url, func(ctx context.Context) (Record, error) {
+		return c.getRecord(ctx, url)
+	})
+}
+
+func (c *Client) getRecord(ctx context.Context, url string) (Record, error) {
+	var rec Record
+	var err error
+	if path, ok := strings.CutPrefix(url, c.baseURL); ok && c.hedge > 0 && len(c.mirrors) > 0 {
+		// the hedged records are verified as they arrive
+		rec, err = c.hedgeRecord(ctx, path)
+	} else {
+		err = c.get(ctx, url, c.recordDecoder(&rec))
+		if err == nil && !c.noVerify {
+			err = c.Verify(ctx, rec)
+		}
+	}
+	if errors.Is(err, ErrMalformedResponse) || errors.Is(err, ErrSignatureInvalid) {
+		return Record{}, err
+	}
+	if err != nil {
+		err = fmt.Errorf("Couldn't get the record from the API: %w", err)
+		return Record{}, err
+	}
+	return rec, nil
+}
+
+// recordDecoder returns a decoder of a record into rec, retaining its raw bytes and validating it as the client is configured to
+func (c *Client) recordDecoder(rec *Record) decoder {
+	d := jsonDecoder(rec)
+	if c.keepRaw {
+		d.decode = func(r io.Reader) error {
+			buf, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			*rec, err = ParseRecordLenient(buf)
+			return err
+		}
+	}
+	if !c.lenient {
+		decode := d.decode
+		d.decode = func(r io.Reader) error {
+			if err := decode(r); err != nil {
+				return err
+			}
+			return rec.Validate()
+		}
+	}
+	return d
+}
+
+// LastRecord fetches the latest record from the beacon and returns an ErrStale error along with it if it is older than the client's staleness threshold
+func (c *Client) LastRecord(ctx context.Context) (Record, error) {
+	now := time.Now()
+	rec, err := c.fetchRecord(ctx, func(period time.Duration) time.Time { return now.Truncate(period) }, "/pulse/last")
+	if err != nil {
+		return rec, err
+	}
+
+	staleness := c.staleness
+	if staleness < 0 {
+		staleness = DefaultStalePeriods * recordPeriod(rec)
+	}
+	if c.metrics != nil {
+		c.metrics.ObservePulse(rec)
+	}
+	if staleness > 0 && time.Since(rec.Pulse.TimeStamp) > staleness {
+		if c.metrics != nil {
+			c.metrics.ObserveStale(rec)
+		}
+		if skew, ok := c.ClockSkew(); ok && skew > time.Second {
+			// the beacon may well be on time
+			c.log.Warn("Beacon is stale, but the local clock is ahead of the beacon's", pulseAttrs(rec), "age", time.Since(rec.Pulse.TimeStamp), "threshold", staleness, "clock_skew", skew)
+			return rec, &Error{Kind: ErrStale, URL: c.url("/pulse/last"), Err: fmt.Errorf("current=%d, pulse=%d, local clock ahead by %s", time.Now().Unix(), rec.Pulse.TimeStamp.Unix(), skew)}
+		}
+		c.log.Warn("Beacon is stale", pulseAttrs(rec), "age", time.Since(rec.Pulse.TimeStamp), "threshold", staleness)
+		return rec, &Error{Kind: ErrStale, URL: c.url("/pulse/last"), Err: fmt.Errorf("current=%d, pulse=%d", time.Now().Unix(), rec.Pulse.TimeStamp.Unix())}
+	}
+
+	return rec, nil
+}
+
+// CurrentRecord fetches the record closest to the given timestamp
+func (c *Client) CurrentRecord(ctx context.Context, t time.Time) (Record, error) {
+	return c.fetchRecord(ctx, func(period time.Duration) time.Time { return t.Truncate(period) }, "/pulse/time/"+strconv.FormatInt(t.Unix(), 10))
+}
+
+// PreviousRecord fetches the record previous to the given timestamp
+func (c *Client) PreviousRecord(ctx context.Context, t time.Time) (Record, error) {
+	key := func(period time.Duration) time.Time {
+		if k := t.Truncate(period); !k.Equal(t) {
+			return k
+		}
+		return t.Add(-period)
+	}
+	return c.fetchRecord(ctx, key, "/pulse/time/previous/"+strconv.FormatInt(t.Unix(), 10))
+}
+
+// NextRecord fetches the record after the given timestamp
+func (c *Client) NextRecord(ctx context.Context, t time.Time) (Record, error) {
+	key := func(period time.Duration) time.Time { return t.Truncate(period).Add(period) }
+	return c.fetchRecord(ctx, key, "/pulse/time/next/"+strconv.FormatInt(t.Unix(), 10))
+}
+
+// ParseTimeParam parses a timestamp as the beacon API takes it in paths and queries, in seconds or milliseconds since the epoch.
+// It is meant for servers mirroring the API.
+func ParseTimeParam(v string) (time.Time, bool) {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if n > 1e11 {
+		return time.UnixMilli(n), true
+	}
+	return time.Unix(n, 0), true
+}
+
+// PulseByIndex fetches the record with the given chain and pulse index, the way audit documents reference pulses. The cache is keyed
+// by time, so the record is looked up at the time it would have if no pulse was skipped since the last fetched one of its chain.
+func (c *Client) PulseByIndex(ctx context.Context, chain, pulse uint64) (Record, error) {
+	var missed time.Time
+	if t, ok := c.indexTime(chain, pulse); ok && c.cache != nil {
+		rec, ok := c.cacheGet(ctx, t)
+		ok = ok && uint64(rec.Pulse.ChainIndex) == chain && uint64(rec.Pulse.PulseIndex) == pulse
+		if c.metrics != nil {
+			c.metrics.ObserveCache(ok)
+		}
+		if ok {
+			return rec, nil
+		}
+		missed = t
+	}
+
+	rec, err := c.GetRecord(ctx, c.url("/chain/"+strconv.FormatUint(chain, 10)+"/pulse/"+strconv.FormatUint(pulse, 10)))
+	if cc, ok := c.cache.(CoordinatedCache); ok && !missed.IsZero() && (err != nil || !rec.Pulse.TimeStamp.Equal(missed)) {
+		// the guessed key won't be filled
+		cc.Release(missed)
+	}
+	if err != nil {
+		return rec, err
+	}
+	c.stored(rec)
+	return rec, nil
+}
+
+// indexTime guesses the timestamp of the pulse with the given chain and pulse index from the last fetched record
+func (c *Client) indexTime(chain, pulse uint64) (time.Time, bool) {
+	ref := c.ref.Load()
+	if ref == nil || uint64(ref.Pulse.ChainIndex) != chain || ref.Pulse.TimeStamp.IsZero() {
+		return time.Time{}, false
+	}
+	return ref.Pulse.TimeStamp.Add(time.Duration(int64(pulse)-int64(ref.Pulse.PulseIndex)) * recordPeriod(*ref)), true
+}
@@ -0,0 +1,72 @@
+package beacon_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestPulseByIndex(t *testing.T) {
+	srv := beacontest.NewServer(beacontest.WithChainIndex(3))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	want, _ := srv.Record(7)
+	rec, err := c.PulseByIndex(ctx, 3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.ChainIndex != 3 || rec.Pulse.PulseIndex != 7 || rec.Pulse.OutputValue != want.Pulse.OutputValue {
+		t.Errorf("got pulse %d of chain %d", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex)
+	}
+
+	if _, err := c.PulseByIndex(ctx, 2, 7); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another chain, got %v", err)
+	}
+}
+
+// countingTransport counts the requests for pulses it sends
+type countingTransport struct {
+	next     http.RoundTripper
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !strings.Contains(req.URL.Path, "/certificate/") {
+		t.requests.Add(1)
+	}
+	return t.next.RoundTrip(req)
+}
+
+func TestPulseByIndexCache(t *testing.T) {
+	srv := beacontest.NewServer(beacontest.WithChainIndex(3))
+	defer srv.Close()
+	transport := &countingTransport{next: srv.HTTPClient().Transport}
+	c := srv.Client(beacon.WithHTTPClient(&http.Client{Transport: transport}), beacon.WithCache(beacon.NewMemoryCache()))
+	ctx := context.Background()
+
+	// the pulses of the records fetched by time or by index are served from the cache
+	recs := srv.Records()
+	if _, err := c.CurrentRecord(ctx, recs[2].Pulse.TimeStamp); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		for _, i := range []int{2, 5} {
+			want := recs[i].Pulse
+			rec, err := c.PulseByIndex(ctx, 3, uint64(want.PulseIndex))
+			if err != nil || rec.Pulse.OutputValue != want.OutputValue {
+				t.Fatalf("couldn't get pulse %d: %v", want.PulseIndex, err)
+			}
+		}
+	}
+	if n := transport.requests.Load(); n != 2 {
+		t.Errorf("expected the pulses looked up again to be cached, got %d requests", n)
+	}
+}
@@ -0,0 +1,224 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func fixtureServer(t *testing.T) *httptest.Server {
+	buf, err := ioutil.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buf)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientBaseURL(t *testing.T) {
+	srv := fixtureServer(t)
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithTimeout(5*time.Second))
+
+	rec, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex != 1000 || rec.Pulse.ChainIndex != 2 {
+		t.Errorf("unexpected indexes: chain=%d, pulse=%d", rec.Pulse.ChainIndex, rec.Pulse.PulseIndex)
+	}
+}
+
+func TestClientStaleness(t *testing.T) {
+	srv := fixtureServer(t)
+
+	_, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL)).LastRecord(context.Background())
+	if err == nil {
+		t.Error("expected the fixture record to be reported as stale")
+	}
+
+	_, err = NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithStaleness(0)).LastRecord(context.Background())
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientStalenessFromPeriod(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.Period = 3600000
+	rec.Pulse.TimeStamp = time.Now().Add(-90 * time.Minute)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(rec)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL)).LastRecord(context.Background()); err != nil {
+		t.Errorf("a record 1.5 periods old shouldn't be stale: %v", err)
+	}
+	if _, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithStaleness(time.Hour)).LastRecord(context.Background()); err == nil {
+		t.Error("expected the explicit threshold to apply")
+	}
+	if _, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithoutStaleness()).CurrentRecord(context.Background(), rec.Pulse.TimeStamp); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientMirrors(t *testing.T) {
+	mirror := fixtureServer(t)
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+
+	c := NewClient(WithoutVerification(), WithBaseURL(down.URL), WithMirrors(down.URL+"/other", mirror.URL))
+	rec, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex != 1000 {
+		t.Errorf("got pulse %d from the mirror", rec.Pulse.PulseIndex)
+	}
+
+	// only unavailability fails over, a missing record is final
+	c = NewClient(WithoutVerification(), WithBaseURL(missing.URL), WithMirrors(mirror.URL))
+	if _, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClientClockSkew(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.Period = 60000
+	offset := 10 * time.Minute
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a beacon whose clock is ten minutes behind ours, and whose latest pulse is on time by it
+		now := time.Now().Add(-offset)
+		w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+		rec := rec
+		rec.Pulse.TimeStamp = now.Add(-30 * time.Second)
+		json.NewEncoder(w).Encode(rec)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+	if _, ok := c.ClockSkew(); ok {
+		t.Error("the skew shouldn't be known before any request")
+	}
+	skew, err := c.CheckClock(context.Background(), time.Minute)
+	if !errors.Is(err, ErrClockSkew) {
+		t.Errorf("expected ErrClockSkew, got %v", err)
+	}
+	if skew < offset-2*time.Second || skew > offset+2*time.Second {
+		t.Errorf("estimated skew %s, expected about %s", skew, offset)
+	}
+	if _, err := c.LastRecord(context.Background()); !errors.Is(err, ErrStale) || !strings.Contains(err.Error(), "local clock ahead") {
+		t.Errorf("expected the stale error to point at the clock, got %v", err)
+	}
+
+	offset = 0
+	c = NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+	if skew, err := c.CheckClock(context.Background(), time.Minute); err != nil || skew > 2*time.Second || skew < -2*time.Second {
+		t.Errorf("expected no skew, got %s, %v", skew, err)
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	NewClient(WithoutVerification(), WithBaseURL(srv.URL)).CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+	if ua := got.Get("User-Agent"); ua != DefaultUserAgent {
+		t.Errorf("got User-Agent %q", ua)
+	}
+
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithUserAgent("acme-lottery/1.2"), WithHeader("X-Team", "draws"), WithHeader("X-Team", "audit"))
+	c.CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+	if ua := got.Get("User-Agent"); ua != "acme-lottery/1.2" {
+		t.Errorf("got User-Agent %q", ua)
+	}
+	if team := got.Values("X-Team"); len(team) != 2 || team[0] != "draws" || team[1] != "audit" {
+		t.Errorf("got X-Team %q", team)
+	}
+}
+
+func TestClientRawResponses(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := fixtureServer(t)
+
+	rec, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL)).CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Raw() != nil {
+		t.Error("expected the raw bytes not to be retained by default")
+	}
+
+	rec, err = NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRawResponses()).CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rec.Raw()) != string(buf) {
+		t.Error("expected the raw bytes to be the served ones")
+	}
+	if rec.Pulse.PulseIndex != 1000 {
+		t.Errorf("got pulse %d", rec.Pulse.PulseIndex)
+	}
+}
+
+func TestSetClientConcurrently(t *testing.T) {
+	prev := DefaultClient()
+	defer defaultClient.Store(prev)
+
+	cli := &http.Client{}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetClient(cli)
+		}()
+		go func() {
+			defer wg.Done()
+			if DefaultClient().BaseURL() != DefaultBaseURL {
+				t.Error("the default client isn't configured with the default base URL")
+			}
+		}()
+	}
+	wg.Wait()
+	if c := DefaultClient(); c == prev || c.http != cli {
+		t.Error("SetClient didn't replace the default client")
+	}
+}
+
+func TestParseTimeParam(t *testing.T) {
+	for v, want := range map[string]time.Time{
+		"1577836800":    time.Unix(1577836800, 0),
+		"1577836800123": time.UnixMilli(1577836800123),
+	} {
+		if got, ok := ParseTimeParam(v); !ok || !got.Equal(want) {
+			t.Errorf("ParseTimeParam(%q) = %s, %v", v, got, ok)
+		}
+	}
+	if _, ok := ParseTimeParam("2020-01-01"); ok {
+		t.Error("expected a date to be rejected")
+	}
+}
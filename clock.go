@@ -0,0 +1,63 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// dateResolution is the precision of the HTTP Date header, local clocks in sync lag behind it by up to that much
+const dateResolution = time.Second
+
+// observeDate records the skew of the local clock relative to the Date header of a response
+func (c *Client) observeDate(date string) {
+	t, err := http.ParseTime(date)
+	if err != nil {
+		return
+	}
+	skew := time.Since(t)
+	// the header is truncated to the second, a clock in sync is up to a second ahead of it
+	if skew > 0 {
+		skew = max(skew-dateResolution, 0)
+	}
+	c.skew.Store(int64(skew))
+	c.skewKnown.Store(true)
+}
+
+// ClockSkew returns how far the local clock is ahead of the beacon's, negative if it's behind, as estimated from the Date header of the
+// last response. The estimate has a resolution of about a second, ok is false until a response with a Date header is received.
+func (c *Client) ClockSkew() (skew time.Duration, ok bool) {
+	return time.Duration(c.skew.Load()), c.skewKnown.Load()
+}
+
+// CheckClock fetches the latest record and estimates the skew of the local clock, returning an ErrClockSkew error along with the
+// estimate if it exceeds threshold. Without a Date header the skew is estimated from the pulse's timestamp alone: the latest pulse
+// must have been emitted less than a period ago, and not in the future.
+func (c *Client) CheckClock(ctx context.Context, threshold time.Duration) (time.Duration, error) {
+	rec, err := c.LastRecord(ctx)
+	if err != nil && !errors.Is(err, ErrStale) {
+		return 0, err
+	}
+
+	skew, ok := c.ClockSkew()
+	if !ok {
+		now := time.Now()
+		switch next := rec.NextPulseTime(); {
+		case now.Before(rec.Pulse.TimeStamp):
+			skew = now.Sub(rec.Pulse.TimeStamp)
+		case now.After(next):
+			// a stale beacon can't be told apart from a clock running ahead
+			skew = now.Sub(next)
+		default:
+			skew = 0
+		}
+	}
+
+	if skew > threshold || skew < -threshold {
+		c.log.Warn("Local clock skewed", "clock_skew", skew, "threshold", threshold)
+		return skew, &Error{Kind: ErrClockSkew, URL: c.url("/pulse/last"), Err: fmt.Errorf("local clock off by %s", skew)}
+	}
+	return skew, nil
+}
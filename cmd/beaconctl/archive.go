@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+	"github.com/sherlach/go-nist-beacon/archive/boltstore"
+)
+
+// archiveSummary is the outcome of verify-archive
+type archiveSummary struct {
+	Records int `json:"records"`
+	Valid   int `json:"valid"`
+	// Invalid lists the records failing signature or output value verification
+	Invalid []string `json:"invalid"`
+	// BrokenLinks lists the records not referencing the output value of the record before them
+	BrokenLinks []string `json:"brokenLinks"`
+	// Gaps is how many pulses are missing between archived records of the same chain
+	Gaps int `json:"gaps"`
+}
+
+// archivedRecords returns every record of the archive database at path, or of the exports in the directory at path
+func archivedRecords(path string) ([]beacon.Record, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		a, err := boltstore.OpenReadOnly(path)
+		if err != nil {
+			return nil, err
+		}
+		defer a.Close()
+		// ordered by timestamp, which orders chains too
+		return a.Range(time.Unix(0, 0), time.UnixMilli(math.MaxInt64))
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var recs []beacon.Record
+	for _, e := range entries {
+		var read func(*os.File) iter.Seq2[beacon.Record, error]
+		switch filepath.Ext(e.Name()) {
+		case ".csv":
+			read = func(f *os.File) iter.Seq2[beacon.Record, error] { return archive.ReadCSV(f) }
+		case ".jsonl":
+			read = func(f *os.File) iter.Seq2[beacon.Record, error] { return archive.ReadJSONL(f) }
+		case ".json":
+			read = func(f *os.File) iter.Seq2[beacon.Record, error] { return beacon.DecodeRecords(f) }
+		default:
+			continue
+		}
+		f, err := os.Open(filepath.Join(path, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for rec, err := range read(f) {
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s: %w", e.Name(), err)
+			}
+			recs = append(recs, rec)
+		}
+		f.Close()
+	}
+	slices.SortFunc(recs, func(a, b beacon.Record) int {
+		if a.Pulse.ChainIndex != b.Pulse.ChainIndex {
+			return a.Pulse.ChainIndex - b.Pulse.ChainIndex
+		}
+		return a.Pulse.PulseIndex - b.Pulse.PulseIndex
+	})
+	return slices.CompactFunc(recs, func(a, b beacon.Record) bool {
+		return a.Pulse.ChainIndex == b.Pulse.ChainIndex && a.Pulse.PulseIndex == b.Pulse.PulseIndex
+	}), nil
+}
+
+func pulseName(rec beacon.Record) string {
+	return fmt.Sprintf("%d/%d", rec.Pulse.ChainIndex, rec.Pulse.PulseIndex)
+}
+
+func verifyArchive(ctx context.Context, c *beacon.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New("verify-archive takes the path of an archive database or export directory")
+	}
+	recs, err := archivedRecords(args[0])
+	if err != nil {
+		return err
+	}
+
+	s := archiveSummary{Records: len(recs), Invalid: []string{}, BrokenLinks: []string{}}
+	for i, rec := range recs {
+		if err := c.Verify(ctx, rec); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.Invalid = append(s.Invalid, pulseName(rec)+": "+err.Error())
+		} else {
+			s.Valid++
+		}
+
+		if i == 0 || rec.Pulse.ChainIndex != recs[i-1].Pulse.ChainIndex {
+			continue
+		}
+		prev := recs[i-1]
+		if rec.Pulse.PulseIndex != prev.Pulse.PulseIndex+1 {
+			s.Gaps += rec.Pulse.PulseIndex - prev.Pulse.PulseIndex - 1
+			continue
+		}
+		if !strings.EqualFold(rec.PreviousOutputValue(), prev.Pulse.OutputValue) {
+			s.BrokenLinks = append(s.BrokenLinks, pulseName(rec))
+		}
+	}
+
+	if *format == "json" {
+		json.NewEncoder(os.Stdout).Encode(s)
+	} else {
+		fmt.Printf("%d records, %d valid, %d invalid, %d broken links, %d missing pulses\n", s.Records, s.Valid, len(s.Invalid), len(s.BrokenLinks), s.Gaps)
+		for _, msg := range s.Invalid {
+			fmt.Println("invalid: " + msg)
+		}
+		for _, name := range s.BrokenLinks {
+			fmt.Println("broken link: " + name)
+		}
+	}
+	if len(s.Invalid) > 0 || len(s.BrokenLinks) > 0 {
+		return errors.New("The archive failed verification")
+	}
+	return nil
+}
+
+// syncReport is a line of the progress sync prints
+type syncReport struct {
+	Latest     time.Time `json:"latest"`
+	LagSeconds float64   `json:"lagSeconds"`
+	Tailed     int       `json:"tailed"`
+	Backfilled int       `json:"backfilled"`
+	Missing    int       `json:"missing"`
+	Invalid    int       `json:"invalid"`
+	Error      string    `json:"error,omitempty"`
+}
+
+func printSyncStatus(st archive.SyncStatus) {
+	r := syncReport{Latest: st.Latest, LagSeconds: st.Lag.Seconds(), Tailed: st.Tailed, Backfilled: st.Backfilled, Missing: st.Missing, Invalid: st.Invalid}
+	if st.Err != nil {
+		r.Error = st.Err.Error()
+	}
+	if *format == "json" {
+		json.NewEncoder(os.Stdout).Encode(r)
+		return
+	}
+	fmt.Printf("latest %s, lag %s, %d tailed, %d backfilled, %d missing, %d invalid\n", st.Latest.UTC().Format(beacon.TimeStampFormat),
+		st.Lag.Round(time.Second), st.Tailed, st.Backfilled, st.Missing, st.Invalid)
+	if r.Error != "" {
+		fmt.Println("error: " + r.Error)
+	}
+}
+
+func syncArchive(ctx context.Context, c *beacon.Client, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("sync takes the path of an archive database, and optionally the time to backfill from")
+	}
+	var opts []archive.SyncOption
+	if len(args) == 2 {
+		from, err := parseTime(args[1])
+		if err != nil {
+			return err
+		}
+		opts = append(opts, archive.WithBackfillFrom(from))
+	}
+	a, err := boltstore.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	s := archive.NewSyncer(a, c, opts...)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Run(ctx)
+	}()
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			printSyncStatus(s.Status())
+			return nil
+		case <-t.C:
+			printSyncStatus(s.Status())
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sherlach/go-nist-beacon/archive/boltstore"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestVerifyArchive(t *testing.T) {
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	recs := srv.Records()
+
+	path := filepath.Join(t.TempDir(), "archive.db")
+	a, err := boltstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, rec := range recs {
+		if i == 2 {
+			// the output value is kept, so that the record still links to its neighbours
+			rec.Pulse.LocalRandomValue = recs[3].Pulse.LocalRandomValue
+		}
+		if err := a.Put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	err = verifyArchive(context.Background(), srv.Client(), []string{path})
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	if err == nil {
+		t.Error("expected the tampered archive to fail verification")
+	}
+	want := "invalid: " + pulseName(recs[2]) + ": "
+	if !strings.Contains(string(out), want) || strings.Count(string(out), "invalid: ") != 1 {
+		t.Errorf("expected only %s to be reported invalid, got:\n%s", pulseName(recs[2]), out)
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("expected the audited archive to be left untouched")
+	}
+}
@@ -0,0 +1,272 @@
+// Command beaconctl exposes the beacon library on the command line, for operators scripting lotteries and audits.
+//
+// Usage:
+//
+//	beaconctl [flags] <command> [arguments]
+//
+// The commands are:
+//
+//	last                    print the latest record
+//	at <time>               print the record closest to time
+//	range <from> <to>       print every record between from and to
+//	verify [time]           verify the signature of the record at time, or of the latest one
+//	watch                   print every new record as it is published
+//	rand [-n count] [time]  print pseudo random numbers derived from the record at time, or from the latest one
+//	verify-archive <path>   re-verify every record of an archive database, or of the CSV, JSON Lines and JSON dump files in a directory
+//	sync <path> [from]      keep an archive database up to date with the beacon, backfilling it from the given time, printing progress every minute
+//
+// Times are either RFC 3339 timestamps or unix seconds. The client reads the BEACON_ environment variables and the
+// JSON, YAML or TOML configuration file named by BEACON_CONFIG, see config.FromEnv, the -url and -timeout flags overriding them.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/config"
+)
+
+var (
+	baseURL = flag.String("url", beacon.DefaultBaseURL, "base URL of the beacon API")
+	timeout = flag.Duration("timeout", beacon.DefaultTimeout, "timeout of each request")
+	format  = flag.String("o", "table", "output format: table or json")
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: beaconctl [flags] <last|at|range|verify|watch|rand|verify-archive|sync> [arguments]")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintln(os.Stderr, "beaconctl: unknown output format "+*format)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// the client is configured by the BEACON_ environment variables, overridden by the flags given explicitly
+	var opts []beacon.Option
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "url":
+			opts = append(opts, beacon.WithBaseURL(*baseURL))
+		case "timeout":
+			opts = append(opts, beacon.WithTimeout(*timeout))
+		}
+	})
+	c, err := config.ClientFromEnv(opts...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beaconctl: "+err.Error())
+		os.Exit(2)
+	}
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+
+	switch cmd {
+	case "last":
+		err = last(ctx, c, args)
+	case "at":
+		err = at(ctx, c, args)
+	case "range":
+		err = rangeRecords(ctx, c, args)
+	case "verify":
+		err = verify(ctx, c, args)
+	case "watch":
+		err = watch(ctx, c, args)
+	case "rand":
+		err = randNumbers(ctx, c, args)
+	case "verify-archive":
+		err = verifyArchive(ctx, c, args)
+	case "sync":
+		err = syncArchive(ctx, c, args)
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "beaconctl: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+// parseTime parses an RFC 3339 timestamp or unix seconds
+func parseTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, errors.New("Couldn't parse the time " + s + ", expected RFC 3339 or unix seconds")
+	}
+	return t, nil
+}
+
+// recordAt fetches the record closest to the time given as the only argument, or the latest one without arguments
+func recordAt(ctx context.Context, c *beacon.Client, args []string) (beacon.Record, error) {
+	switch len(args) {
+	case 0:
+		return c.LastRecord(ctx)
+	case 1:
+		t, err := parseTime(args[0])
+		if err != nil {
+			return beacon.Record{}, err
+		}
+		return c.CurrentRecord(ctx, t)
+	default:
+		return beacon.Record{}, errors.New("Too many arguments")
+	}
+}
+
+// printer writes records in the selected output format
+type printer struct {
+	tw  *tabwriter.Writer
+	enc *json.Encoder
+}
+
+func newPrinter(w io.Writer) *printer {
+	if *format == "json" {
+		return &printer{enc: json.NewEncoder(w)}
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHAIN\tPULSE\tTIMESTAMP\tSTATUS\tOUTPUT")
+	return &printer{tw: tw}
+}
+
+func (p *printer) print(rec beacon.Record) {
+	if p.enc != nil {
+		p.enc.Encode(rec)
+		return
+	}
+	out := rec.Pulse.OutputValue
+	if len(out) > 16 {
+		out = out[:16] + "..."
+	}
+	fmt.Fprintf(p.tw, "%d\t%d\t%s\t%d\t%s\n", rec.Pulse.ChainIndex, rec.Pulse.PulseIndex, rec.Pulse.TimeStamp.UTC().Format(beacon.TimeStampFormat), rec.Pulse.StatusCode, out)
+}
+
+func (p *printer) flush() {
+	if p.tw != nil {
+		p.tw.Flush()
+	}
+}
+
+func last(ctx context.Context, c *beacon.Client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("last takes no arguments")
+	}
+	return at(ctx, c, nil)
+}
+
+func at(ctx context.Context, c *beacon.Client, args []string) error {
+	rec, err := recordAt(ctx, c, args)
+	if err != nil {
+		return err
+	}
+	p := newPrinter(os.Stdout)
+	p.print(rec)
+	p.flush()
+	return nil
+}
+
+func rangeRecords(ctx context.Context, c *beacon.Client, args []string) error {
+	if len(args) != 2 {
+		return errors.New("range takes a start and an end time")
+	}
+	from, err := parseTime(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := parseTime(args[1])
+	if err != nil {
+		return err
+	}
+
+	p := newPrinter(os.Stdout)
+	defer p.flush()
+	for rec, err := range c.Records(ctx, from, to) {
+		if err != nil {
+			return err
+		}
+		p.print(rec)
+	}
+	return nil
+}
+
+func verify(ctx context.Context, c *beacon.Client, args []string) error {
+	rec, err := recordAt(ctx, c, args)
+	if err != nil {
+		return err
+	}
+	if err := c.Verify(ctx, rec); err != nil {
+		return fmt.Errorf("pulse %d of chain %d failed verification: %w", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex, err)
+	}
+	fmt.Printf("pulse %d of chain %d is valid\n", rec.Pulse.PulseIndex, rec.Pulse.ChainIndex)
+	return nil
+}
+
+func watch(ctx context.Context, c *beacon.Client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("watch takes no arguments")
+	}
+	p := newPrinter(os.Stdout)
+	w := c.Watch(ctx)
+	defer w.Stop()
+	for rec := range w.Records() {
+		p.print(rec)
+		p.flush()
+	}
+	return nil
+}
+
+// maxRandNumbers bounds how many numbers rand prints, so a typo can't exhaust the memory
+const maxRandNumbers = 1_000_000
+
+func randNumbers(ctx context.Context, c *beacon.Client, args []string) error {
+	fs := flag.NewFlagSet("rand", flag.ExitOnError)
+	n := fs.Int("n", 1, "how many numbers to print, at most 1000000")
+	fs.Parse(args)
+	if *n < 0 || *n > maxRandNumbers {
+		fmt.Fprintf(os.Stderr, "beaconctl: -n must be between 0 and %d\n", maxRandNumbers)
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rec, err := recordAt(ctx, c, fs.Args())
+	if err != nil {
+		return err
+	}
+	src, err := beacon.NewSource(rec)
+	if err != nil {
+		return err
+	}
+	r := rand.New(src)
+
+	nums := make([]int, *n)
+	for i := range nums {
+		nums[i] = r.Int()
+	}
+	if *format == "json" {
+		return json.NewEncoder(os.Stdout).Encode(nums)
+	}
+	for _, v := range nums {
+		fmt.Println(v)
+	}
+	return nil
+}
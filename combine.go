@@ -0,0 +1,169 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NamedSource is a beacon taking part in a combination, its name identifies it in transcripts
+type NamedSource struct {
+	Name   string
+	Source BeaconSource
+}
+
+// Combiner merges the pulses of several independent beacons into a single value, so no single operator has to be trusted
+type Combiner struct {
+	sources []NamedSource
+	quorum  int
+}
+
+// NewCombiner returns a combiner over sources, requiring at least quorum of them to respond. Source names must be unique.
+func NewCombiner(quorum int, sources ...NamedSource) (*Combiner, error) {
+	if quorum < 1 || quorum > len(sources) {
+		return nil, errors.New(fmt.Sprintf("Invalid quorum %d for %d sources", quorum, len(sources)))
+	}
+	seen := make(map[string]bool)
+	for _, s := range sources {
+		if seen[s.Name] {
+			return nil, errors.New("Duplicate source name: " + s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return &Combiner{sources: sources, quorum: quorum}, nil
+}
+
+// Contribution is the pulse a source provided to a combination
+type Contribution struct {
+	Source string `json:"source"`
+	Record Record `json:"record"`
+}
+
+// Failure records a source that didn't contribute to a combination
+type Failure struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
+
+// Combination is the transcript of a combination: everything needed to recompute and check its value
+type Combination struct {
+	Time          time.Time      `json:"time"`
+	Quorum        int            `json:"quorum"`
+	Contributions []Contribution `json:"contributions"`
+	Failures      []Failure      `json:"failures,omitempty"`
+	// Verified tells whether the combination was made by CombineVerified, every contribution having been verified by its source
+	Verified bool `json:"verified,omitempty"`
+	// Value is the hex encoded SHA-512 hash of the contributions, see CombinedValue
+	Value string `json:"value"`
+}
+
+// CombinedValue hashes contributions, in order, into a single value: SHA-512 over each source name and output value, both prefixed with their length
+func CombinedValue(contributions []Contribution) ([]byte, error) {
+	s := &serializer{}
+	for _, c := range contributions {
+		out, err := c.Record.outputBytes()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid contribution from %s: %w", c.Source, err)
+		}
+		s.string(c.Source)
+		s.bytes(out)
+	}
+	sum := sha512.Sum512(s.buf.Bytes())
+	return sum[:], nil
+}
+
+// Verifier is implemented by the sources that can check the pulses they serve, such as Client and the drand adapter
+type Verifier interface {
+	Verify(ctx context.Context, rec Record) error
+}
+
+// Combine fetches the pulse for time t from every source concurrently and combines the outputs of those that responded.
+// It fails if fewer sources than the quorum responded.
+func (c *Combiner) Combine(ctx context.Context, t time.Time) (Combination, error) {
+	return c.combine(ctx, t, false)
+}
+
+// CombineVerified is Combine in quorum verification mode: a source only contributes if its pulse is within a period of t and passes
+// the source's verification, so that a single compromised operator can't forge a combination and the quorum is the number of them
+// that would have to collude. Sources that don't implement Verifier never contribute. The failures tell why each other source was left out.
+func (c *Combiner) CombineVerified(ctx context.Context, t time.Time) (Combination, error) {
+	return c.combine(ctx, t, true)
+}
+
+// checkContribution checks that rec, served by s for time t, belongs to t's epoch and verifies
+func checkContribution(ctx context.Context, s NamedSource, rec Record, t time.Time) error {
+	v, ok := s.Source.(Verifier)
+	if !ok {
+		return errors.New("The source can't verify its pulses")
+	}
+	if d := rec.Pulse.TimeStamp.Sub(t).Abs(); d >= recordPeriod(rec) {
+		return fmt.Errorf("The pulse of %s is %s away from the requested time", rec.Pulse.TimeStamp.UTC().Format(time.RFC3339), d)
+	}
+	if err := v.Verify(ctx, rec); err != nil {
+		return fmt.Errorf("The pulse failed verification: %w", err)
+	}
+	return nil
+}
+
+func (c *Combiner) combine(ctx context.Context, t time.Time, verify bool) (Combination, error) {
+	recs := make([]Record, len(c.sources))
+	errs := make([]error, len(c.sources))
+	var wg sync.WaitGroup
+	for i, s := range c.sources {
+		wg.Add(1)
+		go func(i int, s NamedSource) {
+			defer wg.Done()
+			recs[i], errs[i] = s.Source.At(ctx, t)
+			if errs[i] == nil && verify {
+				errs[i] = checkContribution(ctx, s, recs[i], t)
+			}
+		}(i, s)
+	}
+	wg.Wait()
+
+	comb := Combination{Time: t, Quorum: c.quorum, Verified: verify}
+	for i, s := range c.sources {
+		if errs[i] != nil {
+			comb.Failures = append(comb.Failures, Failure{Source: s.Name, Error: errs[i].Error()})
+			continue
+		}
+		comb.Contributions = append(comb.Contributions, Contribution{Source: s.Name, Record: recs[i]})
+	}
+	if len(comb.Contributions) < c.quorum {
+		what := "responded"
+		if verify {
+			what = "served a verified pulse"
+		}
+		return comb, errors.New(fmt.Sprintf("Only %d of %d sources %s, %d are required", len(comb.Contributions), len(c.sources), what, c.quorum))
+	}
+
+	value, err := CombinedValue(comb.Contributions)
+	if err != nil {
+		return comb, err
+	}
+	comb.Value = strings.ToUpper(hex.EncodeToString(value))
+	return comb, nil
+}
+
+// Verify recomputes the combination's value from its contributions and checks the quorum was met.
+// It doesn't check the contributed records themselves, which should be verified against their beacons.
+func (comb *Combination) Verify() error {
+	if len(comb.Contributions) < comb.Quorum {
+		return errors.New("The combination doesn't meet its quorum")
+	}
+	want, err := CombinedValue(comb.Contributions)
+	if err != nil {
+		return err
+	}
+	got, err := hex.DecodeString(comb.Value)
+	if err != nil || !bytes.Equal(got, want) {
+		return errors.New("The combination's value doesn't match its contributions")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	rec Record
+	err error
+}
+
+func (f fakeSource) Last(context.Context) (Record, error)                { return f.rec, f.err }
+func (f fakeSource) At(context.Context, time.Time) (Record, error)       { return f.rec, f.err }
+func (f fakeSource) Next(context.Context, time.Time) (Record, error)     { return f.rec, f.err }
+func (f fakeSource) Previous(context.Context, time.Time) (Record, error) { return f.rec, f.err }
+
+func TestCombiner(t *testing.T) {
+	recs := fixtureChain(t, 2)
+	down := fakeSource{err: errors.New("down")}
+
+	c, err := NewCombiner(2,
+		NamedSource{"a", fakeSource{rec: recs[0]}},
+		NamedSource{"b", down},
+		NamedSource{"c", fakeSource{rec: recs[1]}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comb, err := c.Combine(context.Background(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comb.Contributions) != 2 || len(comb.Failures) != 1 || comb.Failures[0].Source != "b" {
+		t.Errorf("unexpected transcript: %+v", comb)
+	}
+	if err := comb.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	comb.Contributions[0], comb.Contributions[1] = comb.Contributions[1], comb.Contributions[0]
+	if err := comb.Verify(); err == nil {
+		t.Error("expected a reordered transcript to fail verification")
+	}
+
+	c, _ = NewCombiner(2, NamedSource{"a", fakeSource{rec: recs[0]}}, NamedSource{"b", down})
+	if _, err := c.Combine(context.Background(), time.Now()); err == nil {
+		t.Error("expected the combination to fail without a quorum")
+	}
+}
+
+type verifyingSource struct {
+	fakeSource
+	verr error
+}
+
+func (v verifyingSource) Verify(context.Context, Record) error { return v.verr }
+
+func TestCombineVerified(t *testing.T) {
+	recs := fixtureChain(t, 3)
+	at := recs[1].Pulse.TimeStamp
+
+	sources := []NamedSource{
+		{"good", verifyingSource{fakeSource: fakeSource{rec: recs[1]}}},
+		{"forged", verifyingSource{fakeSource: fakeSource{rec: recs[1]}, verr: ErrSignatureInvalid}},
+		{"unverifiable", fakeSource{rec: recs[1]}},
+		{"late", verifyingSource{fakeSource: fakeSource{rec: recs[2]}}},
+	}
+	c, err := NewCombiner(1, sources...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	comb, err := c.CombineVerified(context.Background(), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !comb.Verified || len(comb.Contributions) != 1 || comb.Contributions[0].Source != "good" {
+		t.Errorf("unexpected contributions: %+v", comb.Contributions)
+	}
+	if len(comb.Failures) != 3 {
+		t.Fatalf("expected every other source to fail, got %+v", comb.Failures)
+	}
+	for _, f := range comb.Failures {
+		if f.Error == "" {
+			t.Errorf("no reason given for %s", f.Source)
+		}
+	}
+	if err := comb.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	// unverified, every source contributes
+	if comb, err := c.Combine(context.Background(), at); err != nil || len(comb.Contributions) != 4 || comb.Verified {
+		t.Errorf("unexpected unverified combination: %+v, %v", comb, err)
+	}
+
+	c, _ = NewCombiner(2, sources...)
+	if _, err := c.CombineVerified(context.Background(), at); err == nil {
+		t.Error("expected the combination to fail with a single verified source")
+	}
+}
@@ -0,0 +1,84 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Commitment binds some inputs to a pulse emitted in the future: the randomness derived from them is decided by the first pulse
+// emitted at or after PulseTime, which nobody could know when the commitment was made. It marshals to JSON for publication.
+type Commitment struct {
+	PulseTime  time.Time `json:"pulseTime"`
+	InputsHash string    `json:"inputsHash"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func hashInputs(inputs []byte) string {
+	sum := sha512.Sum512(inputs)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Commit commits to inputs and to the first pulse emitted at or after t, which must be in the future. t is rounded up to the second.
+func Commit(inputs []byte, t time.Time) (*Commitment, error) {
+	now := time.Now()
+	if r := t.Truncate(time.Second); !r.Equal(t) {
+		t = r.Add(time.Second)
+	}
+	if !t.After(now) {
+		return nil, errors.New("Commitments must target a future pulse")
+	}
+	return &Commitment{PulseTime: t.UTC(), InputsHash: hashInputs(inputs), CreatedAt: now.UTC()}, nil
+}
+
+// Resolve checks that rec is the pulse the commitment targets and that inputs are the committed ones, then returns the derived value:
+// the SHA-512 of the inputs hash and the pulse's output value. prev must be the pulse preceding rec in its chain, it proves no earlier
+// pulse was emitted at or after the committed time. Signatures aren't checked, use Client.Resolve to fetch and verify the pulses.
+func (cm *Commitment) Resolve(rec, prev Record, inputs []byte) ([]byte, error) {
+	if !strings.EqualFold(hashInputs(inputs), cm.InputsHash) {
+		return nil, errors.New("The inputs don't match the commitment")
+	}
+	if rec.Pulse.TimeStamp.Before(cm.PulseTime) || !cm.CreatedAt.Before(rec.Pulse.TimeStamp) {
+		return nil, errors.New("The pulse wasn't emitted at the committed time")
+	}
+	if !prev.Pulse.TimeStamp.Before(cm.PulseTime) {
+		return nil, errors.New("The pulse isn't the first one emitted at or after the committed time")
+	}
+	if !strings.EqualFold(rec.PreviousOutputValue(), prev.Pulse.OutputValue) {
+		return nil, errors.New("The previous pulse isn't the one preceding the committed pulse")
+	}
+
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+	committed, err := hex.DecodeString(cm.InputsHash)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't decode the commitment's inputs hash: %w", err)
+	}
+	return hashFramed(committed, out), nil
+}
+
+// Resolve fetches and verifies the pulse targeted by the commitment and the one preceding it, then resolves the commitment.
+// It returns ErrNotFound if the pulse hasn't been emitted yet.
+func (c *Client) Resolve(ctx context.Context, cm *Commitment, inputs []byte) (Record, []byte, error) {
+	rec, err := c.NextRecord(ctx, cm.PulseTime.Add(-time.Second))
+	if err != nil {
+		return Record{}, nil, err
+	}
+	prev, err := c.PreviousRecord(ctx, rec.Pulse.TimeStamp)
+	if err != nil {
+		return rec, nil, err
+	}
+	for _, r := range []Record{rec, prev} {
+		if err := c.Verify(ctx, r); err != nil {
+			return rec, nil, err
+		}
+	}
+	v, err := cm.Resolve(rec, prev, inputs)
+	return rec, v, err
+}
@@ -0,0 +1,61 @@
+package beacon_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestCommitment(t *testing.T) {
+	if _, err := beacon.Commit([]byte("inputs"), time.Now().Add(-time.Minute)); err == nil {
+		t.Error("expected a commitment to a past pulse to fail")
+	}
+
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	inputs := []byte("round 1 entrants")
+	cm, err := beacon.Commit(inputs, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Resolve(ctx, cm, inputs); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("expected ErrNotFound before the pulse is emitted, got %v", err)
+	}
+
+	// pretend the commitment was made before the fake chain started
+	target, _ := srv.Record(10)
+	cm.CreatedAt = target.Pulse.TimeStamp.Add(-time.Hour)
+	cm.PulseTime = target.Pulse.TimeStamp.Add(-time.Second)
+
+	rec, v, err := c.Resolve(ctx, cm, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex != target.Pulse.PulseIndex {
+		t.Errorf("resolved to pulse %d, expected %d", rec.Pulse.PulseIndex, target.Pulse.PulseIndex)
+	}
+	if len(v) != 64 {
+		t.Errorf("expected a 512-bit derived value, got %d bytes", len(v))
+	}
+
+	prev, _ := srv.Record(9)
+	again, err := cm.Resolve(target, prev, inputs)
+	if err != nil || !bytes.Equal(v, again) {
+		t.Errorf("resolving offline gave %x, %v", again, err)
+	}
+	if _, err := cm.Resolve(target, prev, []byte("other entrants")); err == nil {
+		t.Error("expected different inputs to fail")
+	}
+	later, _ := srv.Record(11)
+	if _, err := cm.Resolve(later, target, inputs); err == nil {
+		t.Error("expected a later pulse to fail")
+	}
+}
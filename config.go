@@ -0,0 +1,255 @@
+package beacon
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the configuration of a client as read from the environment or from a configuration file, so that deployments
+// can reconfigure it without code changes. Empty fields keep the client defaults. Durations are written as accepted by
+// time.ParseDuration, e.g. "30s".
+type Config struct {
+	// Beacon names the public beacon to query, see KnownBeaconURL. BaseURL takes precedence.
+	Beacon  string   `json:"beacon,omitempty" yaml:"beacon,omitempty" toml:"beacon,omitempty"`
+	BaseURL string   `json:"baseUrl,omitempty" yaml:"baseUrl,omitempty" toml:"baseUrl,omitempty"`
+	Mirrors []string `json:"mirrors,omitempty" yaml:"mirrors,omitempty" toml:"mirrors,omitempty"`
+	// Hedge is passed to WithHedging
+	Hedge   string `json:"hedge,omitempty" yaml:"hedge,omitempty" toml:"hedge,omitempty"`
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	Proxy   string `json:"proxy,omitempty" yaml:"proxy,omitempty" toml:"proxy,omitempty"`
+	// Period is passed to WithPeriod, for beacons that don't pulse every minute
+	Period string `json:"period,omitempty" yaml:"period,omitempty" toml:"period,omitempty"`
+	// Staleness is passed to WithStaleness, "0" disables the staleness check
+	Staleness string            `json:"staleness,omitempty" yaml:"staleness,omitempty" toml:"staleness,omitempty"`
+	UserAgent string            `json:"userAgent,omitempty" yaml:"userAgent,omitempty" toml:"userAgent,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty"`
+	// RateLimit and RateBurst are passed to WithRateLimit, the burst defaulting to 1
+	RateLimit          float64  `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty" toml:"rateLimit,omitempty"`
+	RateBurst          int      `json:"rateBurst,omitempty" yaml:"rateBurst,omitempty" toml:"rateBurst,omitempty"`
+	MaxResponseSize    int64    `json:"maxResponseSize,omitempty" yaml:"maxResponseSize,omitempty" toml:"maxResponseSize,omitempty"`
+	DisableCompression bool     `json:"disableCompression,omitempty" yaml:"disableCompression,omitempty" toml:"disableCompression,omitempty"`
+	TLSPins            []string `json:"tlsPins,omitempty" yaml:"tlsPins,omitempty" toml:"tlsPins,omitempty"`
+	// CertificateRoots is the path of a PEM file of the roots passed to WithCertificateRoots
+	CertificateRoots string `json:"certificateRoots,omitempty" yaml:"certificateRoots,omitempty" toml:"certificateRoots,omitempty"`
+}
+
+// The environment variables read by ConfigFromEnv. Lists are comma separated.
+const (
+	EnvConfig             = "BEACON_CONFIG"
+	EnvBeacon             = "BEACON_NAME"
+	EnvBaseURL            = "BEACON_BASE_URL"
+	EnvMirrors            = "BEACON_MIRRORS"
+	EnvHedge              = "BEACON_HEDGE"
+	EnvTimeout            = "BEACON_TIMEOUT"
+	EnvProxy              = "BEACON_PROXY"
+	EnvPeriod             = "BEACON_PERIOD"
+	EnvStaleness          = "BEACON_STALENESS"
+	EnvUserAgent          = "BEACON_USER_AGENT"
+	EnvRateLimit          = "BEACON_RATE_LIMIT"
+	EnvRateBurst          = "BEACON_RATE_BURST"
+	EnvMaxResponseSize    = "BEACON_MAX_RESPONSE_SIZE"
+	EnvDisableCompression = "BEACON_DISABLE_COMPRESSION"
+	EnvTLSPins            = "BEACON_TLS_PINS"
+	EnvCertificateRoots   = "BEACON_CERTIFICATE_ROOTS"
+)
+
+// LoadConfig reads a JSON configuration file. Unknown keys are rejected so that typos don't go unnoticed. The config package reads
+// YAML and TOML files too.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("Couldn't read the configuration: %w", err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".json" {
+		return cfg, errors.New("Unsupported configuration format: " + ext)
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("Couldn't parse the configuration %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// splitList splits a comma separated list, dropping empty elements
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// ConfigFromEnv reads the JSON configuration file named by BEACON_CONFIG, if set, and overrides it with the other BEACON_
+// environment variables that are set
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	if path := os.Getenv(EnvConfig); path != "" {
+		var err error
+		if cfg, err = LoadConfig(path); err != nil {
+			return cfg, err
+		}
+	}
+	err := cfg.ReadEnv()
+	return cfg, err
+}
+
+// ReadEnv overrides cfg with the BEACON_ environment variables that are set, but BEACON_CONFIG
+func (cfg *Config) ReadEnv() error {
+	strs := map[string]*string{
+		EnvBeacon:           &cfg.Beacon,
+		EnvBaseURL:          &cfg.BaseURL,
+		EnvHedge:            &cfg.Hedge,
+		EnvTimeout:          &cfg.Timeout,
+		EnvProxy:            &cfg.Proxy,
+		EnvPeriod:           &cfg.Period,
+		EnvStaleness:        &cfg.Staleness,
+		EnvUserAgent:        &cfg.UserAgent,
+		EnvCertificateRoots: &cfg.CertificateRoots,
+	}
+	for name, v := range strs {
+		if s, ok := os.LookupEnv(name); ok {
+			*v = s
+		}
+	}
+	if s, ok := os.LookupEnv(EnvMirrors); ok {
+		cfg.Mirrors = splitList(s)
+	}
+	if s, ok := os.LookupEnv(EnvTLSPins); ok {
+		cfg.TLSPins = splitList(s)
+	}
+
+	var err error
+	parse := func(name string, fn func(string) error) {
+		if s, ok := os.LookupEnv(name); ok && err == nil {
+			if e := fn(s); e != nil {
+				err = fmt.Errorf("Couldn't parse %s: %w", name, e)
+			}
+		}
+	}
+	parse(EnvRateLimit, func(s string) (err error) {
+		cfg.RateLimit, err = strconv.ParseFloat(s, 64)
+		return err
+	})
+	parse(EnvRateBurst, func(s string) (err error) {
+		cfg.RateBurst, err = strconv.Atoi(s)
+		return err
+	})
+	parse(EnvMaxResponseSize, func(s string) (err error) {
+		cfg.MaxResponseSize, err = strconv.ParseInt(s, 10, 64)
+		return err
+	})
+	parse(EnvDisableCompression, func(s string) (err error) {
+		cfg.DisableCompression, err = strconv.ParseBool(s)
+		return err
+	})
+	return err
+}
+
+// Options returns the client options applying cfg, failing if a value can't be parsed
+func (cfg Config) Options() ([]Option, error) {
+	var opts []Option
+	if cfg.Beacon != "" {
+		url, ok := KnownBeaconURL(cfg.Beacon)
+		if !ok {
+			return nil, errors.New("Unknown beacon: " + cfg.Beacon)
+		}
+		opts = append(opts, WithBaseURL(url))
+	}
+	if cfg.BaseURL != "" {
+		opts = append(opts, WithBaseURL(strings.TrimSuffix(cfg.BaseURL, "/")))
+	}
+	if len(cfg.Mirrors) > 0 {
+		opts = append(opts, WithMirrors(cfg.Mirrors...))
+	}
+	if cfg.Hedge != "" {
+		d, err := time.ParseDuration(cfg.Hedge)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse the hedging delay: %w", err)
+		}
+		opts = append(opts, WithHedging(d))
+	}
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse the timeout: %w", err)
+		}
+		opts = append(opts, WithTimeout(d))
+	}
+	if cfg.Proxy != "" {
+		u, err := parseProxyURL(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithProxy(u))
+	}
+	if cfg.Period != "" {
+		d, err := time.ParseDuration(cfg.Period)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse the period: %w", err)
+		}
+		opts = append(opts, WithPeriod(d))
+	}
+	if cfg.Staleness != "" {
+		d, err := time.ParseDuration(cfg.Staleness)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't parse the staleness: %w", err)
+		}
+		opts = append(opts, WithStaleness(d))
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, WithUserAgent(cfg.UserAgent))
+	}
+	for k, v := range cfg.Headers {
+		opts = append(opts, WithHeader(k, v))
+	}
+	if cfg.RateLimit > 0 {
+		opts = append(opts, WithRateLimit(cfg.RateLimit, cfg.RateBurst))
+	}
+	if cfg.MaxResponseSize > 0 {
+		opts = append(opts, WithMaxResponseSize(cfg.MaxResponseSize))
+	}
+	if cfg.DisableCompression {
+		opts = append(opts, WithoutCompression())
+	}
+	if len(cfg.TLSPins) > 0 {
+		opts = append(opts, WithTLSPins(cfg.TLSPins...))
+	}
+	if cfg.CertificateRoots != "" {
+		buf, err := os.ReadFile(cfg.CertificateRoots)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read the certificate roots: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(buf) {
+			return nil, errors.New("No certificate in the certificate roots " + cfg.CertificateRoots)
+		}
+		opts = append(opts, WithCertificateRoots(roots))
+	}
+	return opts, nil
+}
+
+// ClientFromEnv returns a client configured by ConfigFromEnv. The given options are applied after the environment,
+// so they override it.
+func ClientFromEnv(opts ...Option) (*Client, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	envOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(append(envOpts, opts...)...), nil
+}
@@ -0,0 +1,72 @@
+// Package config reads client configurations written in YAML or TOML as well as JSON. The beacon package only reads JSON, so that
+// its importers don't link the YAML and TOML parsers.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	beacon "github.com/sherlach/go-nist-beacon"
+	"go.yaml.in/yaml/v2"
+)
+
+// Load reads a configuration file, in JSON, YAML or TOML depending on its .json, .yaml, .yml or .toml extension.
+// Unknown keys are rejected so that typos don't go unnoticed.
+func Load(path string) (beacon.Config, error) {
+	var cfg beacon.Config
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" {
+		return beacon.LoadConfig(path)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("Couldn't read the configuration: %w", err)
+	}
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(buf, &cfg)
+	case ".toml":
+		var md toml.MetaData
+		md, err = toml.Decode(string(buf), &cfg)
+		if err == nil && len(md.Undecoded()) > 0 {
+			err = errors.New("unknown key " + md.Undecoded()[0].String())
+		}
+	default:
+		return cfg, errors.New("Unsupported configuration format: " + ext)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("Couldn't parse the configuration %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// FromEnv reads the configuration file named by BEACON_CONFIG, if set, and overrides it with the other BEACON_ environment
+// variables that are set, as beacon.ConfigFromEnv does for JSON files
+func FromEnv() (beacon.Config, error) {
+	var cfg beacon.Config
+	if path := os.Getenv(beacon.EnvConfig); path != "" {
+		var err error
+		if cfg, err = Load(path); err != nil {
+			return cfg, err
+		}
+	}
+	err := cfg.ReadEnv()
+	return cfg, err
+}
+
+// ClientFromEnv returns a client configured by FromEnv. The given options are applied after the environment, so they override it.
+func ClientFromEnv(opts ...beacon.Option) (*beacon.Client, error) {
+	cfg, err := FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	envOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return beacon.NewClient(append(envOpts, opts...)...), nil
+}
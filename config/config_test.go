@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"beacon.json": `{"baseUrl": "https://beacon.example", "timeout": "10s", "tlsPins": ["pin"]}`,
+		"beacon.yml":  "baseUrl: https://beacon.example\ntimeout: 10s\ntlsPins: [pin]\n",
+		"beacon.toml": "baseUrl = \"https://beacon.example\"\ntimeout = \"10s\"\ntlsPins = [\"pin\"]\n",
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.BaseURL != "https://beacon.example" || cfg.Timeout != "10s" || len(cfg.TLSPins) != 1 {
+				t.Errorf("unexpected configuration %+v", cfg)
+			}
+
+			typo := filepath.Join(dir, "typo"+filepath.Ext(name))
+			if err := os.WriteFile(typo, []byte(strings.Replace(content, "timeout", "timout", 1)), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(typo); err == nil {
+				t.Error("an unknown key was accepted")
+			}
+		})
+	}
+}
+
+func TestClientFromEnv(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "beacon.yaml")
+	if err := os.WriteFile(cfg, []byte("baseUrl: https://file.example/beacon/2.0\nbeacon: uchile\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(beacon.EnvConfig, cfg)
+	c, err := ClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL() != "https://file.example/beacon/2.0" {
+		t.Errorf("the file wasn't applied: %s", c.BaseURL())
+	}
+
+	t.Setenv(beacon.EnvBaseURL, "https://env.example/beacon/2.0")
+	if c, err := ClientFromEnv(); err != nil || c.BaseURL() != "https://env.example/beacon/2.0" {
+		t.Errorf("the environment didn't override the file: %v", err)
+	}
+	t.Setenv(beacon.EnvTimeout, "soon")
+	if _, err := ClientFromEnv(); err == nil {
+		t.Error("an invalid timeout was accepted")
+	}
+}
@@ -0,0 +1,128 @@
+package beacon
+
+import (
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientFromEnv(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "beacon.json")
+	if err := os.WriteFile(cfg, []byte(`{"baseUrl": "https://file.example/beacon/2.0", "timeout": "5s", "userAgent": "file", "headers": {"X-Api-Key": "secret"}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvConfig, cfg)
+	t.Setenv(EnvBaseURL, "https://env.example/beacon/2.0/")
+	t.Setenv(EnvStaleness, "0")
+	t.Setenv(EnvMirrors, "https://a.example, https://b.example")
+	t.Setenv(EnvRateLimit, "2")
+	t.Setenv(EnvHedge, "200ms")
+
+	c, err := ClientFromEnv(WithUserAgent("code"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL != "https://env.example/beacon/2.0" {
+		t.Errorf("the environment didn't override the file: %s", c.baseURL)
+	}
+	if c.timeout != 5*time.Second {
+		t.Errorf("the file timeout wasn't applied: %s", c.timeout)
+	}
+	if c.staleness != 0 {
+		t.Errorf("the staleness check wasn't disabled: %s", c.staleness)
+	}
+	if len(c.mirrors) != 2 || c.mirrors[1] != "https://b.example" {
+		t.Errorf("unexpected mirrors %q", c.mirrors)
+	}
+	if c.hedge != 200*time.Millisecond {
+		t.Errorf("unexpected hedging delay %s", c.hedge)
+	}
+	if c.limiter == nil || c.limiter.rate != 2 {
+		t.Error("the rate limit wasn't applied")
+	}
+	if c.userAgent != "code" {
+		t.Errorf("the options didn't override the configuration: %s", c.userAgent)
+	}
+	if c.header.Get("X-Api-Key") != "secret" {
+		t.Error("the file headers weren't applied")
+	}
+
+	t.Setenv(EnvTimeout, "soon")
+	if _, err := ClientFromEnv(); err == nil {
+		t.Error("an invalid timeout was accepted")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "beacon.json")
+	content := `{"baseUrl": "https://beacon.example", "timeout": "10s", "tlsPins": ["pin"]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseURL != "https://beacon.example" || cfg.Timeout != "10s" || len(cfg.TLSPins) != 1 {
+		t.Errorf("unexpected configuration %+v", cfg)
+	}
+
+	typo := filepath.Join(dir, "typo.json")
+	if err := os.WriteFile(typo, []byte(strings.Replace(content, "timeout", "timout", 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(typo); err == nil {
+		t.Error("an unknown key was accepted")
+	}
+	yml := filepath.Join(dir, "beacon.yml")
+	if err := os.WriteFile(yml, []byte("timeout: 10s\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(yml); err == nil || !strings.Contains(err.Error(), "Unsupported") {
+		t.Errorf("expected YAML to be left to the config package, got %v", err)
+	}
+}
+
+func TestConfigBeacon(t *testing.T) {
+	t.Setenv(EnvBeacon, "UChile")
+	c, err := ClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL != UChileBaseURL {
+		t.Errorf("the beacon name wasn't applied: %s", c.baseURL)
+	}
+
+	t.Setenv(EnvBaseURL, "https://mirror.example/beacon/2.0")
+	if c, err := ClientFromEnv(); err != nil || c.baseURL != "https://mirror.example/beacon/2.0" {
+		t.Errorf("the base URL didn't take precedence over the beacon name: %v", err)
+	}
+
+	t.Setenv(EnvBaseURL, "")
+	t.Setenv(EnvBeacon, "inmetro")
+	if c, err := ClientFromEnv(); err != nil || c.baseURL != InmetroBaseURL {
+		t.Errorf("the Inmetro beacon wasn't selected: %v", err)
+	}
+
+	_, root, buf := testCertificate(t)
+	roots := filepath.Join(t.TempDir(), "roots.pem")
+	if err := os.WriteFile(roots, buf, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want := x509.NewCertPool()
+	want.AddCert(root)
+	t.Setenv(EnvCertificateRoots, roots)
+	if c, err := ClientFromEnv(); err != nil || c.roots == nil || !c.roots.Equal(want) {
+		t.Errorf("the certificate roots weren't applied: %v", err)
+	}
+	t.Setenv(EnvCertificateRoots, "")
+
+	t.Setenv(EnvBeacon, "atlantis")
+	if _, err := ClientFromEnv(); err == nil {
+		t.Error("an unknown beacon was accepted")
+	}
+}
@@ -0,0 +1,93 @@
+package beacon
+
+import (
+	"crypto/hkdf"
+	cryptorand "crypto/rand"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DeriveKey derives n bytes from the record's output value with HKDF-SHA512, info separating the domains of the applications
+// sharing the same pulse: different info strings yield independent values. n can be at most 255*64 bytes.
+//
+// WARNING: despite its name, the derived value is public, anyone holding the pulse and info can compute it. Never use it as a secret,
+// see MixReader for that.
+func DeriveKey(rec Record, info string, n int) ([]byte, error) {
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+	key, err := hkdf.Key(sha512.New, out, nil, info, n)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't derive the key: %w", err)
+	}
+	return key, nil
+}
+
+// mixEntropySize is how many bytes of local entropy MixReader draws, matching the size of the beacon's output value
+const mixEntropySize = 64
+
+// Mix combines the record's output value with local, secret entropy into a stream suitable for secrets, as long as local is.
+// The combination is HKDF-Extract with SHA-512, taking local as the input keying material and the output value as the salt,
+// expanded through SHAKE256: the stream is as unpredictable as local, and as unbiased as the beacon if local is weak.
+func Mix(rec Record, local []byte) (io.Reader, error) {
+	if len(local) == 0 {
+		return nil, errors.New("No local entropy to mix with the beacon's output")
+	}
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha512.New, local, out)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't mix the entropy: %w", err)
+	}
+	h := sha3.NewSHAKE256()
+	h.Write(prk)
+	return h, nil
+}
+
+// MixReader mixes the record's output value with 64 bytes from crypto/rand, see Mix. Unlike the record's Reader, the stream is secret.
+func MixReader(rec Record) (io.Reader, error) {
+	local := make([]byte, mixEntropySize)
+	if _, err := cryptorand.Read(local); err != nil {
+		return nil, fmt.Errorf("Couldn't read local entropy: %w", err)
+	}
+	return Mix(rec, local)
+}
+
+// SplitSize is the size of each value returned by Split
+const SplitSize = 64
+
+// Split expands the record's output value into n independent values of SplitSize bytes, so several draws of the same ceremony
+// can share one pulse without their results being correlated. Value i is HKDF-SHA512 keyed with the output value, with an info
+// framing the length of label, label, i and n as big-endian uint32s: values never repeat across labels, indexes or counts.
+func (rec *Record) Split(n int, label string) ([][]byte, error) {
+	if n < 0 {
+		return nil, errors.New("Can't split a pulse into a negative number of values")
+	}
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha512.New, out, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't split the pulse: %w", err)
+	}
+
+	info := binary.BigEndian.AppendUint32(nil, uint32(len(label)))
+	info = append(info, label...)
+	values := make([][]byte, n)
+	for i := range values {
+		framed := binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(info, uint32(i)), uint32(n))
+		values[i], err = hkdf.Expand(sha512.New, prk, string(framed), SplitSize)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't split the pulse: %w", err)
+		}
+	}
+	return values, nil
+}
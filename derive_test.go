@@ -0,0 +1,99 @@
+package beacon
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	rec := fixtureRecord(t)
+
+	a, err := DeriveKey(rec, "app-a", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 32 {
+		t.Fatalf("expected 32 bytes, got %d", len(a))
+	}
+	again, _ := DeriveKey(rec, "app-a", 32)
+	if !bytes.Equal(a, again) {
+		t.Error("the same record and info derived different keys")
+	}
+	b, _ := DeriveKey(rec, "app-b", 32)
+	if bytes.Equal(a, b) {
+		t.Error("different info strings derived the same key")
+	}
+
+	if _, err := DeriveKey(rec, "app-a", 255*64+1); err == nil {
+		t.Error("expected an oversized key to fail")
+	}
+}
+
+func TestMix(t *testing.T) {
+	rec := fixtureRecord(t)
+	read := func(r io.Reader, err error) []byte {
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := make([]byte, 64)
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	local := bytes.Repeat([]byte{7}, 64)
+	a := read(Mix(rec, local))
+	if !bytes.Equal(a, read(Mix(rec, local))) {
+		t.Error("the same inputs produced different streams")
+	}
+	if bytes.Equal(a, read(Mix(rec, bytes.Repeat([]byte{8}, 64)))) {
+		t.Error("different local entropy produced the same stream")
+	}
+	if bytes.Equal(a, read(rec.Reader(), nil)) {
+		t.Error("the mixed stream matches the public one")
+	}
+	if _, err := Mix(rec, nil); err == nil {
+		t.Error("expected mixing without local entropy to fail")
+	}
+
+	if bytes.Equal(read(MixReader(rec)), read(MixReader(rec))) {
+		t.Error("MixReader returned the same stream twice")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	rec := fixtureRecord(t)
+	values, err := rec.Split(4, "ceremony")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 4 {
+		t.Fatalf("expected 4 values, got %d", len(values))
+	}
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if len(v) != SplitSize {
+			t.Fatalf("expected %d bytes, got %d", SplitSize, len(v))
+		}
+		if seen[string(v)] {
+			t.Error("two values are equal")
+		}
+		seen[string(v)] = true
+	}
+
+	again, _ := rec.Split(4, "ceremony")
+	if !bytes.Equal(values[2], again[2]) {
+		t.Error("splitting the same pulse twice gave different values")
+	}
+	// the values depend on the label and the count
+	other, _ := rec.Split(4, "ceremony2")
+	more, _ := rec.Split(5, "ceremony")
+	if seen[string(other[0])] || seen[string(more[0])] {
+		t.Error("values repeat across labels or counts")
+	}
+	if _, err := rec.Split(-1, "ceremony"); err == nil {
+		t.Error("expected a negative count to fail")
+	}
+}
@@ -0,0 +1,127 @@
+package drand
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// The signature schemes of drand chains, as named by the schemeID of their info. Chains whose info has no schemeID use SchemeChained.
+const (
+	// SchemeChained signs the previous signature and the round with a G1 group key, as the League of Entropy mainnet does
+	SchemeChained = "pedersen-bls-chained"
+	// SchemeUnchained signs the round alone with a G1 group key
+	SchemeUnchained = "pedersen-bls-unchained"
+	// SchemeUnchainedG1 signs the round alone on G1 with a G2 group key, hashing to G1 with the domain of G2
+	SchemeUnchainedG1 = "bls-unchained-on-g1"
+	// SchemeUnchainedG1RFC9380 is SchemeUnchainedG1 hashing to G1 as RFC 9380 specifies, as the quicknet chain does
+	SchemeUnchainedG1RFC9380 = "bls-unchained-g1-rfc9380"
+)
+
+// The domain separation tags of the hashes to the curve, as the schemes use them
+const (
+	dstG1 = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"
+	dstG2 = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"
+)
+
+// chained reports whether the chain's rounds sign the previous signature
+func (info Info) chained() bool {
+	return info.SchemeID == "" || info.SchemeID == SchemeChained
+}
+
+// message returns what the group signed for r: the SHA-256 of the previous signature, for chained schemes, followed by the round number
+func (info Info) message(r Round) ([]byte, error) {
+	h := sha256.New()
+	if info.chained() {
+		prev, err := hex.DecodeString(r.PreviousSignature)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't decode the round's previous signature: %w", err)
+		}
+		h.Write(prev)
+	}
+	h.Write(binary.BigEndian.AppendUint64(nil, r.Round))
+	return h.Sum(nil), nil
+}
+
+// verifyOnG2 checks a signature on G2 against a public key on G1
+func verifyOnG2(pub, sig, msg []byte, dst string) (bool, error) {
+	var pk bls12381.G1
+	if err := pk.SetBytes(pub); err != nil || pk.IsIdentity() {
+		return false, errors.New("Invalid group public key")
+	}
+	var s bls12381.G2
+	if err := s.SetBytes(sig); err != nil || s.IsIdentity() {
+		return false, nil
+	}
+	var h bls12381.G2
+	h.Hash(msg, []byte(dst))
+	// e(pk, H(msg)) = e(g1, sig)
+	e := bls12381.ProdPairFrac([]*bls12381.G1{&pk, bls12381.G1Generator()}, []*bls12381.G2{&h, &s}, []int{1, -1})
+	return e.IsIdentity(), nil
+}
+
+// verifyOnG1 checks a signature on G1 against a public key on G2
+func verifyOnG1(pub, sig, msg []byte, dst string) (bool, error) {
+	var pk bls12381.G2
+	if err := pk.SetBytes(pub); err != nil || pk.IsIdentity() {
+		return false, errors.New("Invalid group public key")
+	}
+	var s bls12381.G1
+	if err := s.SetBytes(sig); err != nil || s.IsIdentity() {
+		return false, nil
+	}
+	var h bls12381.G1
+	h.Hash(msg, []byte(dst))
+	// e(H(msg), pk) = e(sig, g2)
+	e := bls12381.ProdPairFrac([]*bls12381.G1{&h, &s}, []*bls12381.G2{&pk, bls12381.G2Generator()}, []int{1, -1})
+	return e.IsIdentity(), nil
+}
+
+// VerifyRound checks the BLS signature of r against the chain's group public key, and that its randomness, if set, is the SHA-256
+// of its signature. A wrong signature or randomness is reported as a beacon.ErrSignatureInvalid error.
+func (info Info) VerifyRound(r Round) error {
+	pub, err := hex.DecodeString(info.PublicKey)
+	if err != nil {
+		return fmt.Errorf("Couldn't decode the group public key: %w", err)
+	}
+	sig, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("Couldn't decode the round's signature: %w", err)
+	}
+	msg, err := info.message(r)
+	if err != nil {
+		return err
+	}
+
+	var ok bool
+	switch info.SchemeID {
+	case "", SchemeChained, SchemeUnchained:
+		ok, err = verifyOnG2(pub, sig, msg, dstG2)
+	case SchemeUnchainedG1:
+		ok, err = verifyOnG1(pub, sig, msg, dstG2)
+	case SchemeUnchainedG1RFC9380:
+		ok, err = verifyOnG1(pub, sig, msg, dstG1)
+	default:
+		return fmt.Errorf("Unsupported drand scheme %q", info.SchemeID)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return &beacon.Error{Kind: beacon.ErrSignatureInvalid, Err: fmt.Errorf("Invalid signature of round %d", r.Round)}
+	}
+
+	if r.Randomness != "" {
+		sum := sha256.Sum256(sig)
+		if !strings.EqualFold(r.Randomness, hex.EncodeToString(sum[:])) {
+			return &beacon.Error{Kind: beacon.ErrSignatureInvalid, Err: fmt.Errorf("The randomness of round %d isn't the hash of its signature", r.Round)}
+		}
+	}
+	return nil
+}
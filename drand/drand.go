@@ -0,0 +1,321 @@
+// Package drand adapts the drand randomness network (League of Entropy) to the beacon.BeaconSource interface
+package drand
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// DefaultBaseURL is the League of Entropy HTTP relay
+const DefaultBaseURL = "https://api.drand.sh"
+
+// DefaultChainHash identifies the League of Entropy mainnet chain
+const DefaultChainHash = "8990e7a9aaed2ffed73dbd7092123d6f289930540d7651336225dc172e51b2ce"
+
+var errBeforeGenesis = &beacon.Error{Kind: beacon.ErrNotFound, Err: errors.New("The time is before the drand chain's genesis")}
+
+// Info describes a drand chain
+type Info struct {
+	PublicKey   string `json:"public_key"`
+	Period      int    `json:"period"`
+	GenesisTime int64  `json:"genesis_time"`
+	Hash        string `json:"hash"`
+	GroupHash   string `json:"groupHash"`
+	SchemeID    string `json:"schemeID"`
+	Metadata    struct {
+		BeaconID string `json:"beaconID"`
+	} `json:"metadata"`
+}
+
+// Round is a drand beacon round as served by the HTTP API
+type Round struct {
+	Round             uint64 `json:"round"`
+	Randomness        string `json:"randomness"`
+	Signature         string `json:"signature"`
+	PreviousSignature string `json:"previous_signature"`
+}
+
+// Client fetches rounds of a drand chain, verifying their signature against the chain's group key. It is safe for concurrent use.
+type Client struct {
+	baseURL   string
+	chainHash string
+	publicKey string
+	http      *http.Client
+	noVerify  bool
+
+	mu   sync.Mutex
+	info *Info
+}
+
+var _ beacon.BeaconSource = (*Client)(nil)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL points the client at another drand relay
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = url
+	}
+}
+
+// WithChainHash selects the drand chain to follow
+func WithChainHash(hash string) Option {
+	return func(c *Client) {
+		c.chainHash = hash
+	}
+}
+
+// WithPublicKey pins the hex encoded group public key of the chain, rejecting chain info with another one
+func WithPublicKey(key string) Option {
+	return func(c *Client) {
+		c.publicKey = key
+	}
+}
+
+// WithHTTPClient makes the client use cli for all requests
+func WithHTTPClient(cli *http.Client) Option {
+	return func(c *Client) {
+		c.http = cli
+	}
+}
+
+// WithoutVerification makes the client trust the rounds served by the relay instead of verifying their signature
+func WithoutVerification() Option {
+	return func(c *Client) {
+		c.noVerify = true
+	}
+}
+
+// NewClient returns a client for the League of Entropy mainnet configured with the given options
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:   DefaultBaseURL,
+		chainHash: DefaultChainHash,
+		http:      &http.Client{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	url := c.baseURL + "/" + c.chainHash + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("Couldn't build the drand request: %w", err)
+	}
+	r, err := c.http.Do(req)
+	if err != nil {
+		return &beacon.Error{Kind: beacon.ErrUnavailable, URL: url, Err: err}
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		kind := beacon.ErrUnavailable
+		if r.StatusCode == http.StatusNotFound {
+			kind = beacon.ErrNotFound
+		}
+		return &beacon.Error{Kind: kind, StatusCode: r.StatusCode, URL: url, Err: beacon.NewResponseError(r, url)}
+	}
+	return beacon.DecodeResponse(r, url, 0, v)
+}
+
+// ChainHash computes the hash identifying the chain from its parameters, as drand does: the SHA-256 hash of the period, the genesis
+// time, the group public key and group hash, followed by the scheme and beacon ids unless they are the defaults
+func (info Info) ChainHash() (string, error) {
+	pk, err := hex.DecodeString(info.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't decode the drand chain's public key: %w", err)
+	}
+	group, err := hex.DecodeString(info.GroupHash)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't decode the drand chain's group hash: %w", err)
+	}
+	h := sha256.New()
+	binary.Write(h, binary.BigEndian, uint32(info.Period))
+	binary.Write(h, binary.BigEndian, info.GenesisTime)
+	h.Write(pk)
+	h.Write(group)
+	if info.SchemeID != "" && info.SchemeID != SchemeChained {
+		h.Write([]byte(info.SchemeID))
+	}
+	if id := info.Metadata.BeaconID; id != "" && id != "default" {
+		h.Write([]byte(id))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Info returns the parameters of the chain, fetching them once. They are rejected unless they hash to the client's chain hash, so the
+// group public key against which rounds are verified is that of the chain rather than one trusted from the relay.
+func (c *Client) Info(ctx context.Context) (Info, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.info != nil {
+		return *c.info, nil
+	}
+
+	var info Info
+	if err := c.get(ctx, "/info", &info); err != nil {
+		return info, err
+	}
+	if info.Period <= 0 {
+		return info, errors.New("The drand chain info has no period")
+	}
+	hash, err := info.ChainHash()
+	if err != nil {
+		return info, err
+	}
+	if !strings.EqualFold(hash, c.chainHash) || info.Hash != "" && !strings.EqualFold(info.Hash, hash) {
+		return info, errors.New("The drand chain info is that of another chain: " + hash)
+	}
+	if c.publicKey != "" && !strings.EqualFold(info.PublicKey, c.publicKey) {
+		return info, errors.New("The drand chain info has another public key than the pinned one: " + info.PublicKey)
+	}
+	c.info = &info
+	return info, nil
+}
+
+// Round fetches a round of the chain, 0 being the latest one, and verifies it unless the client was created WithoutVerification
+func (c *Client) Round(ctx context.Context, round uint64) (Round, error) {
+	path := "/public/latest"
+	if round > 0 {
+		path = "/public/" + strconv.FormatUint(round, 10)
+	}
+	var r Round
+	if err := c.get(ctx, path, &r); err != nil {
+		return Round{}, err
+	}
+	if c.noVerify {
+		return r, nil
+	}
+	info, err := c.Info(ctx)
+	if err != nil {
+		return Round{}, err
+	}
+	if err := info.VerifyRound(r); err != nil {
+		return Round{}, err
+	}
+	return r, nil
+}
+
+// RoundAt returns the number of the latest round emitted at or before t, 0 if t is before the genesis
+func (info Info) RoundAt(t time.Time) uint64 {
+	if t.Unix() < info.GenesisTime {
+		return 0
+	}
+	return uint64(t.Unix()-info.GenesisTime)/uint64(info.Period) + 1
+}
+
+// RoundTime returns the time at which round is emitted
+func (info Info) RoundTime(round uint64) time.Time {
+	return time.Unix(info.GenesisTime+int64(round-1)*int64(info.Period), 0).UTC()
+}
+
+// ToRecord maps a round into a beacon record. The 512-bit output value is the SHA-512 hash of the round's signature,
+// the previous signature is kept as the "previous" list value and the signature as the signature value.
+func (info Info) ToRecord(r Round) (beacon.Record, error) {
+	sig, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return beacon.Record{}, fmt.Errorf("Couldn't decode the round's signature: %w", err)
+	}
+	out := sha512.Sum512(sig)
+
+	var rec beacon.Record
+	p := &rec.Pulse
+	p.URI = info.Hash + "/public/" + strconv.FormatUint(r.Round, 10)
+	p.Version = "drand"
+	p.Period = info.Period * 1000
+	p.PulseIndex = int(r.Round)
+	p.TimeStamp = info.RoundTime(r.Round)
+	p.SignatureValue = strings.ToUpper(r.Signature)
+	p.OutputValue = strings.ToUpper(hex.EncodeToString(out[:]))
+	if r.PreviousSignature != "" {
+		p.ListValues = append(p.ListValues, beacon.ListValue{Type: "previous", Value: strings.ToUpper(r.PreviousSignature)})
+	}
+	return rec, nil
+}
+
+func (c *Client) record(ctx context.Context, round uint64) (beacon.Record, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	r, err := c.Round(ctx, round)
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	return info.ToRecord(r)
+}
+
+// Verify checks the signature of a record mapped from a round of the client's chain by ToRecord
+func (c *Client) Verify(ctx context.Context, rec beacon.Record) error {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return err
+	}
+	if rec.Pulse.PulseIndex <= 0 {
+		return errors.New("The record isn't that of a drand round")
+	}
+	return info.VerifyRound(Round{
+		Round:             uint64(rec.Pulse.PulseIndex),
+		Signature:         rec.Pulse.SignatureValue,
+		PreviousSignature: rec.PreviousOutputValue(),
+	})
+}
+
+// Last returns the latest round
+func (c *Client) Last(ctx context.Context) (beacon.Record, error) {
+	return c.record(ctx, 0)
+}
+
+// At returns the latest round emitted at or before t
+func (c *Client) At(ctx context.Context, t time.Time) (beacon.Record, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	round := info.RoundAt(t)
+	if round == 0 {
+		return beacon.Record{}, errBeforeGenesis
+	}
+	return c.record(ctx, round)
+}
+
+// Next returns the first round emitted after t
+func (c *Client) Next(ctx context.Context, t time.Time) (beacon.Record, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	return c.record(ctx, info.RoundAt(t)+1)
+}
+
+// Previous returns the last round emitted before t
+func (c *Client) Previous(ctx context.Context, t time.Time) (beacon.Record, error) {
+	info, err := c.Info(ctx)
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	round := info.RoundAt(t)
+	if round > 0 && !info.RoundTime(round).Before(t) {
+		round--
+	}
+	if round == 0 {
+		return beacon.Record{}, errBeforeGenesis
+	}
+	return c.record(ctx, round)
+}
@@ -0,0 +1,298 @@
+package drand
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudflare/circl/ecc/bls12381"
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// testChain signs the rounds of a chain with a random group key
+type testChain struct {
+	info Info
+	sk   bls12381.Scalar
+
+	mu sync.Mutex
+	// sigs[i] is the hex encoded signature of round i, sigs[0] the seed of chained schemes
+	sigs []string
+}
+
+func newTestChain(t *testing.T, info Info) *testChain {
+	ch := &testChain{info: info, sigs: []string{"ef01"}}
+	if err := ch.sk.Random(rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if ch.onG1() {
+		var pk bls12381.G2
+		pk.ScalarMult(&ch.sk, bls12381.G2Generator())
+		ch.info.PublicKey = hex.EncodeToString(pk.BytesCompressed())
+	} else {
+		var pk bls12381.G1
+		pk.ScalarMult(&ch.sk, bls12381.G1Generator())
+		ch.info.PublicKey = hex.EncodeToString(pk.BytesCompressed())
+	}
+	ch.info.Hash, _ = ch.info.ChainHash()
+	return ch
+}
+
+func (ch *testChain) onG1() bool {
+	return ch.info.SchemeID == SchemeUnchainedG1 || ch.info.SchemeID == SchemeUnchainedG1RFC9380
+}
+
+func (ch *testChain) sign(msg []byte) string {
+	if ch.onG1() {
+		dst := dstG1
+		if ch.info.SchemeID == SchemeUnchainedG1 {
+			dst = dstG2
+		}
+		var h bls12381.G1
+		h.Hash(msg, []byte(dst))
+		h.ScalarMult(&ch.sk, &h)
+		return hex.EncodeToString(h.BytesCompressed())
+	}
+	var h bls12381.G2
+	h.Hash(msg, []byte(dstG2))
+	h.ScalarMult(&ch.sk, &h)
+	return hex.EncodeToString(h.BytesCompressed())
+}
+
+// round returns round n as the relay serves it
+func (ch *testChain) round(n uint64) Round {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	for i := uint64(len(ch.sigs)); i <= n; i++ {
+		msg, _ := ch.info.message(Round{Round: i, PreviousSignature: ch.sigs[i-1]})
+		ch.sigs = append(ch.sigs, ch.sign(msg))
+	}
+	sig, _ := hex.DecodeString(ch.sigs[n])
+	sum := sha256.Sum256(sig)
+	r := Round{Round: n, Randomness: hex.EncodeToString(sum[:]), Signature: ch.sigs[n]}
+	if ch.info.chained() {
+		r.PreviousSignature = ch.sigs[n-1]
+	}
+	return r
+}
+
+func testServer(t *testing.T, ch *testChain) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// whatever the chain hash, so that tests can serve the info of another chain
+		_, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		path = "/" + path
+		switch {
+		case path == "/info":
+			json.NewEncoder(w).Encode(ch.info)
+		case strings.HasPrefix(path, "/public/"):
+			round := strings.TrimPrefix(path, "/public/")
+			if round == "latest" {
+				round = "100"
+			}
+			n, err := strconv.ParseUint(round, 10, 64)
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(ch.round(n))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	info := Info{Period: 30, GenesisTime: 1595431050}
+	ch := newTestChain(t, info)
+	srv := testServer(t, ch)
+	c := NewClient(WithBaseURL(srv.URL), WithChainHash(ch.info.Hash))
+	ctx := context.Background()
+
+	rec, err := c.Last(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex != 100 || !rec.Pulse.TimeStamp.Equal(info.RoundTime(100)) {
+		t.Errorf("unexpected round %d at %s", rec.Pulse.PulseIndex, rec.Pulse.TimeStamp)
+	}
+	if rec.PreviousOutputValue() != strings.ToUpper(ch.round(99).Signature) || len(rec.Pulse.OutputValue) != 128 {
+		t.Error("the round wasn't mapped into the record")
+	}
+
+	at := info.RoundTime(10)
+	if rec, err := c.At(ctx, at); err != nil || rec.Pulse.PulseIndex != 10 {
+		t.Errorf("At: got round %d, %v", rec.Pulse.PulseIndex, err)
+	}
+	if rec, err := c.Next(ctx, at); err != nil || rec.Pulse.PulseIndex != 11 {
+		t.Errorf("Next: got round %d, %v", rec.Pulse.PulseIndex, err)
+	}
+	if rec, err := c.Previous(ctx, at); err != nil || rec.Pulse.PulseIndex != 9 {
+		t.Errorf("Previous: got round %d, %v", rec.Pulse.PulseIndex, err)
+	}
+	if _, err := c.At(ctx, time.Unix(0, 0)); err == nil {
+		t.Error("expected a time before the genesis to fail")
+	}
+}
+
+func TestVerifyRound(t *testing.T) {
+	for _, scheme := range []string{"", SchemeChained, SchemeUnchained, SchemeUnchainedG1, SchemeUnchainedG1RFC9380} {
+		t.Run(scheme, func(t *testing.T) {
+			ch := newTestChain(t, Info{Period: 3, SchemeID: scheme})
+			r := ch.round(5)
+			if err := ch.info.VerifyRound(r); err != nil {
+				t.Fatal(err)
+			}
+
+			for name, tampered := range map[string]Round{
+				"round":      {Round: 6, Randomness: r.Randomness, Signature: r.Signature, PreviousSignature: r.PreviousSignature},
+				"signature":  {Round: 5, Signature: ch.round(6).Signature, PreviousSignature: r.PreviousSignature},
+				"randomness": {Round: 5, Randomness: ch.round(6).Randomness, Signature: r.Signature, PreviousSignature: r.PreviousSignature},
+			} {
+				if err := ch.info.VerifyRound(tampered); !errors.Is(err, beacon.ErrSignatureInvalid) {
+					t.Errorf("expected a wrong %s to be an ErrSignatureInvalid, got %v", name, err)
+				}
+			}
+			if ch.info.chained() {
+				tampered := r
+				tampered.PreviousSignature = ch.round(3).Signature
+				if err := ch.info.VerifyRound(tampered); !errors.Is(err, beacon.ErrSignatureInvalid) {
+					t.Errorf("expected a wrong previous signature to be an ErrSignatureInvalid, got %v", err)
+				}
+			}
+
+			other := newTestChain(t, ch.info)
+			if err := other.info.VerifyRound(r); !errors.Is(err, beacon.ErrSignatureInvalid) {
+				t.Errorf("expected the round to fail against another key, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClientVerification(t *testing.T) {
+	info := Info{Period: 30, GenesisTime: 1595431050}
+	ch := newTestChain(t, info)
+	srv := testServer(t, ch)
+	ctx := context.Background()
+
+	c := NewClient(WithBaseURL(srv.URL), WithChainHash(ch.info.Hash))
+	rec, err := c.Last(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(ctx, rec); err != nil {
+		t.Error(err)
+	}
+	rec.Pulse.SignatureValue = strings.ToUpper(ch.round(42).Signature)
+	if err := c.Verify(ctx, rec); !errors.Is(err, beacon.ErrSignatureInvalid) {
+		t.Errorf("expected a tampered record to fail verification, got %v", err)
+	}
+
+	// a relay claiming the chain's key but serving rounds signed with another one
+	forged := newTestChain(t, ch.info)
+	forged.info = ch.info
+	forger := testServer(t, forged)
+	c = NewClient(WithBaseURL(forger.URL), WithChainHash(ch.info.Hash))
+	if _, err := c.Last(ctx); !errors.Is(err, beacon.ErrSignatureInvalid) {
+		t.Errorf("expected forged rounds to be rejected, got %v", err)
+	}
+	c = NewClient(WithBaseURL(forger.URL), WithChainHash(ch.info.Hash), WithoutVerification())
+	if _, err := c.Last(ctx); err != nil {
+		t.Errorf("expected unverified rounds to be accepted, got %v", err)
+	}
+
+	other := testServer(t, newTestChain(t, Info{Period: 30, GenesisTime: 1595431050}))
+	c = NewClient(WithBaseURL(other.URL), WithChainHash(ch.info.Hash))
+	if _, err := c.Last(ctx); err == nil || !strings.Contains(err.Error(), "another chain") {
+		t.Error("expected the info of another chain to be rejected")
+	}
+
+	// a relay serving the chain's hash along with another key
+	liar := newTestChain(t, info)
+	liar.info.Hash = ch.info.Hash
+	c = NewClient(WithBaseURL(testServer(t, liar).URL), WithChainHash(ch.info.Hash))
+	if _, err := c.Last(ctx); err == nil || !strings.Contains(err.Error(), "another chain") {
+		t.Errorf("expected a key not hashing to the chain hash to be rejected, got %v", err)
+	}
+	c = NewClient(WithBaseURL(srv.URL), WithChainHash(ch.info.Hash), WithPublicKey(liar.info.PublicKey))
+	if _, err := c.Last(ctx); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("expected a key other than the pinned one to be rejected, got %v", err)
+	}
+	c = NewClient(WithBaseURL(srv.URL), WithChainHash(ch.info.Hash), WithPublicKey(strings.ToUpper(ch.info.PublicKey)))
+	if _, err := c.Last(ctx); err != nil {
+		t.Errorf("expected the pinned key to be accepted, got %v", err)
+	}
+}
+
+func TestChainHash(t *testing.T) {
+	// the info of the League of Entropy mainnet, as served by its relays
+	mainnet := Info{
+		PublicKey:   "868f005eb8e6e4ca0a47c8a77ceaa5309a47978a7c71bc5cce96366b5d7a569937c529eeda66c7293784a9402801af31",
+		Period:      30,
+		GenesisTime: 1595431050,
+		GroupHash:   "176f93498eac9ca337150b46d21dd58673ea4e3581185f869672e59fa4cb390a",
+		SchemeID:    SchemeChained,
+	}
+	mainnet.Metadata.BeaconID = "default"
+	if hash, err := mainnet.ChainHash(); err != nil || hash != DefaultChainHash {
+		t.Errorf("expected the mainnet info to hash to %s, got %s: %v", DefaultChainHash, hash, err)
+	}
+
+	// the scheme and beacon ids are hashed unless they are the defaults
+	unchained := mainnet
+	unchained.SchemeID = SchemeUnchained
+	named := mainnet
+	named.Metadata.BeaconID = "fastnet"
+	for _, info := range []Info{unchained, named} {
+		if hash, err := info.ChainHash(); err != nil || hash == DefaultChainHash {
+			t.Errorf("expected the info of %s/%s to hash to another chain: %v", info.SchemeID, info.Metadata.BeaconID, err)
+		}
+	}
+	mainnet.PublicKey = "zz"
+	if _, err := mainnet.ChainHash(); err == nil {
+		t.Error("expected an invalid public key to fail")
+	}
+}
+
+func TestPublishedChains(t *testing.T) {
+	paths, err := filepath.Glob("testdata/*.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no chain in testdata: %v", err)
+	}
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var chain struct {
+			Info   Info    `json:"info"`
+			Rounds []Round `json:"rounds"`
+		}
+		if err := json.Unmarshal(buf, &chain); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if hash, err := chain.Info.ChainHash(); err != nil || hash != chain.Info.Hash {
+			t.Errorf("%s: expected the info to hash to %s, got %s: %v", path, chain.Info.Hash, hash, err)
+		}
+		for _, r := range chain.Rounds {
+			if err := chain.Info.VerifyRound(r); err != nil {
+				t.Errorf("%s: round %d: %v", path, r.Round, err)
+			}
+			if _, err := chain.Info.ToRecord(r); err != nil {
+				t.Errorf("%s: round %d: %v", path, r.Round, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package draw runs verifiable lotteries: entrants are shuffled with a Fisher-Yates shuffle seeded from a beacon pulse, and every draw
+// comes with a proof that anyone holding the entrant list and the pulse can re-run to confirm the outcome
+package draw
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// Algorithm identifies the shuffle in proofs: a Fisher-Yates shuffle drawing big-endian uint64s from the SHAKE256 stream of the
+// pulse's output value (see beacon.Record.Reader), rejecting values that would bias the modulo
+const Algorithm = "fisher-yates-shake256-v1"
+
+// Pulse identifies the beacon pulse a draw was seeded from
+type Pulse struct {
+	URI         string    `json:"uri"`
+	ChainIndex  int       `json:"chainIndex"`
+	PulseIndex  int       `json:"pulseIndex"`
+	TimeStamp   time.Time `json:"timeStamp"`
+	OutputValue string    `json:"outputValue"`
+}
+
+// Proof is the machine-readable record of a draw. It marshals to JSON for publication.
+type Proof struct {
+	Algorithm    string   `json:"algorithm"`
+	EntrantsHash string   `json:"entrantsHash"`
+	Entrants     int      `json:"entrants"`
+	Pulse        Pulse    `json:"pulse"`
+	Winners      int      `json:"winners"`
+	Result       []string `json:"result"`
+}
+
+// HashEntrants returns the hex SHA-512 of the entrant list, each entrant prefixed by its length as a big-endian uint32.
+// The order of the list matters: publish it before the pulse is emitted.
+func HashEntrants(entrants []string) string {
+	h := sha512.New()
+	var n [4]byte
+	for _, e := range entrants {
+		binary.BigEndian.PutUint32(n[:], uint32(len(e)))
+		h.Write(n[:])
+		io.WriteString(h, e)
+	}
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+// uniform reads an unbiased integer in [0, n) from r
+func uniform(r io.Reader, n uint64) (uint64, error) {
+	limit := ^uint64(0) - ^uint64(0)%n
+	var buf [8]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, err
+		}
+		if v := binary.BigEndian.Uint64(buf[:]); v < limit {
+			return v % n, nil
+		}
+	}
+}
+
+// shuffle returns a copy of entrants shuffled from the record's output value
+func shuffle[T any](rec beacon.Record, entrants []T) ([]T, error) {
+	out := slices.Clone(entrants)
+	r := rec.Reader()
+	for i := len(out) - 1; i > 0; i-- {
+		j, err := uniform(r, uint64(i+1))
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read the pulse's random stream: %w", err)
+		}
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+// Shuffle shuffles entrants from the pulse rec, the proof's result holds the whole shuffled list
+func Shuffle(rec beacon.Record, entrants []string) (*Proof, error) {
+	return Winners(rec, entrants, len(entrants))
+}
+
+// Winners selects n distinct winners among entrants from the pulse rec, in the order they were drawn
+func Winners(rec beacon.Record, entrants []string, n int) (*Proof, error) {
+	if n < 0 || n > len(entrants) {
+		return nil, errors.New(fmt.Sprintf("Can't draw %d winners among %d entrants", n, len(entrants)))
+	}
+	shuffled, err := shuffle(rec, entrants)
+	if err != nil {
+		return nil, err
+	}
+	// the tail of a Fisher-Yates shuffle is settled first, so winners are read from the end
+	result := make([]string, n)
+	for i := range result {
+		result[i] = shuffled[len(shuffled)-1-i]
+	}
+	return &Proof{
+		Algorithm:    Algorithm,
+		EntrantsHash: HashEntrants(entrants),
+		Entrants:     len(entrants),
+		Pulse: Pulse{
+			URI:         rec.Pulse.URI,
+			ChainIndex:  rec.Pulse.ChainIndex,
+			PulseIndex:  rec.Pulse.PulseIndex,
+			TimeStamp:   rec.Pulse.TimeStamp,
+			OutputValue: rec.Pulse.OutputValue,
+		},
+		Winners: n,
+		Result:  result,
+	}, nil
+}
+
+// Verify re-runs the draw described by the proof from the published entrant list and the pulse, and checks it has the same outcome.
+// It doesn't verify the pulse's signature, use beacon.Verify or beacon.Client.Verify for that.
+func (p *Proof) Verify(rec beacon.Record, entrants []string) error {
+	if p.Algorithm != Algorithm {
+		return errors.New("Unsupported draw algorithm: " + p.Algorithm)
+	}
+	if !strings.EqualFold(p.EntrantsHash, HashEntrants(entrants)) || p.Entrants != len(entrants) {
+		return errors.New("The entrant list doesn't match the proof")
+	}
+	if rec.Pulse.ChainIndex != p.Pulse.ChainIndex || rec.Pulse.PulseIndex != p.Pulse.PulseIndex ||
+		!rec.Pulse.TimeStamp.Equal(p.Pulse.TimeStamp) || !strings.EqualFold(rec.Pulse.OutputValue, p.Pulse.OutputValue) {
+		return errors.New("The pulse doesn't match the proof")
+	}
+	want, err := Winners(rec, entrants, p.Winners)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(want.Result, p.Result) {
+		return errors.New("The draw's result doesn't match the proof")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package draw
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestDraw(t *testing.T) {
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	rec, _ := srv.Record(10)
+	other, _ := srv.Record(11)
+
+	entrants := make([]string, 50)
+	for i := range entrants {
+		entrants[i] = fmt.Sprintf("entrant-%02d", i)
+	}
+
+	shuffled, err := Shuffle(rec, entrants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted := slices.Clone(shuffled.Result)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, entrants) {
+		t.Fatal("the shuffle isn't a permutation of the entrants")
+	}
+	if slices.Equal(shuffled.Result, entrants) {
+		t.Error("the shuffle left the entrants in order")
+	}
+
+	proof, err := Winners(rec, entrants, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof.Result) != 3 {
+		t.Fatalf("expected 3 winners, got %d", len(proof.Result))
+	}
+	again, _ := Winners(rec, entrants, 3)
+	if !slices.Equal(proof.Result, again.Result) {
+		t.Error("the draw isn't deterministic")
+	}
+
+	// the proof survives a JSON round trip
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var published Proof
+	if err := json.Unmarshal(data, &published); err != nil {
+		t.Fatal(err)
+	}
+	if err := published.Verify(rec, entrants); err != nil {
+		t.Error(err)
+	}
+
+	if err := published.Verify(other, entrants); err == nil {
+		t.Error("expected a different pulse to fail verification")
+	}
+	if err := published.Verify(rec, entrants[1:]); err == nil {
+		t.Error("expected a different entrant list to fail verification")
+	}
+	published.Result[0], published.Result[1] = published.Result[1], published.Result[0]
+	if err := published.Verify(rec, entrants); err == nil {
+		t.Error("expected a tampered result to fail verification")
+	}
+
+	if _, err := Winners(rec, entrants, 51); err == nil {
+		t.Error("expected drawing more winners than entrants to fail")
+	}
+}
@@ -0,0 +1,156 @@
+package draw
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// ReportVersion is the version of the audit report layout
+const ReportVersion = 1
+
+// Report is a signed audit report of a draw, to be published alongside its results. Along with the outcome it carries the proof bundle
+// of the pulse, so it can be checked without access to the beacon, and it is signed by the organizer of the draw with an Ed25519 key.
+// It marshals to JSON; the public key and signature are base64 encoded.
+type Report struct {
+	Version      int    `json:"version"`
+	Algorithm    string `json:"algorithm"`
+	EntrantsHash string `json:"entrantsHash"`
+	Entrants     int    `json:"entrants"`
+	Winners      int    `json:"winners"`
+	// WinnerIndices are the positions of the winners in the entrant list, in the order they were drawn
+	WinnerIndices []int          `json:"winnerIndices"`
+	Result        []string       `json:"result"`
+	Bundle        *beacon.Bundle `json:"bundle"`
+	PublicKey     string         `json:"publicKey"`
+	Signature     string         `json:"signature"`
+}
+
+// winnerIndices returns the positions of the n winners drawn among count entrants, in the order they were drawn
+func winnerIndices(rec beacon.Record, count, n int) ([]int, error) {
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = i
+	}
+	shuffled, err := shuffle(rec, indices)
+	if err != nil {
+		return nil, err
+	}
+	winners := make([]int, n)
+	for i := range winners {
+		winners[i] = shuffled[len(shuffled)-1-i]
+	}
+	return winners, nil
+}
+
+// NewReport draws n winners among entrants from the pulse of the bundle b, as Winners does, and returns the report of the draw signed with key.
+// The bundle is verified first.
+func NewReport(b *beacon.Bundle, entrants []string, n int, key ed25519.PrivateKey) (*Report, error) {
+	if err := beacon.VerifyBundle(b); err != nil {
+		return nil, err
+	}
+	proof, err := Winners(b.Record, entrants, n)
+	if err != nil {
+		return nil, err
+	}
+	indices, err := winnerIndices(b.Record, len(entrants), n)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{
+		Version:       ReportVersion,
+		Algorithm:     proof.Algorithm,
+		EntrantsHash:  proof.EntrantsHash,
+		Entrants:      proof.Entrants,
+		Winners:       proof.Winners,
+		WinnerIndices: indices,
+		Result:        proof.Result,
+		Bundle:        b,
+		PublicKey:     base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+	}
+	msg, err := r.signedBytes()
+	if err != nil {
+		return nil, err
+	}
+	r.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, msg))
+	return r, nil
+}
+
+// signedBytes returns what the report's signature covers: its JSON encoding without the signature
+func (r *Report) signedBytes() ([]byte, error) {
+	unsigned := *r
+	unsigned.Signature = ""
+	buf, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't encode the report: %w", err)
+	}
+	return buf, nil
+}
+
+// Verify checks the report's signature, its proof bundle, and re-runs the draw from the published entrant list to confirm its outcome.
+// The signature only proves the report wasn't altered since it was signed by the holder of its public key: auditors must compare the key
+// with the organizer's, and the bundle's certificate with the beacon's.
+func (r *Report) Verify(entrants []string) error {
+	if r.Version != ReportVersion {
+		return errors.New(fmt.Sprintf("Unsupported report version: %d", r.Version))
+	}
+	pub, err := base64.StdEncoding.DecodeString(r.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return errors.New("The report's public key is malformed")
+	}
+	sig, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("Couldn't decode the report's signature: %w", err)
+	}
+	msg, err := r.signedBytes()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, msg, sig) {
+		return errors.New("The report's signature is invalid")
+	}
+
+	if r.Bundle == nil {
+		return errors.New("The report has no proof bundle")
+	}
+	if err := beacon.VerifyBundle(r.Bundle); err != nil {
+		return err
+	}
+	rec := r.Bundle.Record
+	proof := &Proof{
+		Algorithm:    r.Algorithm,
+		EntrantsHash: r.EntrantsHash,
+		Entrants:     r.Entrants,
+		Pulse: Pulse{
+			URI:         rec.Pulse.URI,
+			ChainIndex:  rec.Pulse.ChainIndex,
+			PulseIndex:  rec.Pulse.PulseIndex,
+			TimeStamp:   rec.Pulse.TimeStamp,
+			OutputValue: rec.Pulse.OutputValue,
+		},
+		Winners: r.Winners,
+		Result:  r.Result,
+	}
+	if err := proof.Verify(rec, entrants); err != nil {
+		return err
+	}
+	indices, err := winnerIndices(rec, len(entrants), r.Winners)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(indices, r.WinnerIndices) {
+		return errors.New("The report's winner indices don't match the draw")
+	}
+	for i, idx := range indices {
+		if entrants[idx] != r.Result[i] {
+			return errors.New("The report's winners don't match their indices")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package draw
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestReport(t *testing.T) {
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	rec, _ := srv.Record(10)
+	bundle, err := srv.Client().ProofBundle(context.Background(), rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entrants := make([]string, 20)
+	for i := range entrants {
+		entrants[i] = fmt.Sprintf("entrant-%02d", i)
+	}
+	report, err := NewReport(bundle, entrants, 3, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, idx := range report.WinnerIndices {
+		if entrants[idx] != report.Result[i] {
+			t.Errorf("winner %d isn't the entrant at index %d", i, idx)
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var published Report
+	if err := json.Unmarshal(data, &published); err != nil {
+		t.Fatal(err)
+	}
+	if err := published.Verify(entrants); err != nil {
+		t.Fatal(err)
+	}
+
+	// another entrant than the first winner, whichever the pulse drew
+	other := entrants[0]
+	if other == published.Result[0] {
+		other = entrants[1]
+	}
+	tampered := published
+	tampered.Result = append([]string{other}, published.Result[1:]...)
+	if err := tampered.Verify(entrants); err == nil {
+		t.Error("expected an altered report to fail")
+	}
+	if err := published.Verify(entrants[1:]); err == nil {
+		t.Error("expected another entrant list to fail")
+	}
+}
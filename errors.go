@@ -0,0 +1,135 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Kinds of errors reported by the package, to be tested with errors.Is
+var (
+	// ErrStale reports that the latest record is older than the staleness threshold
+	ErrStale = errors.New("Beacon is stale")
+	// ErrSignatureInvalid reports a record that failed signature or hash verification
+	ErrSignatureInvalid = errors.New("Invalid signature")
+	// ErrNotFound reports that the beacon has no record for the request
+	ErrNotFound = errors.New("Record not found")
+	// ErrMalformedResponse reports a response that couldn't be decoded
+	ErrMalformedResponse = errors.New("Malformed response")
+	// ErrUnavailable reports that the beacon couldn't be reached or failed to serve the request
+	ErrUnavailable = errors.New("Beacon unavailable")
+	// ErrClockSkew reports that the local clock disagrees with the beacon's
+	ErrClockSkew = errors.New("Local clock skewed")
+	// ErrRateLimited reports that the beacon rejected the request with 429 Too Many Requests. It also matches ErrUnavailable.
+	ErrRateLimited = errors.New("Beacon rate limited")
+)
+
+// Error describes a failed beacon request. errors.Is matches it against its Kind, and errors.As can extract it to inspect the HTTP status and URL.
+type Error struct {
+	// Kind is one of the package's Err values
+	Kind error
+	// StatusCode is the HTTP status of the response, 0 if none was received
+	StatusCode int
+	URL        string
+	// RetryAfter is how long the beacon asked the client to wait before retrying, as its Retry-After header tells, 0 if it didn't
+	RetryAfter time.Duration
+	// Err is the underlying cause, if any
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := e.Kind.Error()
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (HTTP %d)", e.StatusCode)
+	}
+	if e.URL != "" {
+		msg += " for " + e.URL
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind || (e.Kind == ErrRateLimited && target == ErrUnavailable)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// maxErrorBody bounds the snippet of the body a ResponseError keeps
+const maxErrorBody = 512
+
+// ResponseError is the cause of the *Error returned for a response whose status isn't 200 OK, telling what the server answered.
+// errors.As extracts it, so callers can tell a pulse that isn't published yet (404) from server trouble (5xx).
+type ResponseError struct {
+	StatusCode int
+	URL        string
+	// Body is the start of the response body, at most 512 bytes, which usually holds the server's error message
+	Body string
+	// Retryable reports whether retrying it later may succeed. For the errors of a Client, it tells whether the client's retry
+	// policy, or DefaultRetryPolicy for a client created without WithRetry, retries the status.
+	Retryable bool
+}
+
+// NewResponseError returns the ResponseError of r, fetched from url, reading the start of its body. It is meant for the adapters of
+// other beacons, like DecodeResponse, which set Retryable according to how they retry.
+func NewResponseError(r *http.Response, url string) *ResponseError {
+	e := &ResponseError{StatusCode: r.StatusCode, URL: url}
+	if rc, err := decompress(r); err == nil {
+		buf, _ := io.ReadAll(io.LimitReader(rc, maxErrorBody))
+		rc.Close()
+		e.Body = strings.ToValidUTF8(strings.TrimSpace(string(buf)), "\uFFFD")
+	}
+	return e
+}
+
+func (e *ResponseError) Error() string {
+	msg := http.StatusText(e.StatusCode)
+	if msg == "" {
+		msg = fmt.Sprintf("Status %d", e.StatusCode)
+	}
+	if e.Body != "" {
+		msg += fmt.Sprintf(", the server answered %q", e.Body)
+	}
+	return msg
+}
+
+// statusError returns the error for a response with an unexpected HTTP status: ErrNotFound for 404, ErrRateLimited for 429,
+// ErrUnavailable otherwise
+func statusError(code int, url string) *Error {
+	kind := ErrUnavailable
+	switch code {
+	case http.StatusNotFound:
+		kind = ErrNotFound
+	case http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	}
+	return &Error{Kind: kind, StatusCode: code, URL: url}
+}
+
+// statusCode returns the HTTP status carried by err, 0 if there is none
+func statusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// retryAfter returns how long the beacon asked to wait before retrying the request that failed with err, 0 if it didn't
+func retryAfter(err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package beacon
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	var status int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte("{"))
+	}))
+	defer srv.Close()
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+
+	for _, tc := range []struct {
+		status int
+		kind   error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusServiceUnavailable, ErrUnavailable},
+		{http.StatusOK, ErrMalformedResponse},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	} {
+		status = tc.status
+		_, err := c.NextRecord(context.Background(), time.Now())
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("status %d: expected %v, got %v", tc.status, tc.kind, err)
+		}
+		var e *Error
+		if !errors.As(err, &e) || e.URL == "" {
+			t.Errorf("status %d: expected an *Error carrying the URL, got %v", tc.status, err)
+			continue
+		}
+		if tc.status != http.StatusOK && e.StatusCode != tc.status {
+			t.Errorf("expected status %d, got %d", tc.status, e.StatusCode)
+		}
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	var status int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(strings.Repeat("x", status+100)))
+	}))
+	defer srv.Close()
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+	// a client retrying other statuses tells them retryable instead
+	custom := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRetry(RetryPolicy{MaxAttempts: 1, RetryableStatus: []int{http.StatusHTTPVersionNotSupported}}))
+
+	for _, tc := range []struct {
+		c         *Client
+		status    int
+		retryable bool
+	}{
+		{c, http.StatusNotFound, false},
+		{c, http.StatusServiceUnavailable, true},
+		{c, http.StatusHTTPVersionNotSupported, false},
+		{custom, http.StatusServiceUnavailable, false},
+		{custom, http.StatusHTTPVersionNotSupported, true},
+	} {
+		status = tc.status
+		_, err := tc.c.NextRecord(context.Background(), time.Now())
+		var e *ResponseError
+		if !errors.As(err, &e) {
+			t.Errorf("status %d: expected a *ResponseError, got %v", tc.status, err)
+			continue
+		}
+		if e.StatusCode != tc.status || e.URL == "" || e.Retryable != tc.retryable {
+			t.Errorf("status %d: unexpected response error %+v", tc.status, e)
+		}
+		if want := min(tc.status+100, maxErrorBody); len(e.Body) != want {
+			t.Errorf("status %d: expected a %d bytes body, got %d", tc.status, want, len(e.Body))
+		}
+	}
+}
+
+func TestStaleError(t *testing.T) {
+	srv := fixtureServer(t)
+	_, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL)).LastRecord(context.Background())
+	if !errors.Is(err, ErrStale) {
+		t.Errorf("expected ErrStale, got %v", err)
+	}
+}
+
+func TestSignatureError(t *testing.T) {
+	key, cert, _ := testCertificate(t)
+	rec := fixtureRecord(t)
+	signRecord(t, key, &rec)
+	rec.Pulse.StatusCode = 4
+	if err := Verify(rec, cert); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+func TestErrorCauses(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	_, err := NewClient(WithoutVerification(), WithBaseURL(slow.URL), WithTimeout(10*time.Millisecond)).NextRecord(context.Background(), time.Now())
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected an ErrUnavailable error wrapping context.DeadlineExceeded, got %v", err)
+	}
+
+	untrusted := httptest.NewTLSServer(http.NotFoundHandler())
+	defer untrusted.Close()
+	_, err = NewClient(WithoutVerification(), WithBaseURL(untrusted.URL)).NextRecord(context.Background(), time.Now())
+	var unknown x509.UnknownAuthorityError
+	if !errors.As(err, &unknown) {
+		t.Errorf("expected the x509 verification failure to be wrapped, got %v", err)
+	}
+
+	rec := fixtureRecord(t)
+	rec.Pulse.SignatureValue = "zz"
+	var invalid hex.InvalidByteError
+	if _, err := rec.ComputeOutputValue(); !errors.As(err, &invalid) {
+		t.Errorf("expected the hex decoding error to be wrapped, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package beacon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxResponseSize bounds the body of every response of a client created without WithMaxResponseSize. Records and certificates
+// are a few kilobytes, anything much larger is not a beacon response.
+const DefaultMaxResponseSize = 1 << 20
+
+// WithMaxResponseSize bounds the size of the responses the client reads, DefaultMaxResponseSize by default.
+// Larger responses are rejected as malformed.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxResponseSize = n
+	}
+}
+
+// decoder consumes the body of a response, provided its media type is one of accept. An empty media type is always accepted.
+type decoder struct {
+	accept []string
+	decode func(io.Reader) error
+}
+
+// recordMediaTypes are the media types a record may be served as. Servers that don't label their JSON send text/plain.
+var recordMediaTypes = []string{"application/json", "text/json", "text/plain"}
+
+// certificateMediaTypes are the media types a PEM encoded certificate may be served as
+var certificateMediaTypes = []string{"application/x-pem-file", "application/x-x509-ca-cert", "application/pkix-cert", "application/octet-stream", "text/plain"}
+
+// jsonDecoder decodes a JSON body into v
+func jsonDecoder(v any) decoder {
+	return decoder{recordMediaTypes, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(v)
+	}}
+}
+
+// accepts reports whether a response labelled with the Content-Type header ct can be decoded
+func (d decoder) accepts(ct string) bool {
+	if ct == "" {
+		return true
+	}
+	typ, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	if strings.HasSuffix(typ, "+json") {
+		typ = "application/json"
+	}
+	for _, a := range d.accept {
+		if typ == a {
+			return true
+		}
+	}
+	return false
+}
+
+// errLegacyProtocol reports an endpoint serving XML, as the 1.0 protocol NIST retired in 2018 did
+var errLegacyProtocol = errors.New("the endpoint serves XML, as beacons speaking the retired 1.0 protocol do; point the client at a 2.0 endpoint such as " + DefaultBaseURL)
+
+// isXML reports whether the Content-Type header ct labels an XML document
+func isXML(ct string) bool {
+	typ, _, err := mime.ParseMediaType(ct)
+	return err == nil && (typ == "application/xml" || typ == "text/xml" || strings.HasSuffix(typ, "+xml"))
+}
+
+// errTooLarge is returned by a bodyReader once more than its limit has been read
+var errTooLarge = errors.New("Response too large")
+
+// bodyReader reads a response body up to a limit, keeping track of why reading stopped
+type bodyReader struct {
+	r    io.Reader
+	left int64
+	// err is the last error of the underlying reader other than io.EOF
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	if b.left <= 0 {
+		return 0, errTooLarge
+	}
+	if int64(len(p)) > b.left {
+		p = p[:b.left]
+	}
+	n, err := b.r.Read(p)
+	b.left -= int64(n)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// readBody checks the media type of r and decodes its body with d, reading at most limit bytes. Failing to read the body reports
+// the beacon as unavailable, while bodies that can't be decoded are malformed.
+func readBody(r *http.Response, url string, limit int64, d decoder) error {
+	if ct := r.Header.Get("Content-Type"); !d.accepts(ct) {
+		if isXML(ct) {
+			return &Error{Kind: ErrMalformedResponse, StatusCode: r.StatusCode, URL: url, Err: errLegacyProtocol}
+		}
+		return &Error{Kind: ErrMalformedResponse, StatusCode: r.StatusCode, URL: url, Err: fmt.Errorf("unexpected content type %q", ct)}
+	}
+	if limit <= 0 {
+		limit = DefaultMaxResponseSize
+	}
+	if r.ContentLength > limit {
+		return &Error{Kind: ErrMalformedResponse, StatusCode: r.StatusCode, URL: url, Err: fmt.Errorf("the response is %d bytes, the limit is %d", r.ContentLength, limit)}
+	}
+
+	rc, err := decompress(r)
+	if err != nil {
+		return &Error{Kind: ErrMalformedResponse, StatusCode: r.StatusCode, URL: url, Err: err}
+	}
+	defer rc.Close()
+
+	// the limit applies to the decompressed body
+	body := &bodyReader{r: rc, left: limit}
+	err = d.decode(body)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errTooLarge):
+		return &Error{Kind: ErrMalformedResponse, StatusCode: r.StatusCode, URL: url, Err: fmt.Errorf("the response exceeds %d bytes", limit)}
+	case body.err != nil:
+		return &Error{Kind: ErrUnavailable, StatusCode: r.StatusCode, URL: url, Err: body.err}
+	default:
+		return &Error{Kind: ErrMalformedResponse, StatusCode: r.StatusCode, URL: url, Err: err}
+	}
+}
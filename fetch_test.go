@@ -0,0 +1,53 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseLimits(t *testing.T) {
+	rec := fixtureRecord(t)
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		limit       int64
+		err         error
+	}{
+		{"json", "application/json", string(buf), 0, nil},
+		{"unlabelled", "", string(buf), 0, nil},
+		{"vendor json", "application/vnd.beacon+json; charset=utf-8", string(buf), 0, nil},
+		{"html", "text/html; charset=utf-8", "<html><body>Please log in</body></html>", 0, ErrMalformedResponse},
+		{"legacy xml", "text/xml;charset=UTF-8", "<record><version>Version 1.0</version></record>", 0, ErrMalformedResponse},
+		{"truncated", "application/json", string(buf[:len(buf)/2]), 0, ErrMalformedResponse},
+		{"too large", "application/json", string(buf), int64(len(buf) / 2), ErrMalformedResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header()["Content-Type"] = []string{tt.contentType}
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithMaxResponseSize(tt.limit))
+			got, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+			if err == nil && got.Pulse.OutputValue != rec.Pulse.OutputValue {
+				t.Error("the record wasn't decoded")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package beacon
+
+import (
+	"context"
+	"sync"
+)
+
+// flight coalesces concurrent requests for the same URL into a single upstream request, whose result is shared by all callers
+type flight struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is a request in progress, done is closed once rec and err are set
+type call struct {
+	done chan struct{}
+	rec  Record
+	err  error
+	// waiters counts the callers waiting for the request, which is cancelled when the last one gives up
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do returns the result of fetch for key, joining the request already in progress for key if there is one. The request is detached
+// from the cancellation of ctx so that a caller giving up doesn't fail the others, each caller still returns as soon as its own ctx is
+// done, and the request is cancelled once every caller has given up.
+func (f *flight) do(ctx context.Context, key string, fetch func(context.Context) (Record, error)) (Record, error) {
+	f.mu.Lock()
+	cl, ok := f.calls[key]
+	if !ok {
+		if f.calls == nil {
+			f.calls = make(map[string]*call)
+		}
+		fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		f.calls[key] = cl
+		go func() {
+			cl.rec, cl.err = fetch(fctx)
+			cancel()
+			f.mu.Lock()
+			f.forget(key, cl)
+			f.mu.Unlock()
+			close(cl.done)
+		}()
+	}
+	cl.waiters++
+	f.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.rec, cl.err
+	case <-ctx.Done():
+		f.mu.Lock()
+		if cl.waiters--; cl.waiters == 0 {
+			cl.cancel()
+			// the next caller starts a new request rather than joining the cancelled one
+			f.forget(key, cl)
+		}
+		f.mu.Unlock()
+		return Record{}, ctx.Err()
+	}
+}
+
+// forget removes cl from the requests in progress, unless another request for key replaced it. f.mu must be held.
+func (f *flight) forget(key string, cl *call) {
+	if f.calls[key] == cl {
+		delete(f.calls, key)
+	}
+}
@@ -0,0 +1,119 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrentFetchesCoalesce(t *testing.T) {
+	rec := fixtureRecord(t)
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(rec)
+	}))
+	defer srv.Close()
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithoutStaleness())
+
+	// a caller giving up doesn't fail the request for the others
+	ctx, cancel := context.WithCancel(context.Background())
+	quitter := make(chan error)
+	go func() {
+		_, err := c.LastRecord(ctx)
+		quitter <- err
+	}()
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.LastRecord(context.Background())
+			if err == nil && got.Pulse.PulseIndex != rec.Pulse.PulseIndex {
+				err = errors.New("unexpected record")
+			}
+			errs <- err
+		}()
+	}
+
+	// the callers join the request in progress before the first one gives up
+	for waiters(&c.flight) != 11 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-quitter; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the cancelled caller to return, got %v", err)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected a single request, got %d", n)
+	}
+
+	// once done, the request is made again
+	if _, err := c.LastRecord(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected a second request, got %d", n)
+	}
+}
+
+// waiters returns the number of callers waiting for the requests in progress
+func waiters(f *flight) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, cl := range f.calls {
+		n += cl.waiters
+	}
+	return n
+}
+
+func TestAbandonedFetchesStop(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	policy := DefaultRetryPolicy
+	policy.MaxAttempts = 6
+	policy.BaseDelay = 50 * time.Millisecond
+	policy.MaxDelay = 50 * time.Millisecond
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRetry(policy))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.LastRecord(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the caller's deadline to be exceeded, got %v", err)
+	}
+	// the retries stop with the last caller instead of going on in the background
+	n := requests.Load()
+	time.Sleep(300 * time.Millisecond)
+	if got := requests.Load(); got != n {
+		t.Errorf("expected no request after the caller gave up, got %d more", got-n)
+	}
+	if waiters(&c.flight) != 0 {
+		t.Error("expected the abandoned request to be forgotten")
+	}
+}
@@ -0,0 +1,74 @@
+package fscache
+
+import (
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+)
+
+// maxTime bounds the timestamps of the entries listed without an end
+var maxTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// store is the archive.Store kept in a cache directory
+type store struct {
+	c *Cache
+}
+
+var _ archive.Store = store{}
+
+// Archive returns an archive storing its records in the cache directory, for durability without a database. Records that don't hash
+// to their output value can't be archived.
+func (c *Cache) Archive() *archive.Archive {
+	return archive.New(store{c})
+}
+
+func (s store) Put(rec beacon.Record, verified bool) error {
+	out, err := s.c.store(rec)
+	if err != nil || !verified {
+		return err
+	}
+	return writeFile(s.c.timePath("verified", rec.Pulse.TimeStamp), []byte(out))
+}
+
+func (s store) Get(t time.Time) (beacon.Record, error) {
+	return s.c.Load(t)
+}
+
+func (s store) GetByIndex(chain, pulse int) (beacon.Record, error) {
+	return s.c.loadEntry(s.c.indexPath(chain, pulse))
+}
+
+func (s store) Range(from, to time.Time) ([]beacon.Record, error) {
+	var recs []beacon.Record
+	for path, err := range s.c.entries("time", from, to, false) {
+		if err != nil {
+			return nil, err
+		}
+		rec, err := s.c.loadEntry(path)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// latest returns the record of the last entry of tree
+func (s store) latest(tree string) (beacon.Record, error) {
+	for path, err := range s.c.entries(tree, time.Time{}, maxTime, true) {
+		if err != nil {
+			return beacon.Record{}, err
+		}
+		return s.c.loadEntry(path)
+	}
+	return beacon.Record{}, ErrNotFound
+}
+
+func (s store) Latest() (beacon.Record, error) {
+	return s.latest("time")
+}
+
+func (s store) LatestVerified() (beacon.Record, error) {
+	return s.latest("verified")
+}
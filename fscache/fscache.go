@@ -0,0 +1,238 @@
+// Package fscache implements a beacon.Cache stored in a directory, for durability without a database. Each pulse is a file named by
+// its output value, the SHA-512 of the pulse itself, so a file can be checked against its name; a second tree indexes them by timestamp.
+// Files are written to a temporary name and renamed into place, so a crash never leaves a partial record behind. The same directory
+// can back an archive.Archive.
+package fscache
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// ErrNotFound is returned when the cache doesn't hold the requested record, it matches beacon.ErrNotFound
+var ErrNotFound = fmt.Errorf("%w in the cache directory", beacon.ErrNotFound)
+
+// Cache is a beacon.Cache keeping records as files under a directory, forever: published pulses never change. It is safe for concurrent
+// use, and by several processes sharing the directory.
+type Cache struct {
+	dir string
+}
+
+var _ beacon.Cache = (*Cache)(nil)
+
+// New returns a cache storing records under dir, creating it if needed
+func New(dir string) (*Cache, error) {
+	for _, sub := range []string{"pulses", "time", "index", "verified"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("Couldn't create the cache directory: %w", err)
+		}
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// pulsePath returns the path of the record whose output value is out, hex encoded in upper case
+func (c *Cache) pulsePath(out string) string {
+	return filepath.Join(c.dir, "pulses", out[:2], out+".json")
+}
+
+// timePath returns the path of the entry of the pulse emitted at t in tree, "time" or "verified", grouped by day
+func (c *Cache) timePath(tree string, t time.Time) string {
+	return filepath.Join(c.dir, tree, t.UTC().Format(time.DateOnly), fmt.Sprintf("%016d", t.UnixMilli()))
+}
+
+// indexPath returns the path of the index entry of the pulse with the given chain and pulse index
+func (c *Cache) indexPath(chain, pulse int) string {
+	return filepath.Join(c.dir, "index", strconv.Itoa(chain), strconv.Itoa(pulse))
+}
+
+// writeFile writes buf to path atomically: it is written to a temporary file in the same directory, synced, then renamed over path
+func writeFile(path string, buf []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	// persist the rename itself, where directories can be synced
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// outputValue returns the output value rec must be stored under: the one it computes, which must match the one it carries
+func outputValue(rec beacon.Record) (string, error) {
+	out, err := rec.ComputeOutputValue()
+	if err != nil {
+		return "", err
+	}
+	name := strings.ToUpper(hex.EncodeToString(out))
+	if !strings.EqualFold(name, rec.Pulse.OutputValue) {
+		return "", errors.New("The record's output value isn't the hash of its pulse")
+	}
+	return name, nil
+}
+
+// Store writes rec, the bytes the beacon served if it retained them. The pulse file is written before the index entries pointing to it,
+// so a reader never finds a dangling entry. Storing a record that is already cached only rewrites its index entries.
+func (c *Cache) Store(rec beacon.Record) error {
+	_, err := c.store(rec)
+	return err
+}
+
+// store writes rec as Store does, returning its output value
+func (c *Cache) store(rec beacon.Record) (string, error) {
+	out, err := outputValue(rec)
+	if err != nil {
+		return "", fmt.Errorf("Couldn't store the record: %w", err)
+	}
+	path := c.pulsePath(out)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		buf := rec.Raw()
+		if buf == nil {
+			if buf, err = json.Marshal(rec); err != nil {
+				return "", fmt.Errorf("Couldn't encode the record: %w", err)
+			}
+		}
+		if err := writeFile(path, buf); err != nil {
+			return "", fmt.Errorf("Couldn't store the record: %w", err)
+		}
+	}
+	for _, index := range []string{c.indexPath(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex), c.timePath("time", rec.Pulse.TimeStamp)} {
+		if err := writeFile(index, []byte(out)); err != nil {
+			return "", fmt.Errorf("Couldn't index the record: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// Load returns the record whose pulse timestamp is t. A file that doesn't hash to its name is reported as beacon.ErrMalformedResponse.
+func (c *Cache) Load(t time.Time) (beacon.Record, error) {
+	return c.loadEntry(c.timePath("time", t))
+}
+
+// loadEntry returns the record the index entry at path points to
+func (c *Cache) loadEntry(path string) (beacon.Record, error) {
+	name, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return beacon.Record{}, ErrNotFound
+	}
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	return c.load(string(name))
+}
+
+func (c *Cache) load(name string) (beacon.Record, error) {
+	if _, err := hex.DecodeString(name); len(name) != 128 || err != nil {
+		return beacon.Record{}, &beacon.Error{Kind: beacon.ErrMalformedResponse, Err: errors.New("Invalid index entry")}
+	}
+	path := c.pulsePath(name)
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return beacon.Record{}, ErrNotFound
+	}
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	rec, err := beacon.ParseRecordLenient(buf)
+	if err != nil {
+		return beacon.Record{}, &beacon.Error{Kind: beacon.ErrMalformedResponse, URL: path, Err: err}
+	}
+	if out, err := outputValue(rec); err != nil || out != name {
+		return beacon.Record{}, &beacon.Error{Kind: beacon.ErrMalformedResponse, URL: path, Err: errors.New("The file doesn't hash to its name")}
+	}
+	return rec, nil
+}
+
+// Get returns the record whose pulse timestamp is t, if it is cached and intact
+func (c *Cache) Get(t time.Time) (beacon.Record, bool) {
+	rec, err := c.Load(t)
+	return rec, err == nil
+}
+
+// Put caches rec. Records that can't be stored are silently not cached.
+func (c *Cache) Put(t time.Time, rec beacon.Record) {
+	c.Store(rec)
+}
+
+// entries yields the paths of the entries of tree, "time" or "verified", with a timestamp in [from, to], ordered by timestamp, or in
+// reverse order if reverse is true
+func (c *Cache) entries(tree string, from, to time.Time, reverse bool) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		days, err := os.ReadDir(filepath.Join(c.dir, tree))
+		if err != nil {
+			yield("", err)
+			return
+		}
+		// directory entries are sorted by name, which orders the days and, zero padded, the timestamps
+		first, last := from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly)
+		days = slices.DeleteFunc(days, func(e os.DirEntry) bool { return e.Name() < first || e.Name() > last })
+		if reverse {
+			slices.Reverse(days)
+		}
+		for _, day := range days {
+			entries, err := os.ReadDir(filepath.Join(c.dir, tree, day.Name()))
+			if err != nil {
+				yield("", err)
+				return
+			}
+			entries = slices.DeleteFunc(entries, func(e os.DirEntry) bool {
+				ms, err := strconv.ParseInt(e.Name(), 10, 64)
+				return err != nil || ms < from.UnixMilli() || ms > to.UnixMilli()
+			})
+			if reverse {
+				slices.Reverse(entries)
+			}
+			for _, e := range entries {
+				if !yield(filepath.Join(c.dir, tree, day.Name(), e.Name()), nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All yields every cached record ordered by timestamp, then stops after yielding the first error, such as a corrupted file
+func (c *Cache) All() iter.Seq2[beacon.Record, error] {
+	return func(yield func(beacon.Record, error) bool) {
+		for path, err := range c.entries("time", time.Time{}, maxTime, false) {
+			var rec beacon.Record
+			if err == nil {
+				rec, err = c.loadEntry(path)
+			}
+			if !yield(rec, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package fscache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestCache(t *testing.T) {
+	// pulses every ten minutes over two days
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-48*time.Hour)), beacontest.WithPeriod(10*time.Minute))
+	defer srv.Close()
+	recs := srv.Records()
+
+	dir := t.TempDir()
+	c, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := len(recs) - 1; i >= 0; i-- {
+		wg.Go(func() {
+			if err := c.Store(recs[i]); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	// storing twice is harmless
+	if err := c.Store(recs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := again.Load(recs[1].Pulse.TimeStamp)
+	if err != nil || !got.Equal(recs[1]) {
+		t.Fatalf("expected the record to be read back, got %v", err)
+	}
+	if _, err := again.Load(recs[1].Pulse.TimeStamp.Add(time.Minute)); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("expected a missing record not to be found, got %v", err)
+	}
+
+	n := 0
+	var last time.Time
+	for rec, err := range again.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rec.Pulse.TimeStamp.After(last) {
+			t.Errorf("record %d out of order", rec.Pulse.PulseIndex)
+		}
+		last = rec.Pulse.TimeStamp
+		n++
+	}
+	if n != len(recs) {
+		t.Errorf("expected %d records, got %d", len(recs), n)
+	}
+
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if strings.HasPrefix(d.Name(), ".tmp-") {
+			t.Errorf("temporary file %s left behind", path)
+		}
+		return nil
+	})
+
+	forged := recs[2]
+	forged.Pulse.LocalRandomValue = recs[3].Pulse.LocalRandomValue
+	if err := c.Store(forged); err == nil {
+		t.Error("expected a record whose output value isn't its hash to be rejected")
+	}
+
+	// a corrupted file no longer hashes to its name
+	path := c.pulsePath(strings.ToUpper(recs[1].Pulse.OutputValue))
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := strings.Replace(string(buf), recs[1].Pulse.LocalRandomValue, recs[2].Pulse.LocalRandomValue, 1)
+	if err := os.WriteFile(path, []byte(corrupted), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get(recs[1].Pulse.TimeStamp); ok {
+		t.Error("expected a corrupted record to be a miss")
+	}
+	if _, err := c.Load(recs[1].Pulse.TimeStamp); !errors.Is(err, beacon.ErrMalformedResponse) {
+		t.Errorf("expected a corrupted record to be reported, got %v", err)
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	c, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.Client(beacon.WithCache(c))
+	rec := srv.Records()[3]
+	if _, err := client.CurrentRecord(context.Background(), rec.Pulse.TimeStamp); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Get(rec.Pulse.TimeStamp); !ok || !got.Equal(rec) {
+		t.Error("expected the fetched record to be cached")
+	}
+}
+
+func TestArchive(t *testing.T) {
+	// a day of pulses every ten minutes
+	origin := time.Now().Add(-24 * time.Hour)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithPeriod(10*time.Minute))
+	defer srv.Close()
+	recs := srv.Records()
+
+	c, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := c.Archive()
+	for i, rec := range recs {
+		put := a.Put
+		if i < 100 {
+			put = a.PutVerified
+		}
+		if err := put(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := a.Range(recs[10].Pulse.TimeStamp, recs[20].Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 11 || !got[0].Equal(recs[10]) || beacon.VerifyChain(got) != nil {
+		t.Errorf("unexpected range of %d records", len(got))
+	}
+	if rec, err := a.GetByIndex(recs[5].Pulse.ChainIndex, recs[5].Pulse.PulseIndex); err != nil || !rec.Equal(recs[5]) {
+		t.Errorf("couldn't get the record by index: %v", err)
+	}
+	if rec, err := a.Latest(); err != nil || !rec.Equal(recs[len(recs)-1]) {
+		t.Errorf("unexpected latest record: %v", err)
+	}
+	if rec, err := a.LatestVerified(); err != nil || !rec.Equal(recs[99]) {
+		t.Errorf("unexpected latest verified record: %v", err)
+	}
+	if _, err := a.Get(origin.Add(-time.Hour)); !errors.Is(err, archive.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Gap is an interval in which the beacon emitted no pulse although it should have
+type Gap struct {
+	// Start and End are the timestamps of the pulses around the gap, or the bounds of the scanned range
+	Start, End time.Time
+	// Missing is how many pulses should have been emitted in between
+	Missing int
+	// StartStatus and EndStatus are the status codes of the pulses at Start and End, -1 at a bound of the scanned range
+	StartStatus, EndStatus int
+}
+
+// gapBefore returns the gap between prev and rec, consecutive records of a traversal, if the beacon flagged one with the
+// StatusGap status code or if more than a period elapsed between them. Chain restarts aren't gaps.
+func gapBefore(prev, rec Record) (Gap, bool) {
+	if rec.IsNewChainStart() || rec.Pulse.ChainIndex != prev.Pulse.ChainIndex {
+		return Gap{}, false
+	}
+	period := recordPeriod(prev)
+	elapsed := rec.Pulse.TimeStamp.Sub(prev.Pulse.TimeStamp)
+	if !rec.IsGap() && elapsed < 2*period {
+		return Gap{}, false
+	}
+	return Gap{
+		Start: prev.Pulse.TimeStamp, End: rec.Pulse.TimeStamp, Missing: max(int(elapsed/period)-1, 0),
+		StartStatus: prev.Pulse.StatusCode, EndStatus: rec.Pulse.StatusCode,
+	}, true
+}
+
+// Event is a step of a traversal of the chain: a record, or a gap before the next record
+type Event struct {
+	// Record is the record reached, the zero Record if Gap is set
+	Record Record
+	// Gap is set when pulses are missing before the next record
+	Gap *Gap
+}
+
+// Anomaly is a record that is inconsistent with the one before it
+type Anomaly struct {
+	Record  Record
+	Message string
+}
+
+// GapReport describes the gaps, chain restarts and inconsistencies found in a range of records
+type GapReport struct {
+	From, To time.Time
+	// Pulses is the number of records scanned
+	Pulses      int
+	Gaps        []Gap
+	ChainStarts []Record
+	Anomalies   []Anomaly
+}
+
+// OK reports whether the range holds no gap, chain restart or anomaly
+func (r *GapReport) OK() bool {
+	return len(r.Gaps) == 0 && len(r.ChainStarts) == 0 && len(r.Anomalies) == 0
+}
+
+func (r *GapReport) anomaly(rec Record, format string, args ...any) {
+	r.Anomalies = append(r.Anomalies, Anomaly{Record: rec, Message: fmt.Sprintf(format, args...)})
+}
+
+// NewGapReport scans recs, every record with a timestamp in [from, to] ordered by timestamp, as returned by Client.Records or archive.Range
+func NewGapReport(from, to time.Time, recs []Record) *GapReport {
+	r := &GapReport{From: from, To: to, Pulses: len(recs)}
+	if len(recs) == 0 {
+		return r
+	}
+
+	if first := recs[0]; !first.IsNewChainStart() {
+		if n := int(first.Pulse.TimeStamp.Sub(from) / recordPeriod(first)); n > 0 {
+			r.Gaps = append(r.Gaps, Gap{Start: from, End: first.Pulse.TimeStamp, Missing: n, StartStatus: -1, EndStatus: first.Pulse.StatusCode})
+		}
+	}
+
+	for i, rec := range recs {
+		if rec.Pulse.StatusCode&^(StatusNewChain|StatusGap) != 0 {
+			r.anomaly(rec, "Unknown status code %d", rec.Pulse.StatusCode)
+		}
+		if rec.IsNewChainStart() {
+			r.ChainStarts = append(r.ChainStarts, rec)
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := recs[i-1]
+		if rec.Pulse.ChainIndex != prev.Pulse.ChainIndex {
+			if !rec.IsNewChainStart() {
+				r.anomaly(rec, "Chain index changed from %d without the new chain status", prev.Pulse.ChainIndex)
+			}
+			continue
+		}
+		if rec.IsNewChainStart() {
+			r.anomaly(rec, "New chain status without a new chain index")
+			continue
+		}
+
+		if rec.Pulse.PulseIndex != prev.Pulse.PulseIndex+1 {
+			r.anomaly(rec, "Pulse index %d doesn't follow %d", rec.Pulse.PulseIndex, prev.Pulse.PulseIndex)
+		}
+		if !strings.EqualFold(rec.PreviousOutputValue(), prev.Pulse.OutputValue) {
+			r.anomaly(rec, "Previous output value doesn't match pulse %d", prev.Pulse.PulseIndex)
+		}
+
+		period := recordPeriod(prev)
+		elapsed := rec.Pulse.TimeStamp.Sub(prev.Pulse.TimeStamp)
+		switch {
+		case elapsed < period:
+			r.anomaly(rec, "Emitted %s after the previous pulse, less than the period", elapsed)
+		case elapsed >= 2*period:
+			r.Gaps = append(r.Gaps, Gap{
+				Start: prev.Pulse.TimeStamp, End: rec.Pulse.TimeStamp, Missing: int(elapsed/period) - 1,
+				StartStatus: prev.Pulse.StatusCode, EndStatus: rec.Pulse.StatusCode,
+			})
+			if !rec.IsGap() {
+				r.anomaly(rec, "Pulses are missing before this one but its status doesn't flag a gap")
+			}
+		case rec.IsGap():
+			r.anomaly(rec, "Gap status without missing pulses")
+		}
+	}
+
+	last := recs[len(recs)-1]
+	end, n := to, 0
+	if now := time.Now(); end.After(now) {
+		// leave the beacon time to publish the pulse that is due
+		end, n = now, -1
+	}
+	if n += int(end.Sub(last.Pulse.TimeStamp) / recordPeriod(last)); n > 0 {
+		r.Gaps = append(r.Gaps, Gap{Start: last.Pulse.TimeStamp, End: end, Missing: n, StartStatus: last.Pulse.StatusCode, EndStatus: -1})
+	}
+	return r
+}
+
+// GapReport fetches every record in [from, to] and reports its gaps, chain restarts and inconsistencies
+func (c *Client) GapReport(ctx context.Context, from, to time.Time) (*GapReport, error) {
+	var recs []Record
+	for rec, err := range c.Records(ctx, from, to) {
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, rec)
+	}
+	return NewGapReport(from, to, recs), nil
+}
+
+// Range fetches every record in [from, to] along with the gaps where pulses are missing, which don't fail the call. If a request
+// fails, the records fetched so far are returned with the gaps among them and the error.
+func (c *Client) Range(ctx context.Context, from, to time.Time) ([]Record, []Gap, error) {
+	var recs []Record
+	for rec, err := range c.Records(ctx, from, to) {
+		if err != nil {
+			if len(recs) == 0 {
+				return nil, nil, err
+			}
+			return recs, NewGapReport(from, recs[len(recs)-1].Pulse.TimeStamp, recs).Gaps, err
+		}
+		recs = append(recs, rec)
+	}
+	return recs, NewGapReport(from, to, recs).Gaps, nil
+}
@@ -0,0 +1,96 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestGapReport(t *testing.T) {
+	origin := time.Now().Add(-30 * time.Minute).Truncate(time.Minute)
+	gap := origin.Add(10 * time.Minute)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithGap(gap), beacontest.WithGap(gap.Add(time.Minute)))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	report, err := c.GapReport(ctx, origin, origin.Add(20*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Gaps) != 1 || report.Gaps[0].Missing != 2 || !report.Gaps[0].Start.Equal(gap.Add(-time.Minute)) {
+		t.Fatalf("expected one gap of two pulses after %s, got %+v", gap.Add(-time.Minute), report.Gaps)
+	}
+	if len(report.ChainStarts) != 1 {
+		t.Errorf("expected the first pulse to start the chain, got %d chain starts", len(report.ChainStarts))
+	}
+	if len(report.Anomalies) != 0 {
+		t.Errorf("unexpected anomalies: %+v", report.Anomalies)
+	}
+
+	clean, err := c.GapReport(ctx, gap.Add(5*time.Minute), gap.Add(15*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clean.OK() || clean.Pulses != 11 {
+		t.Errorf("expected 11 pulses without problems, got %+v", clean)
+	}
+
+	// a missing pulse the status code doesn't flag
+	recs := srv.Records()[15:20]
+	recs = append(recs[:2], recs[3:]...)
+	report = beacon.NewGapReport(recs[0].Pulse.TimeStamp, recs[len(recs)-1].Pulse.TimeStamp, recs)
+	if len(report.Gaps) != 1 || len(report.Anomalies) != 3 {
+		t.Errorf("expected a gap with a broken link, a skipped index and an unflagged gap, got %+v and %+v", report.Gaps, report.Anomalies)
+	}
+}
+
+func TestRange(t *testing.T) {
+	origin := time.Now().Add(-30 * time.Minute).Truncate(time.Minute)
+	gap := origin.Add(10 * time.Minute)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithGap(gap))
+	defer srv.Close()
+
+	recs, gaps, err := srv.Client().Range(context.Background(), origin.Add(5*time.Minute), origin.Add(15*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 10 {
+		t.Errorf("expected 10 records, got %d", len(recs))
+	}
+	if len(gaps) != 1 || gaps[0].Missing != 1 || gaps[0].StartStatus != 0 || gaps[0].EndStatus != beacon.StatusGap {
+		t.Errorf("expected one pulse missing before a pulse flagging the gap, got %+v", gaps)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	origin := time.Now().Add(-30 * time.Minute).Truncate(time.Minute)
+	gap := origin.Add(10 * time.Minute)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithGap(gap), beacontest.WithGap(gap.Add(time.Minute)))
+	defer srv.Close()
+
+	var records int
+	var gaps []beacon.Gap
+	for ev, err := range srv.Client().Events(context.Background(), origin.Add(5*time.Minute), origin.Add(15*time.Minute)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Gap != nil {
+			if records == 0 {
+				t.Error("a gap was yielded before any record")
+			}
+			gaps = append(gaps, *ev.Gap)
+			continue
+		}
+		records++
+	}
+	if records != 9 {
+		t.Errorf("expected 9 records, got %d", records)
+	}
+	if len(gaps) != 1 || gaps[0].Missing != 2 || !gaps[0].End.Equal(gap.Add(2*time.Minute)) || gaps[0].EndStatus != beacon.StatusGap {
+		t.Errorf("expected two pulses missing before %s, got %+v", gap.Add(2*time.Minute), gaps)
+	}
+}
@@ -1,105 +1,104 @@
-//Package beacon implements an easy to use, but feature rich NIST Randomness Beacon API Wrapper in go
+// Package beacon implements an easy to use, but feature rich NIST Randomness Beacon API Wrapper in go
 package beacon
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
-type Record struct {
-	Pulse struct {
-		URI              string    `json:"uri"`
-		Version          string    `json:"version"`
-		CipherSuite      int       `json:"cipherSuite"`
-		Period           int       `json:"period"`
-		CertificateID    string    `json:"certificateId"`
-		ChainIndex       int       `json:"chainIndex"`
-		PulseIndex       int       `json:"pulseIndex"`
-		TimeStamp        time.Time `json:"timeStamp"`
-		LocalRandomValue string    `json:"localRandomValue"`
-		External         struct {
-			SourceID   string `json:"sourceId"`
-			StatusCode int    `json:"statusCode"`
-			Value      string `json:"value"`
-		} `json:"external"`
-		ListValues []struct {
-			URI   string `json:"uri"`
-			Type  string `json:"type"`
-			Value string `json:"value"`
-		} `json:"listValues"`
-		PrecommitmentValue string `json:"precommitmentValue"`
-		StatusCode         int    `json:"statusCode"`
-		SignatureValue     string `json:"signatureValue"`
-		OutputValue        string `json:"outputValue"`
-	} `json:"pulse"`
+// ListValue references the output value of an earlier pulse: the previous one, or the first pulse of the current hour, day, month or year
+type ListValue struct {
+	URI   string `json:"uri"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
 }
 
-var defaultClient = &http.Client{}
-
-//const outdated = 60
-const outdated = 120
+// Record is a pulse as the beacon serves it
+type Record struct {
+	Pulse Pulse `json:"pulse"`
 
-// SetClient is useful if you want to use your own http client, it adds the possibility to use a proxy to fetch the data for example.
-func SetClient(cli *http.Client) {
-	defaultClient = cli
+	// raw is the response the record was decoded from, if it was retained
+	raw []byte
 }
 
-func GetRecord(url string) (Record, error) {
-	r, err := defaultClient.Get(url)
+// ParseRecord decodes a record served by the beacon, retaining buf as its raw bytes. Malformed records are rejected, see Record.Validate.
+func ParseRecord(buf []byte) (Record, error) {
+	rec, err := ParseRecordLenient(buf)
 	if err != nil {
-		err = errors.New("Couldn't get the record from the API: " + err.Error())
 		return Record{}, err
 	}
-
-	buf, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		err = errors.New("Couldn't read the API's response: " + err.Error())
+	if err := rec.Validate(); err != nil {
 		return Record{}, err
 	}
+	return rec, nil
+}
 
+// ParseRecordLenient decodes a record as ParseRecord does, without validating its fields
+func ParseRecordLenient(buf []byte) (Record, error) {
 	var rec Record
-	err = json.Unmarshal(buf, &rec)
-	if err != nil {
-		err = errors.New("Couldn't unmarshal the API's response: " + err.Error())
+	if err := json.Unmarshal(buf, &rec); err != nil {
 		return Record{}, err
 	}
+	rec.raw = bytes.Clone(buf)
 	return rec, nil
 }
 
-// LastRecord fetches the latest record from the beacon and returns the record
-func LastRecord() (Record, error) {
-	rec, err := GetRecord("https://beacon.nist.gov/beacon/2.0/pulse/last")
-	if err != nil {
-		return rec, err
-	}
+// Raw returns the exact bytes the beacon served for rec, so they can be archived verbatim. It is nil unless rec was fetched by a client
+// created with WithRawResponses or decoded with ParseRecord.
+func (rec *Record) Raw() []byte {
+	return rec.raw
+}
 
-	if time.Now().Unix()-rec.Pulse.TimeStamp.Unix() > outdated {
-		return rec, errors.New(fmt.Sprintf("Beacon is stale: current=%d, pulse=%d", time.Now().Unix(), rec.Pulse.TimeStamp.Unix()))
-	}
+// defaultClient backs the package-level functions. It is only ever replaced as a whole, by the deprecated SetClient.
+var defaultClient atomic.Pointer[Client]
 
-	return rec, nil
+func init() {
+	defaultClient.Store(NewClient())
+}
+
+// DefaultClient returns the client used by the package-level functions, created by NewClient without options.
+// Prefer creating a client of your own, whose configuration no other package can change.
+func DefaultClient() *Client {
+	return defaultClient.Load()
+}
+
+// SetClient makes the package-level functions use cli. It is safe to call concurrently with them, but affects every caller
+// of the package-level functions in the program.
+//
+// Deprecated: create a client with NewClient(WithHTTPClient(cli)) and call its methods instead of the package-level functions.
+func SetClient(cli *http.Client) {
+	defaultClient.Store(NewClient(WithHTTPClient(cli)))
+}
+
+// GetRecord fetches and decodes the record served at url using the default client
+func GetRecord(url string) (Record, error) {
+	return DefaultClient().GetRecord(context.Background(), url)
+}
+
+// LastRecord fetches the latest record from the beacon and returns the record
+func LastRecord() (Record, error) {
+	return DefaultClient().LastRecord(context.Background())
 }
 
 // CurrentRecord fetches the record closest to the given timestamp
 func CurrentRecord(t time.Time) (Record, error) {
-	fmt.Println("https://beacon.nist.gov/beacon/2.0/pulse/time/" + strconv.FormatInt(t.Unix(), 10))
-	return GetRecord("https://beacon.nist.gov/beacon/2.0/pulse/time/" + strconv.FormatInt(t.Unix(), 10))
+	return DefaultClient().CurrentRecord(context.Background(), t)
 }
 
 // PreviousRecord fetches the record previous to the given timestamp
 func PreviousRecord(t time.Time) (Record, error) {
-	return GetRecord("https://beacon.nist.gov/beacon/2.0/pulse/time/previous/" + strconv.FormatInt(t.Unix(), 10))
+	return DefaultClient().PreviousRecord(context.Background(), t)
 }
 
 // NextRecord fetches the record after the given timestamp
 func NextRecord(t time.Time) (Record, error) {
-	return GetRecord("https://beacon.nist.gov/beacon/2.0/pulse/time/next/" + strconv.FormatInt(t.Unix(), 10))
+	return DefaultClient().NextRecord(context.Background(), t)
 }
 
 func (rec *Record) ChainpointFormat() string {
@@ -108,3 +107,32 @@ func (rec *Record) ChainpointFormat() string {
 	}
 	return ""
 }
+
+// Equal reports whether rec and other hold the same pulse, field by field. Hex encoded values are compared regardless of case and
+// timestamps regardless of location, so a record re-read from an archive equals the one fetched from the beacon.
+func (rec *Record) Equal(other Record) bool {
+	a, b := &rec.Pulse, &other.Pulse
+	if a.URI != b.URI || a.Version != b.Version || a.CipherSuite != b.CipherSuite || a.Period != b.Period ||
+		a.ChainIndex != b.ChainIndex || a.PulseIndex != b.PulseIndex || !a.TimeStamp.Equal(b.TimeStamp) || a.StatusCode != b.StatusCode {
+		return false
+	}
+	if !strings.EqualFold(a.CertificateID, b.CertificateID) || !strings.EqualFold(a.LocalRandomValue, b.LocalRandomValue) ||
+		!strings.EqualFold(a.PrecommitmentValue, b.PrecommitmentValue) || !strings.EqualFold(a.SignatureValue, b.SignatureValue) ||
+		!strings.EqualFold(a.OutputValue, b.OutputValue) {
+		return false
+	}
+	if !strings.EqualFold(a.External.SourceID, b.External.SourceID) ||
+		a.External.StatusCode != b.External.StatusCode || !strings.EqualFold(a.External.Value, b.External.Value) {
+		return false
+	}
+	if len(a.ListValues) != len(b.ListValues) {
+		return false
+	}
+	for i, v := range a.ListValues {
+		w := b.ListValues[i]
+		if v.URI != w.URI || v.Type != w.Type || !strings.EqualFold(v.Value, w.Value) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,11 +1,24 @@
-package beacon
+package beacon_test
 
 import (
-	"github.com/davecgh/go-spew/spew"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	. "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
 )
 
+// TestMain points the package-level functions at a fake beacon, so the tests don't depend on the live NIST service
+func TestMain(m *testing.M) {
+	srv := beacontest.NewServer()
+	SetClient(srv.HTTPClient())
+	code := m.Run()
+	srv.Close()
+	os.Exit(code)
+}
+
 func TestLastRecord(t *testing.T) {
 	resp, err := LastRecord()
 	if err != nil {
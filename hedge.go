@@ -0,0 +1,73 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// hedgeRecord requests the record at path from the beacon, then from each mirror in turn whenever the hedging delay elapses or the
+// last request fails with its host unavailable, until a host serves a record passing verification. The other requests are cancelled
+// once one wins, or once a host reports the pulse isn't found.
+func (c *Client) hedgeRecord(ctx context.Context, path string) (Record, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	hosts := append([]string{c.baseURL}, c.mirrors...)
+
+	type result struct {
+		rec Record
+		err error
+	}
+	results := make(chan result, len(hosts))
+	sent := 0
+	send := func() {
+		url := hosts[sent] + path
+		if sent > 0 {
+			c.log.Debug("Hedging beacon request", "url", url)
+		}
+		sent++
+		go func() {
+			var rec Record
+			err := c.getRetry(ctx, url, c.recordDecoder(&rec))
+			if err == nil {
+				err = c.Verify(ctx, rec)
+			}
+			results <- result{rec, err}
+		}()
+	}
+
+	send()
+	timer := time.NewTimer(c.hedge)
+	defer timer.Stop()
+	var first error
+	for done := 0; ; {
+		select {
+		case <-timer.C:
+			if sent < len(hosts) {
+				send()
+				timer.Reset(c.hedge)
+			}
+		case r := <-results:
+			if r.err == nil {
+				return r.rec, nil
+			}
+			// a pulse that isn't published yet is missing from the mirrors as well
+			if errors.Is(r.err, ErrNotFound) {
+				return Record{}, r.err
+			}
+			if first == nil {
+				first = r.err
+			}
+			if done++; done == len(hosts) {
+				return Record{}, first
+			}
+			// a host that is down or timed out doesn't wait for the delay to be hedged
+			if done == sent && (errors.Is(r.err, ErrUnavailable) || errors.Is(r.err, context.DeadlineExceeded)) {
+				send()
+				timer.Reset(c.hedge)
+			}
+		case <-ctx.Done():
+			return Record{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedging(t *testing.T) {
+	key, cert, _ := testCertificate(t)
+	rec := fixtureRecord(t)
+	signRecord(t, key, &rec)
+	forged := rec
+	forged.Pulse.LocalRandomValue = forged.Pulse.PrecommitmentValue
+
+	host := func(delay time.Duration, rec Record, cancelled *atomic.Bool) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+				json.NewEncoder(w).Encode(rec)
+			case <-r.Context().Done():
+				cancelled.Store(true)
+			}
+		}))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	var cancelled, unused atomic.Bool
+	slow := host(time.Minute, rec, &cancelled)
+	c := NewClient(WithBaseURL(slow), WithMirrors(host(0, forged, &unused), host(10*time.Millisecond, rec, &unused)),
+		WithHedging(20*time.Millisecond), WithCertificate(cert))
+
+	start := time.Now()
+	got, err := c.GetRecord(context.Background(), slow+"/pulse/last")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(rec) {
+		t.Error("expected the verified record to win over the forged one")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("the slow host wasn't hedged, the request took %s", d)
+	}
+	waitFor(t, "the slow request to be cancelled", cancelled.Load)
+
+	// every host failing reports the first failure
+	c = NewClient(WithBaseURL(host(0, forged, &unused)), WithMirrors(host(0, forged, &unused)), WithHedging(10*time.Millisecond), WithCertificate(cert))
+	if _, err := c.LastRecord(context.Background()); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("expected the forged records to be rejected, got %v", err)
+	}
+
+	status := func(code int) string {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(code) }))
+		t.Cleanup(srv.Close)
+		return srv.URL
+	}
+	// a host that is down is hedged without waiting for the delay
+	c = NewClient(WithBaseURL(status(http.StatusServiceUnavailable)), WithMirrors(host(0, rec, &unused)), WithHedging(time.Hour), WithCertificate(cert),
+		WithoutStaleness())
+	if got, err := c.LastRecord(context.Background()); err != nil || !got.Equal(rec) {
+		t.Errorf("expected the mirror to answer at once: %v", err)
+	}
+	// a pulse that isn't published yet isn't asked from the mirrors
+	var asked atomic.Bool
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asked.Store(true)
+		json.NewEncoder(w).Encode(rec)
+	}))
+	defer mirror.Close()
+	c = NewClient(WithBaseURL(status(http.StatusNotFound)), WithMirrors(mirror.URL), WithHedging(time.Hour), WithCertificate(cert), WithoutStaleness())
+	if _, err := c.LastRecord(context.Background()); !errors.Is(err, ErrNotFound) || asked.Load() {
+		t.Errorf("expected ErrNotFound without hedging, got %v", err)
+	}
+}
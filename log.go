@@ -0,0 +1,51 @@
+package beacon
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// WithLogger makes the client, and the watchers and generators using it, log retries, stale records, verification failures and
+// watcher restarts to l. By default nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+// fingerprintSize is how many hex digits of the output value identify a record in logs and printed output
+const fingerprintSize = 16
+
+// fingerprint returns the first hex digits of v, enough to tell values apart at a glance
+func fingerprint(v string) string {
+	if len(v) <= fingerprintSize {
+		return v
+	}
+	return v[:fingerprintSize] + "…"
+}
+
+// String identifies the record by its chain and pulse index, timestamp and a prefix of its output value
+func (rec Record) String() string {
+	s := fmt.Sprintf("pulse %d/%d at %s, output %s", rec.Pulse.ChainIndex, rec.Pulse.PulseIndex, rec.Pulse.TimeStamp.UTC().Format(time.RFC3339), fingerprint(rec.Pulse.OutputValue))
+	if rec.Pulse.StatusCode != 0 {
+		s += fmt.Sprintf(", status %d", rec.Pulse.StatusCode)
+	}
+	return s
+}
+
+// LogValue logs the record as a group of its chain and pulse index, timestamp, status code and a prefix of its output value
+func (rec Record) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("chain", rec.Pulse.ChainIndex),
+		slog.Int("index", rec.Pulse.PulseIndex),
+		slog.Time("timestamp", rec.Pulse.TimeStamp),
+		slog.Int("status", rec.Pulse.StatusCode),
+		slog.String("output", fingerprint(rec.Pulse.OutputValue)),
+	)
+}
+
+// pulseAttrs identifies a record in log entries
+func pulseAttrs(rec Record) slog.Attr {
+	return slog.Any("pulse", rec)
+}
@@ -0,0 +1,43 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	srv := fixtureServer(t)
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+
+	_, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithLogger(l)).LastRecord(context.Background())
+	if err == nil {
+		t.Fatal("expected the fixture record to be stale")
+	}
+	if out := buf.String(); !strings.Contains(out, "Beacon is stale") || !strings.Contains(out, "pulse.index=1000") {
+		t.Errorf("expected the stale record to be logged, got %q", out)
+	}
+}
+
+func TestRecordString(t *testing.T) {
+	rec := fixtureRecord(t)
+	s := rec.String()
+	if !strings.HasPrefix(s, "pulse 2/1000 at 2020-01-01T00:00:00Z, output "+rec.Pulse.OutputValue[:fingerprintSize]) || len(s) > 80 {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("Drawn", "pulse", rec)
+	out := buf.String()
+	for _, want := range []string{"pulse.chain=2", "pulse.index=1000", "pulse.output=" + rec.Pulse.OutputValue[:fingerprintSize]} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, rec.Pulse.OutputValue) {
+		t.Error("expected the output value to be truncated")
+	}
+}
@@ -0,0 +1,56 @@
+package beacon
+
+import (
+	"strings"
+	"time"
+)
+
+// Metrics receives observations from a Client and from the Watchers it starts. Implementations must be safe for concurrent use,
+// the metrics subpackage provides one exporting them to Prometheus.
+type Metrics interface {
+	// ObserveRequest is called after every request attempt with the endpoint requested ("last", "time", "previous", "next",
+	// "chain", "certificate" or "other"), the HTTP status (0 if no response was received) and how long the attempt took
+	ObserveRequest(endpoint string, status int, d time.Duration)
+	// ObserveVerification is called with the result of every verification made by Client.Verify
+	ObserveVerification(err error)
+	// ObserveStale is called whenever LastRecord finds the beacon stale
+	ObserveStale(rec Record)
+	// ObserveCache is called on every cache lookup
+	ObserveCache(hit bool)
+	// ObservePulse is called with every record returned by LastRecord
+	ObservePulse(rec Record)
+}
+
+// WithMetrics makes the client report its activity to m
+func WithMetrics(m Metrics) Option {
+	return func(c *Client) {
+		c.metrics = m
+	}
+}
+
+// endpoint names the beacon endpoint of url for metrics
+func (c *Client) endpoint(url string) string {
+	path, ok := strings.CutPrefix(url, c.baseURL)
+	for _, m := range c.mirrors {
+		if ok {
+			break
+		}
+		path, ok = strings.CutPrefix(url, m)
+	}
+	switch {
+	case path == "/pulse/last":
+		return "last"
+	case strings.HasPrefix(path, "/pulse/time/previous/"):
+		return "previous"
+	case strings.HasPrefix(path, "/pulse/time/next/"):
+		return "next"
+	case strings.HasPrefix(path, "/pulse/time/"):
+		return "time"
+	case strings.HasPrefix(path, "/chain/"):
+		return "chain"
+	case strings.HasPrefix(path, "/certificate/"):
+		return "certificate"
+	default:
+		return "other"
+	}
+}
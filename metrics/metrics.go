@@ -0,0 +1,176 @@
+// Package metrics exports the activity of beacon clients, watchers and archive syncers to Prometheus
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+)
+
+// Collector implements beacon.Metrics, archive.SyncMetrics and prometheus.Collector. Pass it to beacon.WithMetrics or
+// archive.WithSyncMetrics and register it with a Prometheus registry.
+type Collector struct {
+	requests      *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	verifications *prometheus.CounterVec
+	stale         prometheus.Counter
+	cache         *prometheus.CounterVec
+	lastPulse     prometheus.Gauge
+
+	archived   *prometheus.CounterVec
+	syncLag    prometheus.Gauge
+	missing    prometheus.Gauge
+	invalid    prometheus.Gauge
+	reverified prometheus.Gauge
+}
+
+var (
+	_ beacon.Metrics       = (*Collector)(nil)
+	_ archive.SyncMetrics  = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
+
+// New returns a collector whose metrics are prefixed with namespace, e.g. "beacon"
+func New(namespace string) *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Requests made to the beacon, by endpoint and HTTP status (0 when no response was received).",
+		}, []string{"endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests made to the beacon, by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "verifications_total",
+			Help:      "Record verifications, by result.",
+		}, []string{"result"}),
+		stale: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stale_total",
+			Help:      "Times the latest record was found stale.",
+		}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Cache lookups, by result.",
+		}, []string{"result"}),
+		lastPulse: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_pulse_timestamp_seconds",
+			Help:      "Timestamp of the latest record observed.",
+		}),
+		archived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "archived_total",
+			Help:      "Records archived by the syncer, as they were published (tail) or to fill gaps (backfill).",
+		}, []string{"source"}),
+		syncLag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "sync_lag_seconds",
+			Help:      "Age of the latest archived record when the syncer last archived one.",
+		}),
+		missing: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "archive_missing_pulses",
+			Help:      "Pulses missing from the archive after the last backfill, the beacon's own outages included.",
+		}),
+		invalid: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "archive_invalid_records",
+			Help:      "Archived records failing verification in the last re-verification.",
+		}),
+		reverified: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "archive_reverified_records",
+			Help:      "Archived records checked by the last re-verification.",
+		}),
+	}
+}
+
+// ObserveRequest implements beacon.Metrics
+func (c *Collector) ObserveRequest(endpoint string, status int, d time.Duration) {
+	c.requests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	c.latency.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// ObserveVerification implements beacon.Metrics
+func (c *Collector) ObserveVerification(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	c.verifications.WithLabelValues(result).Inc()
+}
+
+// ObserveStale implements beacon.Metrics
+func (c *Collector) ObserveStale(beacon.Record) {
+	c.stale.Inc()
+}
+
+// ObserveCache implements beacon.Metrics
+func (c *Collector) ObserveCache(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	c.cache.WithLabelValues(result).Inc()
+}
+
+// ObservePulse implements beacon.Metrics
+func (c *Collector) ObservePulse(rec beacon.Record) {
+	c.lastPulse.Set(float64(rec.Pulse.TimeStamp.UnixMilli()) / 1000)
+}
+
+// ObserveArchived implements archive.SyncMetrics
+func (c *Collector) ObserveArchived(rec beacon.Record, backfilled bool) {
+	source := "tail"
+	if backfilled {
+		source = "backfill"
+	}
+	c.archived.WithLabelValues(source).Inc()
+}
+
+// ObserveSyncLag implements archive.SyncMetrics
+func (c *Collector) ObserveSyncLag(lag time.Duration) {
+	c.syncLag.Set(lag.Seconds())
+}
+
+// ObserveMissing implements archive.SyncMetrics
+func (c *Collector) ObserveMissing(n int) {
+	c.missing.Set(float64(n))
+}
+
+// ObserveReverification implements archive.SyncMetrics
+func (c *Collector) ObserveReverification(checked, invalid int) {
+	c.reverified.Set(float64(checked))
+	c.invalid.Set(float64(invalid))
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.requests, c.latency, c.verifications, c.stale, c.cache, c.lastPulse,
+		c.archived, c.syncLag, c.missing, c.invalid, c.reverified,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.collectors() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.collectors() {
+		m.Collect(ch)
+	}
+}
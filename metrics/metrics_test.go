@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestCollector(t *testing.T) {
+	srv := beacontest.NewServer()
+	defer srv.Close()
+
+	m := New("beacon")
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(m); err != nil {
+		t.Fatal(err)
+	}
+
+	c := srv.Client(beacon.WithMetrics(m), beacon.WithCache(beacon.NewMemoryCache()))
+	ctx := context.Background()
+	rec, err := c.LastRecord(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LastRecord(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(ctx, rec); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("last", "200")); got != 1 {
+		t.Errorf("expected 1 request to the last endpoint, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.cache.WithLabelValues("hit")); got != 1 {
+		t.Errorf("expected 1 cache hit, got %v", got)
+	}
+	// the fetched record was verified once as it arrived, then by Verify
+	if got := testutil.ToFloat64(m.verifications.WithLabelValues("success")); got != 2 {
+		t.Errorf("expected 2 successful verifications, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.lastPulse); got != float64(rec.Pulse.TimeStamp.Unix()) {
+		t.Errorf("unexpected last pulse timestamp %v", got)
+	}
+	if n, err := testutil.GatherAndCount(reg); err != nil || n == 0 {
+		t.Errorf("couldn't gather the metrics: %v", err)
+	}
+}
+
+func TestCollectorSync(t *testing.T) {
+	m := New("beacon")
+	m.ObserveArchived(beacon.Record{}, false)
+	m.ObserveArchived(beacon.Record{}, true)
+	m.ObserveArchived(beacon.Record{}, true)
+	m.ObserveSyncLag(90 * time.Second)
+	m.ObserveMissing(3)
+	m.ObserveReverification(100, 2)
+
+	if got := testutil.ToFloat64(m.archived.WithLabelValues("backfill")); got != 2 {
+		t.Errorf("expected 2 backfilled records, got %v", got)
+	}
+	if got := testutil.ToFloat64(m.syncLag); got != 90 {
+		t.Errorf("unexpected sync lag %v", got)
+	}
+	if got := testutil.ToFloat64(m.missing); got != 3 {
+		t.Errorf("unexpected missing pulses %v", got)
+	}
+	if got := testutil.ToFloat64(m.invalid); got != 2 {
+		t.Errorf("unexpected invalid records %v", got)
+	}
+}
@@ -0,0 +1,367 @@
+// Package objstore implements an archive.Store in an object storage bucket, such as S3 or GCS, for cheap and durable retention of every
+// pulse a service consumed. Only verified records are written, one object per pulse, alongside a daily manifest listing the pulses of
+// the day and their output values.
+//
+// The bucket layout is:
+//
+//	pulses/<chain>/<pulse>.json                                the record, as served by the beacon if it retained the bytes
+//	time/<YYYY-MM-DD>/<milliseconds>_<chain>_<pulse>_<output>  an empty object indexing the pulse by timestamp
+//	manifests/<YYYY-MM-DD>.json                                the manifest of the day
+package objstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+)
+
+// DefaultTimeout bounds every operation of a Store by default
+const DefaultTimeout = 30 * time.Second
+
+// DefaultManifestInterval is how often a Store rewrites the manifests of the days it stored pulses for by default
+const DefaultManifestInterval = time.Hour
+
+// ErrNotFound is returned when the bucket doesn't hold the requested object or record, it matches beacon.ErrNotFound
+var ErrNotFound = fmt.Errorf("%w in the bucket", beacon.ErrNotFound)
+
+var errUnverified = errors.New("Only verified records are written to object storage")
+
+// Bucket is the object storage a Store writes to. S3 implements it for S3 compatible services; the client of another service, or the
+// official SDKs, are adapted in a few lines. Implementations must be safe for concurrent use.
+type Bucket interface {
+	// Get returns the content of the object named key, or an error matching beacon.ErrNotFound if there is none
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put writes body to the object named key, replacing it if it exists
+	Put(ctx context.Context, key string, body []byte) error
+	// List returns the keys of the objects directly under prefix and the prefixes of the deeper ones up to the next slash, as S3
+	// lists with the slash delimiter, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Manifest lists the pulses of a day stored in a bucket. The output value of a pulse is the SHA-512 hash of its record, so the
+// manifest is enough to check that none of them has been altered or removed.
+type Manifest struct {
+	Day     string          `json:"day"`
+	Written time.Time       `json:"written"`
+	Pulses  []ManifestEntry `json:"pulses"`
+}
+
+// ManifestEntry describes a pulse in a Manifest
+type ManifestEntry struct {
+	ChainIndex  int       `json:"chainIndex"`
+	PulseIndex  int       `json:"pulseIndex"`
+	TimeStamp   time.Time `json:"timeStamp"`
+	OutputValue string    `json:"outputValue"`
+}
+
+// Store is an archive.Store writing verified records to a Bucket. It is safe for concurrent use.
+type Store struct {
+	bucket   Bucket
+	timeout  time.Duration
+	interval time.Duration
+
+	mu sync.Mutex
+	// dirty holds the days whose manifest is out of date, written is when they were last written
+	dirty   map[string]bool
+	written time.Time
+}
+
+var _ archive.Store = (*Store)(nil)
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithTimeout bounds every operation of the store, DefaultTimeout by default
+func WithTimeout(d time.Duration) Option {
+	return func(s *Store) {
+		s.timeout = d
+	}
+}
+
+// WithManifestInterval sets how often the manifests of the days pulses were stored for are rewritten, DefaultManifestInterval by
+// default. They are also written when the store is flushed or closed.
+func WithManifestInterval(d time.Duration) Option {
+	return func(s *Store) {
+		s.interval = d
+	}
+}
+
+// New returns a store writing to b. Pass it to archive.New, or to archive.NewSyncer through it, to retain every pulse a service syncs.
+func New(b Bucket, opts ...Option) *Store {
+	s := &Store{
+		bucket:   b,
+		timeout:  DefaultTimeout,
+		interval: DefaultManifestInterval,
+		dirty:    make(map[string]bool),
+		written:  time.Now(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Store) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+func pulseKey(chain, pulse int) string {
+	return "pulses/" + strconv.Itoa(chain) + "/" + strconv.Itoa(pulse) + ".json"
+}
+
+func dayPrefix(t time.Time) string {
+	return "time/" + t.UTC().Format(time.DateOnly) + "/"
+}
+
+func timePrefix(t time.Time) string {
+	return dayPrefix(t) + fmt.Sprintf("%016d", t.UnixMilli()) + "_"
+}
+
+// parseTimeKey returns the pulse a timestamp index key names
+func parseTimeKey(key string) (ManifestEntry, error) {
+	fields := strings.Split(key[strings.LastIndexByte(key, '/')+1:], "_")
+	if len(fields) != 4 {
+		return ManifestEntry{}, fmt.Errorf("Invalid index key %q", key)
+	}
+	var e ManifestEntry
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err == nil {
+		e.ChainIndex, err = strconv.Atoi(fields[1])
+	}
+	if err == nil {
+		e.PulseIndex, err = strconv.Atoi(fields[2])
+	}
+	if err != nil {
+		return ManifestEntry{}, fmt.Errorf("Invalid index key %q: %w", key, err)
+	}
+	e.TimeStamp = time.UnixMilli(ms).UTC()
+	e.OutputValue = fields[3]
+	return e, nil
+}
+
+// Put writes rec, which must be verified. The pulse object is written before the index entry pointing to it, so a reader never
+// finds a dangling entry.
+func (s *Store) Put(rec beacon.Record, verified bool) error {
+	if !verified {
+		return errUnverified
+	}
+	buf := rec.Raw()
+	if buf == nil {
+		var err error
+		if buf, err = json.Marshal(rec); err != nil {
+			return fmt.Errorf("Couldn't encode the record: %w", err)
+		}
+	}
+	ctx, cancel := s.context()
+	defer cancel()
+	if err := s.bucket.Put(ctx, pulseKey(rec.Pulse.ChainIndex, rec.Pulse.PulseIndex), buf); err != nil {
+		return fmt.Errorf("Couldn't store the record: %w", err)
+	}
+	key := timePrefix(rec.Pulse.TimeStamp) + fmt.Sprintf("%d_%d_%s", rec.Pulse.ChainIndex, rec.Pulse.PulseIndex, strings.ToUpper(rec.Pulse.OutputValue))
+	if err := s.bucket.Put(ctx, key, nil); err != nil {
+		return fmt.Errorf("Couldn't index the record: %w", err)
+	}
+
+	s.mu.Lock()
+	s.dirty[rec.Pulse.TimeStamp.UTC().Format(time.DateOnly)] = true
+	due := s.interval > 0 && time.Since(s.written) >= s.interval
+	s.mu.Unlock()
+	if due {
+		return s.Flush()
+	}
+	return nil
+}
+
+// load returns the record a timestamp index entry points to
+func (s *Store) load(ctx context.Context, e ManifestEntry) (beacon.Record, error) {
+	buf, err := s.bucket.Get(ctx, pulseKey(e.ChainIndex, e.PulseIndex))
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	rec, err := beacon.ParseRecordLenient(buf)
+	if err != nil {
+		return beacon.Record{}, fmt.Errorf("Couldn't decode the stored record: %w", err)
+	}
+	return rec, nil
+}
+
+// entries returns the pulses indexed under prefix, a day or a timestamp, ordered by timestamp
+func (s *Store) entries(ctx context.Context, prefix string) ([]ManifestEntry, error) {
+	keys, err := s.bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ManifestEntry
+	for _, key := range keys {
+		if strings.HasSuffix(key, "/") {
+			continue
+		}
+		e, err := parseTimeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// days returns the days pulses were stored for, ordered
+func (s *Store) days(ctx context.Context) ([]string, error) {
+	prefixes, err := s.bucket.List(ctx, "time/")
+	if err != nil {
+		return nil, err
+	}
+	var days []string
+	for _, p := range prefixes {
+		if day, ok := strings.CutSuffix(strings.TrimPrefix(p, "time/"), "/"); ok {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// Get implements archive.Store
+func (s *Store) Get(t time.Time) (beacon.Record, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	entries, err := s.entries(ctx, timePrefix(t))
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	if len(entries) == 0 {
+		return beacon.Record{}, ErrNotFound
+	}
+	return s.load(ctx, entries[0])
+}
+
+// GetByIndex implements archive.Store
+func (s *Store) GetByIndex(chain, pulse int) (beacon.Record, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.load(ctx, ManifestEntry{ChainIndex: chain, PulseIndex: pulse})
+}
+
+// Range implements archive.Store. The timeout bounds the whole range, which should span a few days at most.
+func (s *Store) Range(from, to time.Time) ([]beacon.Record, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	days, err := s.days(ctx)
+	if err != nil {
+		return nil, err
+	}
+	first, last := from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly)
+	var recs []beacon.Record
+	for _, day := range days {
+		if day < first || day > last {
+			continue
+		}
+		entries, err := s.entries(ctx, "time/"+day+"/")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.TimeStamp.Before(from) || e.TimeStamp.After(to) {
+				continue
+			}
+			rec, err := s.load(ctx, e)
+			if err != nil {
+				return nil, err
+			}
+			recs = append(recs, rec)
+		}
+	}
+	return recs, nil
+}
+
+// Latest implements archive.Store
+func (s *Store) Latest() (beacon.Record, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	days, err := s.days(ctx)
+	if err != nil {
+		return beacon.Record{}, err
+	}
+	for _, day := range slices.Backward(days) {
+		entries, err := s.entries(ctx, "time/"+day+"/")
+		if err != nil {
+			return beacon.Record{}, err
+		}
+		if len(entries) > 0 {
+			return s.load(ctx, entries[len(entries)-1])
+		}
+	}
+	return beacon.Record{}, ErrNotFound
+}
+
+// LatestVerified returns the latest record, as only verified records are stored
+func (s *Store) LatestVerified() (beacon.Record, error) {
+	return s.Latest()
+}
+
+// Manifest returns the manifest of the given day, as last written
+func (s *Store) Manifest(day time.Time) (*Manifest, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	buf, err := s.bucket.Get(ctx, "manifests/"+day.UTC().Format(time.DateOnly)+".json")
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf("Couldn't decode the manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// writeManifest lists the pulses of day in its manifest
+func (s *Store) writeManifest(ctx context.Context, day string) error {
+	entries, err := s.entries(ctx, "time/"+day+"/")
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(Manifest{Day: day, Written: time.Now().UTC(), Pulses: entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.bucket.Put(ctx, "manifests/"+day+".json", buf)
+}
+
+// Flush writes the manifests of the days pulses were stored for since they were last written
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	dirty := s.dirty
+	s.dirty = make(map[string]bool)
+	s.written = time.Now()
+	s.mu.Unlock()
+
+	ctx, cancel := s.context()
+	defer cancel()
+	var errs []error
+	for day := range dirty {
+		if err := s.writeManifest(ctx, day); err != nil {
+			errs = append(errs, fmt.Errorf("Couldn't write the manifest of %s: %w", day, err))
+			// try again on the next flush
+			s.mu.Lock()
+			s.dirty[day] = true
+			s.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+var _ io.Closer = (*Store)(nil)
+
+// Close flushes the manifests, closing an archive closes its store
+func (s *Store) Close() error {
+	return s.Flush()
+}
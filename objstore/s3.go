@@ -0,0 +1,234 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Credentials are the HMAC keys signing the requests to an S3 compatible service. On GCS they are the HMAC keys of a service account.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set with temporary credentials only
+	SessionToken string
+}
+
+// S3 is a Bucket on an S3 compatible service, such as Amazon S3, GCS through its XML API or MinIO. It signs its requests with AWS
+// Signature Version 4 and is safe for concurrent use.
+type S3 struct {
+	url    *url.URL
+	region string
+	creds  Credentials
+	http   *http.Client
+}
+
+var _ Bucket = (*S3)(nil)
+
+// S3Option configures an S3 bucket
+type S3Option func(*S3)
+
+// WithHTTPClient makes the bucket use cli for all requests
+func WithHTTPClient(cli *http.Client) S3Option {
+	return func(b *S3) {
+		b.http = cli
+	}
+}
+
+// NewS3 returns the bucket at bucketURL, either virtual-hosted as https://my-bucket.s3.eu-west-1.amazonaws.com or path-style as
+// http://minio:9000/my-bucket, in the given region
+func NewS3(bucketURL, region string, creds Credentials, opts ...S3Option) (*S3, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse the bucket URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("Invalid bucket URL %q", bucketURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	b := &S3{url: u, region: region, creds: creds, http: http.DefaultClient}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b, nil
+}
+
+// NewGCS returns the named Google Cloud Storage bucket, reached through its S3 compatible XML API with HMAC keys
+func NewGCS(bucket string, creds Credentials, opts ...S3Option) (*S3, error) {
+	return NewS3("https://storage.googleapis.com/"+bucket, "auto", creds, opts...)
+}
+
+// uriEncode escapes s as Signature Version 4 requires: every byte but the unreserved characters, and slashes if slash is true
+func uriEncode(s string, slash bool) string {
+	var sb strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !slash:
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// canonicalQuery returns the query string of q sorted and escaped as Signature Version 4 requires
+func canonicalQuery(q url.Values) string {
+	var params []string
+	for k, vs := range q {
+		for _, v := range vs {
+			params = append(params, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// signature returns the Signature Version 4 signature of a request to an S3 service. header must hold the signed headers, named in
+// lower case by signed, including the host.
+func signature(secret, region string, t time.Time, method, path string, query url.Values, header http.Header, signed []string) string {
+	var canonical strings.Builder
+	fmt.Fprintf(&canonical, "%s\n%s\n%s\n", method, uriEncode(path, false), canonicalQuery(query))
+	for _, name := range signed {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, strings.TrimSpace(strings.Join(header.Values(name), ",")))
+	}
+	fmt.Fprintf(&canonical, "\n%s\n%s", strings.Join(signed, ";"), header.Get("X-Amz-Content-Sha256"))
+
+	day := t.UTC().Format("20060102")
+	scope := day + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonical.String()))
+	toSign := "AWS4-HMAC-SHA256\n" + t.UTC().Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secret), day)
+	for _, s := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+// sign adds the Signature Version 4 authorization of req at t, whose body hashes to payloadHash, signing all its headers
+func (b *S3) sign(req *http.Request, payloadHash string, t time.Time) {
+	req.Header.Set("X-Amz-Date", t.UTC().Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.creds.SessionToken)
+	}
+	header := req.Header.Clone()
+	header.Set("Host", req.URL.Host)
+	var signed []string
+	for name := range header {
+		signed = append(signed, strings.ToLower(name))
+	}
+	slices.Sort(signed)
+
+	sig := signature(b.creds.SecretAccessKey, b.region, t, req.Method, req.URL.Path, req.URL.Query(), header, signed)
+	scope := t.UTC().Format("20060102") + "/" + b.region + "/s3/aws4_request"
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.creds.AccessKeyID, scope, strings.Join(signed, ";"), sig))
+}
+
+// do sends a signed request for key with the given query and body, returning the response body if the status is 2xx
+func (b *S3) do(ctx context.Context, method, key string, query url.Values, body []byte) ([]byte, error) {
+	u := *b.url
+	u.Path += "/" + key
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(body)
+	b.sign(req, hex.EncodeToString(hash[:]), time.Now())
+
+	resp, err := b.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet && key != "" {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Code    string
+			Message string
+		}
+		xml.Unmarshal(buf, &e)
+		return nil, fmt.Errorf("Couldn't %s /%s: %s %s %s", strings.ToLower(method), key, resp.Status, e.Code, e.Message)
+	}
+	return buf, nil
+}
+
+// Get returns the content of the object named key
+func (b *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	return b.do(ctx, http.MethodGet, key, nil, nil)
+}
+
+// Put writes body to the object named key
+func (b *S3) Put(ctx context.Context, key string, body []byte) error {
+	_, err := b.do(ctx, http.MethodPut, key, nil, body)
+	return err
+}
+
+// List lists the objects and prefixes under prefix with ListObjectsV2, following its continuation tokens
+func (b *S3) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		buf, err := b.do(ctx, http.MethodGet, "", q, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			Contents []struct {
+				Key string
+			}
+			CommonPrefixes []struct {
+				Prefix string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := xml.Unmarshal(buf, &res); err != nil {
+			return nil, fmt.Errorf("Couldn't decode the object listing: %w", err)
+		}
+		for _, c := range res.Contents {
+			names = append(names, c.Key)
+		}
+		for _, p := range res.CommonPrefixes {
+			names = append(names, p.Prefix)
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			break
+		}
+		token = res.NextContinuationToken
+	}
+	slices.Sort(names)
+	return names, nil
+}
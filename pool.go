@@ -0,0 +1,61 @@
+package beacon
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// randPoolDomain separates the seeds of the pool's generators from the other values derived from a pulse
+const randPoolDomain = "go-nist-beacon rand pool"
+
+// RandPool hands out independent generators seeded from the same pulse, one per goroutine, so that high-throughput servers don't
+// contend on a shared Rand. Worker i's generator is seeded with the SHA-512 of the length-framed domain, label, i and output value:
+// the same pulse, label and index always yield the same sequence, and sequences never overlap across labels or indexes.
+// It is safe for concurrent use, unlike the generators it hands out.
+//
+// WARNING: like the pulse, the values are public, never use them as secrets.
+type RandPool struct {
+	rec   Record
+	out   []byte
+	label string
+	// next is the index of the next worker whose generator Get creates
+	next atomic.Uint64
+	pool sync.Pool
+}
+
+// NewRandPool returns a pool of generators seeded from rec, label separating the applications sharing the pulse
+func NewRandPool(rec Record, label string) (*RandPool, error) {
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &RandPool{rec: rec, out: out, label: label}, nil
+}
+
+// Pulse returns the record the pool's generators are seeded from
+func (p *RandPool) Pulse() Record {
+	return p.rec
+}
+
+// Worker returns a new generator for worker i, yielding the same sequence as every other generator of worker i of a pool with the
+// same pulse and label. Use it when the results must be reproducible from the pulse and the worker index.
+func (p *RandPool) Worker(i uint64) *rand.Rand {
+	seed := hashFramed([]byte(randPoolDomain), []byte(p.label), binary.BigEndian.AppendUint64(nil, i), p.out)
+	return rand.New(&Source{out: seed, r: shake(seed)})
+}
+
+// Get returns a generator for the exclusive use of the caller until it is given back with Put. Released generators are reused,
+// new ones are those of the next worker index, so which sequence a caller gets depends on scheduling: use Worker for reproducibility.
+func (p *RandPool) Get() *rand.Rand {
+	if r, ok := p.pool.Get().(*rand.Rand); ok {
+		return r
+	}
+	return p.Worker(p.next.Add(1) - 1)
+}
+
+// Put gives back a generator obtained from Get, which the caller must not use anymore
+func (p *RandPool) Put(r *rand.Rand) {
+	p.pool.Put(r)
+}
@@ -0,0 +1,58 @@
+package beacon
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRandPool(t *testing.T) {
+	rec := fixtureRecord(t)
+	p, err := NewRandPool(rec, "lottery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewRandPool(rec, "raffle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared, err := NewRand(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := p.Worker(3).Int()
+	if again := p.Worker(3).Int(); again != first {
+		t.Error("worker 3 isn't reproducible")
+	}
+	for name, v := range map[string]int{
+		"another worker":       p.Worker(4).Int(),
+		"another label":        other.Worker(3).Int(),
+		"the shared generator": shared.Int(),
+	} {
+		if v == first {
+			t.Errorf("worker 3 yields the same value as %s", name)
+		}
+	}
+
+	var wg sync.WaitGroup
+	values := make([]uint64, 8)
+	for i := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := p.Get()
+			defer p.Put(r)
+			for range 1000 {
+				values[i] ^= r.Uint64()
+			}
+		}()
+	}
+	wg.Wait()
+	seen := make(map[uint64]bool)
+	for _, v := range values {
+		seen[v] = true
+	}
+	if len(seen) < 2 {
+		t.Error("the goroutines drew the same sequence")
+	}
+}
@@ -0,0 +1,56 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTorAddr is where a local Tor daemon listens for SOCKS connections
+const DefaultTorAddr = "127.0.0.1:9050"
+
+// WithProxy sends every request through the proxy at u, an http, https or socks5 URL. Host names are resolved by socks5 proxies,
+// not locally. It is ignored if WithHTTPClient is used.
+func WithProxy(u *url.URL) Option {
+	return func(c *Client) {
+		c.transport.proxy = http.ProxyURL(u)
+	}
+}
+
+// parseProxyURL parses the URL of a proxy the transport supports
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't parse the proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, errors.New("Unsupported proxy scheme: " + u.Scheme)
+	}
+	return u, nil
+}
+
+// NewClientViaProxy returns a client fetching pulses through the proxy at proxyURL, e.g. socks5://127.0.0.1:1080
+func NewClientViaProxy(proxyURL string, opts ...Option) (*Client, error) {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(append([]Option{WithProxy(u)}, opts...)...), nil
+}
+
+// NewClientViaTor returns a client fetching pulses through the Tor SOCKS proxy at addr, DefaultTorAddr if empty,
+// with timeouts suited to the latency of Tor circuits
+func NewClientViaTor(addr string, opts ...Option) (*Client, error) {
+	if addr == "" {
+		addr = DefaultTorAddr
+	}
+	return NewClientViaProxy("socks5://"+addr, append([]Option{
+		WithTimeout(2 * time.Minute),
+		WithDialTimeout(time.Minute),
+		WithTLSHandshakeTimeout(time.Minute),
+	}, opts...)...)
+}
@@ -0,0 +1,162 @@
+// Package proxy serves the Beacon 2.0 REST paths from a local cache or archive, fetching records upstream only when needed.
+// A fleet of services can share one upstream connection through it, and keep working through short beacon outages.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive"
+)
+
+// PathPrefix is the path the proxy serves the beacon API under, point clients at the proxy's URL followed by it
+const PathPrefix = "/beacon/2.0"
+
+// Server is an http.Handler mirroring the beacon API. Timestamp lookups go through its client, configure it with beacon.WithCache
+// to answer them locally.
+type Server struct {
+	client  *beacon.Client
+	archive *archive.Archive
+	log     *slog.Logger
+	mux     *http.ServeMux
+
+	mu   sync.Mutex
+	last beacon.Record
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithArchive makes the server archive every record it fetches and answer chain and pulse index lookups from a
+func WithArchive(a *archive.Archive) Option {
+	return func(s *Server) {
+		s.archive = a
+	}
+}
+
+// WithLogger makes the server log the records it couldn't archive to l. By default nothing is logged.
+func WithLogger(l *slog.Logger) Option {
+	return func(s *Server) {
+		s.log = l
+	}
+}
+
+// New returns a server fetching records upstream with c
+func New(c *beacon.Client, opts ...Option) *Server {
+	s := &Server{client: c, log: slog.New(slog.DiscardHandler), mux: http.NewServeMux()}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.mux.HandleFunc("GET /pulse/last", func(w http.ResponseWriter, r *http.Request) {
+		s.serve(w, r, s.lastRecord)
+	})
+	s.handleTime("GET /pulse/time/{ts}", c.CurrentRecord)
+	s.handleTime("GET /pulse/time/previous/{ts}", c.PreviousRecord)
+	s.handleTime("GET /pulse/time/next/{ts}", c.NextRecord)
+	s.mux.HandleFunc("GET /chain/{chain}/pulse/{pulse}", func(w http.ResponseWriter, r *http.Request) {
+		chain, err1 := strconv.Atoi(r.PathValue("chain"))
+		pulse, err2 := strconv.Atoi(r.PathValue("pulse"))
+		if err1 != nil || err2 != nil {
+			http.NotFound(w, r)
+			return
+		}
+		s.serve(w, r, func(ctx context.Context) (beacon.Record, error) {
+			return s.byIndex(ctx, chain, pulse)
+		})
+	})
+	s.mux.HandleFunc("GET /certificate/{id}", func(w http.ResponseWriter, r *http.Request) {
+		cert, err := c.Certificate(r.Context(), r.PathValue("id"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	})
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, PathPrefix)
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleTime(pattern string, fetch func(context.Context, time.Time) (beacon.Record, error)) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		t, ok := beacon.ParseTimeParam(r.PathValue("ts"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		s.serve(w, r, func(ctx context.Context) (beacon.Record, error) {
+			return fetch(ctx, t)
+		})
+	})
+}
+
+// lastRecord returns the latest record, only asking upstream once the next pulse is due. If upstream fails, the last known record is served.
+func (s *Server) lastRecord(ctx context.Context) (beacon.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last.Pulse.URI != "" && time.Now().Before(s.last.NextPulseTime()) {
+		return s.last, nil
+	}
+
+	rec, err := s.client.LastRecord(ctx)
+	if err != nil && !errors.Is(err, beacon.ErrStale) {
+		if s.last.Pulse.URI != "" {
+			return s.last, nil
+		}
+		return rec, err
+	}
+	// clients judge staleness themselves
+	s.last = rec
+	return rec, nil
+}
+
+// byIndex returns the record with the given chain and pulse index, from the archive if possible
+func (s *Server) byIndex(ctx context.Context, chain, pulse int) (beacon.Record, error) {
+	if s.archive != nil {
+		if rec, err := s.archive.GetByIndex(chain, pulse); err == nil {
+			return rec, nil
+		}
+	}
+	return s.client.PulseByIndex(ctx, uint64(chain), uint64(pulse))
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, fetch func(context.Context) (beacon.Record, error)) {
+	rec, err := fetch(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if s.archive != nil {
+		// the record is served anyway, the archive missing it only costs an upstream request later
+		if err := s.archive.Put(rec); err != nil {
+			s.log.Warn("Couldn't archive the record", "pulse", rec, "err", err)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, beacon.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/archive/boltstore"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := beacontest.NewServer()
+	a, err := boltstore.Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	srv := httptest.NewServer(New(upstream.Client(beacon.WithRetry(beacon.RetryPolicy{MaxAttempts: 1})), WithArchive(a)))
+	defer srv.Close()
+	c := beacon.NewClient(beacon.WithBaseURL(srv.URL + PathPrefix))
+	ctx := context.Background()
+
+	last, err := c.LastRecord(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Verify(ctx, last); err != nil {
+		t.Errorf("the proxied record failed verification: %v", err)
+	}
+	want, _ := upstream.Record(last.Pulse.PulseIndex)
+	if last.Pulse.OutputValue != want.Pulse.OutputValue {
+		t.Error("the proxy served a different record")
+	}
+
+	prev, err := c.PreviousRecord(ctx, last.Pulse.TimeStamp)
+	if err != nil || prev.Pulse.PulseIndex != last.Pulse.PulseIndex-1 {
+		t.Errorf("previous record: got pulse %d, %v", prev.Pulse.PulseIndex, err)
+	}
+	if _, err := c.NextRecord(ctx, last.Pulse.TimeStamp.Add(time.Hour)); !errors.Is(err, beacon.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a future pulse, got %v", err)
+	}
+	if _, err := a.Get(prev.Pulse.TimeStamp); err != nil {
+		t.Errorf("the proxy didn't archive the record it served: %v", err)
+	}
+
+	// the proxy keeps serving through an upstream outage
+	upstream.Close()
+	again, err := c.LastRecord(ctx)
+	if err != nil || again.Pulse.PulseIndex != last.Pulse.PulseIndex {
+		t.Errorf("during an outage got pulse %d, %v", again.Pulse.PulseIndex, err)
+	}
+	byIndex, err := c.GetRecord(ctx, srv.URL+PathPrefix+"/chain/"+strconv.Itoa(prev.Pulse.ChainIndex)+"/pulse/"+strconv.Itoa(prev.Pulse.PulseIndex))
+	if err != nil || byIndex.Pulse.OutputValue != prev.Pulse.OutputValue {
+		t.Errorf("archived lookup by index during an outage: %v", err)
+	}
+}
+
+func TestArchiveFailure(t *testing.T) {
+	upstream := beacontest.NewServer()
+	defer upstream.Close()
+	path := filepath.Join(t.TempDir(), "archive.db")
+	a, err := boltstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Close()
+	// puts fail on a read-only archive
+	if a, err = boltstore.OpenReadOnly(path); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	var logs bytes.Buffer
+	srv := httptest.NewServer(New(upstream.Client(), WithArchive(a), WithLogger(slog.New(slog.NewTextHandler(&logs, nil)))))
+	defer srv.Close()
+	if _, err := beacon.NewClient(beacon.WithBaseURL(srv.URL + PathPrefix)).LastRecord(context.Background()); err != nil {
+		t.Fatalf("expected the record to be served anyway: %v", err)
+	}
+	if !strings.Contains(logs.String(), "Couldn't archive the record") {
+		t.Errorf("expected the archive failure to be logged, got %q", logs.String())
+	}
+}
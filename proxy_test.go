@@ -0,0 +1,49 @@
+package beacon
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewClientViaProxy(t *testing.T) {
+	srv := fixtureServer(t)
+	target, _ := url.Parse(srv.URL)
+
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		r.URL.Scheme, r.URL.Host = target.Scheme, target.Host
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	defer proxy.Close()
+
+	c, err := NewClientViaProxy(proxy.URL, WithBaseURL("http://beacon.invalid"), WithoutStaleness(), WithoutVerification())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LastRecord(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if proxied != 1 {
+		t.Errorf("expected the request to go through the proxy, got %d proxied requests", proxied)
+	}
+
+	if _, err := NewClientViaProxy("ftp://proxy"); err == nil {
+		t.Error("expected an unsupported scheme to be rejected")
+	}
+	if _, err := NewClientViaTor(""); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package beacon
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// External is the external source value a pulse includes, such as a lottery draw, when the beacon has one
+type External struct {
+	SourceID   string `json:"sourceId"`
+	StatusCode int    `json:"statusCode"`
+	Value      string `json:"value"`
+}
+
+// Pulse holds every field of a pulse in the 2.0 format, hex encoded values as the beacon serves them, so records can be audited or
+// re-served as is. It marshals its timestamp in RFC 3339 with milliseconds, as the beacon does.
+type Pulse struct {
+	URI     string `json:"uri"`
+	Version string `json:"version"`
+	// CipherSuite identifies the hash and signature algorithms, 0 being SHA-512 and RSA PKCS #1 v1.5
+	CipherSuite int `json:"cipherSuite"`
+	// Period is the interval between pulses, in milliseconds
+	Period           int         `json:"period"`
+	CertificateID    string      `json:"certificateId"`
+	ChainIndex       int         `json:"chainIndex"`
+	PulseIndex       int         `json:"pulseIndex"`
+	TimeStamp        time.Time   `json:"timeStamp"`
+	LocalRandomValue string      `json:"localRandomValue"`
+	External         External    `json:"external"`
+	ListValues       []ListValue `json:"listValues"`
+	// PrecommitmentValue is the hash of the next pulse's local random value
+	PrecommitmentValue string `json:"precommitmentValue"`
+	// StatusCode flags pulses starting a new chain or following a gap, see StatusNewChain and StatusGap
+	StatusCode     int    `json:"statusCode"`
+	SignatureValue string `json:"signatureValue"`
+	OutputValue    string `json:"outputValue"`
+}
+
+// MarshalJSON encodes p with its timestamp in the beacon's format
+func (p Pulse) MarshalJSON() ([]byte, error) {
+	// pulse doesn't inherit this method
+	type pulse Pulse
+	return json.Marshal(struct {
+		pulse
+		TimeStamp string `json:"timeStamp"`
+	}{pulse(p), p.TimeStamp.UTC().Format(TimeStampFormat)})
+}
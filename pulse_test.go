@@ -0,0 +1,61 @@
+package beacon
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPulseJSON(t *testing.T) {
+	rec := fixtureRecord(t)
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Record
+	if err := json.Unmarshal(buf, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !back.Equal(rec) {
+		t.Error("the record changed through JSON")
+	}
+
+	// every field the beacon serves is kept, the timestamp in the beacon's format
+	fields := func(buf []byte) map[string]any {
+		var v struct {
+			Pulse map[string]any `json:"pulse"`
+		}
+		if err := json.Unmarshal(buf, &v); err != nil {
+			t.Fatal(err)
+		}
+		return v.Pulse
+	}
+	served, err := os.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, got := fields(served), fields(buf)
+	for k := range want {
+		if _, ok := got[k]; !ok {
+			t.Errorf("field %s is lost", k)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got fields %v, want %v", slices.Sorted(maps.Keys(got)), slices.Sorted(maps.Keys(want)))
+	}
+	if got["timeStamp"] != want["timeStamp"] {
+		t.Errorf("got timestamp %v, want %v", got["timeStamp"], want["timeStamp"])
+	}
+
+	rec.Pulse.TimeStamp = time.Date(2024, 3, 1, 10, 0, 0, 123456789, time.FixedZone("CET", 3600))
+	buf, err = json.Marshal(rec.Pulse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts := fields([]byte(`{"pulse":` + string(buf) + `}`))["timeStamp"]; ts != "2024-03-01T09:00:00.123Z" {
+		t.Errorf("got timestamp %v", ts)
+	}
+}
@@ -0,0 +1,477 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha3"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Source implements rand.Source and rand.Source64 on top of the SHAKE256 stream of a record's output value, see Record.Reader.
+// It can be passed to rand.New to get the full math/rand method set. Like the math/rand sources, it is not safe for concurrent use.
+//
+// WARNING: the values it produces are public, never use them as secrets.
+type Source struct {
+	out []byte
+	r   io.Reader
+	buf [8]byte
+}
+
+var _ rand.Source64 = (*Source)(nil)
+
+// NewSource returns a source seeded from the record's output value. All 512 bits are absorbed into the SHAKE256 state, none of them is
+// discarded, and the output value is the field the beacon designates as its random output.
+func NewSource(rec Record) (*Source, error) {
+	out, err := rec.outputBytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Source{out: out, r: rec.Reader()}, nil
+}
+
+// Uint64 returns the next 64 bits of the stream
+func (s *Source) Uint64() uint64 {
+	io.ReadFull(s.r, s.buf[:])
+	return binary.BigEndian.Uint64(s.buf[:])
+}
+
+// Int63 returns the next non-negative 63-bit integer of the stream
+func (s *Source) Int63() int64 {
+	return int64(s.Uint64() &^ (1 << 63))
+}
+
+// Seed restarts the stream from the record's output value mixed with seed, so the same seed always yields the same sequence
+func (s *Source) Seed(seed int64) {
+	h := sha3.NewSHAKE256()
+	h.Write(s.out)
+	binary.BigEndian.PutUint64(s.buf[:], uint64(seed))
+	h.Write(s.buf[:])
+	s.r = h
+}
+
+// Fallback decides how an updated generator behaves while its seed can't be refreshed
+type Fallback int
+
+const (
+	// FallbackKeepSeed keeps drawing values from the current seed, this is the default
+	FallbackKeepSeed Fallback = iota
+	// FallbackError makes the error-returning methods, such as IntE, return the refresh error instead of drawing from the current seed
+	FallbackError
+	// FallbackBlock makes every method wait until a refresh succeeds or the generator stops refreshing
+	FallbackBlock
+)
+
+// RandOption configures an updated generator
+type RandOption func(*Rand)
+
+// WithFallback sets how the generator behaves while its seed can't be refreshed
+func WithFallback(f Fallback) RandOption {
+	return func(r *Rand) {
+		r.fallback = f
+	}
+}
+
+// Rand is a pseudo random generator seeded from a beacon record. It is safe for concurrent use.
+type Rand struct {
+	mu      sync.Mutex
+	rec     Record
+	r       *rand.Rand
+	lastErr error
+
+	fallback Fallback
+	// refreshed is closed and replaced whenever the seed is refreshed, or closed when the generator stops refreshing
+	refreshed chan struct{}
+	stopped   bool
+}
+
+// NewRand returns a generator seeded from rec, it always yields the same sequence for the same record
+func NewRand(rec Record) (*Rand, error) {
+	src, err := NewSource(rec)
+	if err != nil {
+		return nil, err
+	}
+	return &Rand{rec: rec, r: rand.New(src), refreshed: make(chan struct{})}, nil
+}
+
+// NewUpdatedRand returns a generator seeded from the latest record of the default client, see Client.NewUpdatedRand
+func NewUpdatedRand(ctx context.Context, opts ...RandOption) (*Rand, error) {
+	return DefaultClient().NewUpdatedRand(ctx, opts...)
+}
+
+// NewUpdatedRand returns a generator seeded from the latest record. A background goroutine re-seeds it from every new pulse until ctx is done,
+// so drawing values never waits on the network. If a refresh fails the error is reported by LastError, and the generator follows
+// its fallback policy until a refresh succeeds.
+func (c *Client) NewUpdatedRand(ctx context.Context, opts ...RandOption) (*Rand, error) {
+	rec, err := c.LastRecord(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRand(rec)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	go r.refresh(ctx, c)
+	return r, nil
+}
+
+// refresh re-seeds the generator from c after every pulse period until ctx is done
+func (r *Rand) refresh(ctx context.Context, c *Client) {
+	defer r.stop()
+
+	rec := r.LastPulse()
+	wait := time.Until(rec.Pulse.TimeStamp.Add(recordPeriod(rec)))
+	for Sleep(ctx, wait) == nil {
+		retry := rec.RetryInterval()
+
+		next, err := c.LastRecord(ctx)
+		if err == nil && !next.Pulse.TimeStamp.After(rec.Pulse.TimeStamp) {
+			// the pulse is late, try again shortly
+			wait = retry
+			continue
+		}
+		var src *Source
+		if err == nil {
+			src, err = NewSource(next)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.log.Warn("Couldn't refresh the generator's seed", "err", err)
+			r.fail(err)
+			wait = retry
+			continue
+		}
+
+		r.reseed(next, src)
+		rec = next
+		wait = time.Until(rec.Pulse.TimeStamp.Add(recordPeriod(rec)))
+	}
+}
+
+// reseed switches the generator to rec, wakes up the callers waiting for a refresh
+func (r *Rand) reseed(rec Record, src *Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rec, r.r, r.lastErr = rec, rand.New(src), nil
+	close(r.refreshed)
+	r.refreshed = make(chan struct{})
+}
+
+// fail records a failed refresh
+func (r *Rand) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastErr = err
+}
+
+// stop records that the generator won't be refreshed anymore, waking up the callers waiting for a refresh
+func (r *Rand) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+	close(r.refreshed)
+}
+
+// lock locks the generator once its seed can be drawn from according to the fallback policy, returning the refresh error unless the
+// FallbackKeepSeed policy applies. The generator is locked even when an error is returned.
+func (r *Rand) lock() error {
+	r.mu.Lock()
+	if r.fallback == FallbackBlock {
+		for r.lastErr != nil && !r.stopped {
+			ch := r.refreshed
+			r.mu.Unlock()
+			<-ch
+			r.mu.Lock()
+		}
+	}
+	if r.fallback == FallbackKeepSeed {
+		return nil
+	}
+	return r.lastErr
+}
+
+// LastPulse returns the record the generator is currently seeded from
+func (r *Rand) LastPulse() Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rec
+}
+
+// LastError returns the error of the last failed refresh, or nil if the generator was refreshed since
+func (r *Rand) LastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastErr
+}
+
+// IntE is like Int, but returns the refresh error instead of a value when the FallbackError policy applies
+func (r *Rand) IntE() (int, error) {
+	defer r.mu.Unlock()
+	if err := r.lock(); err != nil {
+		return 0, err
+	}
+	return r.r.Int(), nil
+}
+
+// IntnE is like Intn, but returns the refresh error instead of a value when the FallbackError policy applies
+func (r *Rand) IntnE(n int) (int, error) {
+	defer r.mu.Unlock()
+	if err := r.lock(); err != nil {
+		return 0, err
+	}
+	return r.r.Intn(n), nil
+}
+
+// ReadE is like Read, but returns the refresh error instead of bytes when the FallbackError policy applies
+func (r *Rand) ReadE(p []byte) (int, error) {
+	defer r.mu.Unlock()
+	if err := r.lock(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// Int returns a non-negative pseudo-random int
+func (r *Rand) Int() int {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Int()
+}
+
+// Intn returns a pseudo-random int in [0, n) without modulo bias. It panics if n <= 0.
+func (r *Rand) Intn(n int) int {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+// Int63n returns a pseudo-random int64 in [0, n) without modulo bias. It panics if n <= 0.
+func (r *Rand) Int63n(n int64) int64 {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Int63n(n)
+}
+
+// uint64n returns an unbiased value in [0, n) by rejecting the draws above the largest multiple of n, the caller must hold the lock
+func (r *Rand) uint64n(n uint64) uint64 {
+	limit := ^uint64(0) - ^uint64(0)%n
+	for {
+		if v := r.r.Uint64(); v < limit {
+			return v % n
+		}
+	}
+}
+
+// Uint64n returns a pseudo-random uint64 in [0, n) without modulo bias. It panics if n == 0.
+func (r *Rand) Uint64n(n uint64) uint64 {
+	if n == 0 {
+		panic("invalid argument to Uint64n")
+	}
+	r.lock()
+	defer r.mu.Unlock()
+	return r.uint64n(n)
+}
+
+// IntBetween returns a pseudo-random int in [lo, hi], both ends included. It panics if hi < lo.
+func (r *Rand) IntBetween(lo, hi int) int {
+	if hi < lo {
+		panic("invalid arguments to IntBetween")
+	}
+	r.lock()
+	defer r.mu.Unlock()
+	// the span is computed unsigned, hi-lo overflowing int on the widest ranges
+	span := uint64(uint(hi) - uint(lo))
+	if span == math.MaxUint64 {
+		return int(r.r.Uint64())
+	}
+	return lo + int(r.uint64n(span+1))
+}
+
+// Roll returns the result of rolling a die with the given number of sides, between 1 and sides. It panics if sides <= 0.
+func (r *Rand) Roll(sides int) int {
+	return r.Intn(sides) + 1
+}
+
+// Coin returns the result of a fair coin toss
+func (r *Rand) Coin() bool {
+	return r.Intn(2) == 1
+}
+
+// Sample returns k distinct ints in [0, n), in the order they were drawn. It runs a partial Fisher-Yates shuffle, so it needs memory
+// proportional to k rather than n and the same generator state always selects the same items. It panics if k < 0 or k > n.
+func (r *Rand) Sample(n, k int) []int {
+	if k < 0 || k > n {
+		panic("invalid arguments to Sample")
+	}
+	r.lock()
+	defer r.mu.Unlock()
+
+	// swapped holds the positions of the virtual [0, n) array that no longer hold their own index
+	swapped := make(map[int]int, k)
+	at := func(i int) int {
+		if v, ok := swapped[i]; ok {
+			return v
+		}
+		return i
+	}
+	out := make([]int, k)
+	for i := range out {
+		j := i + int(r.uint64n(uint64(n-i)))
+		out[i] = at(j)
+		swapped[j] = at(i)
+	}
+	return out
+}
+
+// SampleSlice returns k distinct elements of s drawn with r.Sample. It panics if k < 0 or k > len(s).
+func SampleSlice[T any](r *Rand, s []T, k int) []T {
+	out := make([]T, k)
+	for i, j := range r.Sample(len(s), k) {
+		out[i] = s[j]
+	}
+	return out
+}
+
+// Float64 returns a pseudo-random float64 in [0.0, 1.0)
+func (r *Rand) Float64() float64 {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Float64()
+}
+
+// Float32 returns a pseudo-random float32 in [0.0, 1.0)
+func (r *Rand) Float32() float32 {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Float32()
+}
+
+// NormFloat64 returns a normally distributed float64 with mean 0 and standard deviation 1
+func (r *Rand) NormFloat64() float64 {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.NormFloat64()
+}
+
+// ExpFloat64 returns an exponentially distributed float64 with rate parameter 1
+func (r *Rand) ExpFloat64() float64 {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.ExpFloat64()
+}
+
+// Perm returns a pseudo-random permutation of the ints in [0, n)
+func (r *Rand) Perm(n int) []int {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Perm(n)
+}
+
+// Shuffle pseudo-randomizes the order of n elements, swap swaps the elements with indexes i and j. It panics if n < 0.
+func (r *Rand) Shuffle(n int, swap func(i, j int)) {
+	r.lock()
+	defer r.mu.Unlock()
+	r.r.Shuffle(n, swap)
+}
+
+// Shuffle shuffles s in place with r
+func Shuffle[T any](r *Rand, s []T) {
+	r.Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+}
+
+// Pick returns an element of s chosen with r. It panics if s is empty.
+func Pick[T any](r *Rand, s []T) T {
+	return s[r.Intn(len(s))]
+}
+
+// Weighted returns an index of weights chosen with a probability proportional to its weight. Weights must be finite and non-negative,
+// with at least one positive, otherwise it panics.
+func (r *Rand) Weighted(weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			panic("invalid weight passed to Weighted")
+		}
+		total += w
+	}
+	if total == 0 || math.IsInf(total, 0) {
+		panic("invalid weights passed to Weighted")
+	}
+
+	x := r.Float64() * total
+	last := 0
+	for i, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if x < w {
+			return i
+		}
+		x -= w
+		last = i
+	}
+	// rounding may leave x just above the last weight
+	return last
+}
+
+// WeightedPick returns an element of s chosen with a probability proportional to the weight at the same index, see Rand.Weighted.
+// It panics if s and weights have different lengths.
+func WeightedPick[T any](r *Rand, s []T, weights []float64) T {
+	if len(s) != len(weights) {
+		panic("WeightedPick needs as many weights as elements")
+	}
+	return s[r.Weighted(weights)]
+}
+
+// BigIntN returns a uniform pseudo-random value in [0, max), drawing as many bits as max needs and rejecting the values out of range.
+// It panics if max <= 0.
+func (r *Rand) BigIntN(max *big.Int) *big.Int {
+	if max.Sign() <= 0 {
+		panic("invalid argument to BigIntN")
+	}
+	bits := max.BitLen()
+	buf := make([]byte, (bits+7)/8)
+	// mask clears the bits of the top byte above the bit length of max
+	mask := byte(0xff >> (len(buf)*8 - bits))
+
+	r.lock()
+	defer r.mu.Unlock()
+	n := new(big.Int)
+	for {
+		for i := 0; i < len(buf); i += 8 {
+			var b [8]byte
+			binary.BigEndian.PutUint64(b[:], r.r.Uint64())
+			copy(buf[i:], b[:])
+		}
+		buf[0] &= mask
+		if n.SetBytes(buf).Cmp(max) < 0 {
+			return n
+		}
+	}
+}
+
+// Read fills p with pseudo-random bytes from the generator, it always returns len(p) and a nil error.
+// Like every value of the generator, the bytes are public: never use them for keys.
+func (r *Rand) Read(p []byte) (int, error) {
+	r.lock()
+	defer r.mu.Unlock()
+	return r.r.Read(p)
+}
+
+// Bytes returns n pseudo-random bytes from the generator
+func (r *Rand) Bytes(n int) []byte {
+	b := make([]byte, n)
+	r.Read(b)
+	return b
+}
@@ -0,0 +1,33 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestUpdatedRandRefreshes(t *testing.T) {
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-5*time.Second)), beacontest.WithPeriod(time.Second))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	r, err := srv.Client().NewUpdatedRand(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := r.LastPulse().Pulse.PulseIndex
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.LastPulse().Pulse.PulseIndex == first {
+		if time.Now().After(deadline) {
+			t.Fatal("the generator wasn't re-seeded from a new pulse")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := r.LastError(); err != nil {
+		t.Errorf("unexpected refresh error: %v", err)
+	}
+}
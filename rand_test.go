@@ -0,0 +1,360 @@
+package beacon
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSourceDeterministic(t *testing.T) {
+	rec := fixtureRecord(t)
+
+	a, err := NewSource(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewSource(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ra, rb := rand.New(a), rand.New(b)
+	for i := 0; i < 100; i++ {
+		if x, y := ra.Uint64(), rb.Uint64(); x != y {
+			t.Fatalf("sources diverged at %d: %d != %d", i, x, y)
+		}
+	}
+}
+
+func TestSourceSeed(t *testing.T) {
+	rec := fixtureRecord(t)
+	src, err := NewSource(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src.Seed(42)
+	first := src.Int63()
+	src.Seed(42)
+	if src.Int63() != first {
+		t.Error("re-seeding with the same seed didn't restart the stream")
+	}
+	src.Seed(43)
+	if src.Int63() == first {
+		t.Error("different seeds produced the same value")
+	}
+}
+
+func TestRandInt(t *testing.T) {
+	rec := fixtureRecord(t)
+	a, err := NewRand(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewRand(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Int() != b.Int() {
+		t.Error("the same record produced different values")
+	}
+}
+
+func TestRandBounded(t *testing.T) {
+	rec := fixtureRecord(t)
+	r, err := NewRand(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var faces [7]int
+	for i := 0; i < 6000; i++ {
+		faces[r.Roll(6)]++
+		if v := r.IntBetween(-3, 3); v < -3 || v > 3 {
+			t.Fatalf("IntBetween(-3, 3) returned %d", v)
+		}
+		if v := r.Uint64n(10); v >= 10 {
+			t.Fatalf("Uint64n(10) returned %d", v)
+		}
+		if v := r.Int63n(5); v < 0 || v >= 5 {
+			t.Fatalf("Int63n(5) returned %d", v)
+		}
+	}
+	if faces[0] != 0 {
+		t.Error("Roll(6) returned 0")
+	}
+	for face := 1; face <= 6; face++ {
+		if faces[face] < 800 || faces[face] > 1200 {
+			t.Errorf("face %d came up %d times out of 6000", face, faces[face])
+		}
+	}
+
+	if v := r.IntBetween(7, 7); v != 7 {
+		t.Errorf("IntBetween(7, 7) returned %d", v)
+	}
+	negative := 0
+	for i := 0; i < 100; i++ {
+		if r.IntBetween(math.MinInt, math.MaxInt) < 0 {
+			negative++
+		}
+	}
+	if negative == 0 || negative == 100 {
+		t.Errorf("IntBetween over the whole int range returned %d negative values out of 100", negative)
+	}
+
+	heads := 0
+	for i := 0; i < 1000; i++ {
+		if r.Coin() {
+			heads++
+		}
+	}
+	if heads < 400 || heads > 600 {
+		t.Errorf("got %d heads out of 1000 tosses", heads)
+	}
+}
+
+func TestRandSample(t *testing.T) {
+	rec := fixtureRecord(t)
+	a, _ := NewRand(rec)
+	b, _ := NewRand(rec)
+
+	got := a.Sample(1000, 50)
+	seen := make(map[int]bool)
+	for _, v := range got {
+		if v < 0 || v >= 1000 || seen[v] {
+			t.Fatalf("invalid or duplicate sample %d in %v", v, got)
+		}
+		seen[v] = true
+	}
+	for i, v := range b.Sample(1000, 50) {
+		if got[i] != v {
+			t.Fatal("the same record selected different samples")
+		}
+	}
+
+	all := a.Sample(5, 5)
+	seen = make(map[int]bool)
+	for _, v := range all {
+		seen[v] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("sampling every item returned %v", all)
+	}
+
+	names := []string{"alice", "bob", "carol", "dave"}
+	picked := SampleSlice(a, names, 2)
+	if len(picked) != 2 || picked[0] == picked[1] {
+		t.Errorf("SampleSlice returned %v", picked)
+	}
+}
+
+func TestRandDistributions(t *testing.T) {
+	rec := fixtureRecord(t)
+	r, _ := NewRand(rec)
+	ref := rand.New(must(NewSource(rec)))
+
+	if got, want := r.Float64(), ref.Float64(); got != want {
+		t.Errorf("Float64 = %v, expected %v", got, want)
+	}
+	if got, want := r.Float32(), ref.Float32(); got != want {
+		t.Errorf("Float32 = %v, expected %v", got, want)
+	}
+	if got, want := r.NormFloat64(), ref.NormFloat64(); got != want {
+		t.Errorf("NormFloat64 = %v, expected %v", got, want)
+	}
+	if got, want := r.ExpFloat64(), ref.ExpFloat64(); got != want {
+		t.Errorf("ExpFloat64 = %v, expected %v", got, want)
+	}
+	perm, want := r.Perm(10), ref.Perm(10)
+	for i := range perm {
+		if perm[i] != want[i] {
+			t.Fatalf("Perm = %v, expected %v", perm, want)
+		}
+	}
+}
+
+func must(src *Source, err error) *Source {
+	if err != nil {
+		panic(err)
+	}
+	return src
+}
+
+func TestShuffleAndPick(t *testing.T) {
+	rec := fixtureRecord(t)
+	a, _ := NewRand(rec)
+	b, _ := NewRand(rec)
+
+	x := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	y := append([]string(nil), x...)
+	Shuffle(a, x)
+	Shuffle(b, y)
+	for i := range x {
+		if x[i] != y[i] {
+			t.Fatalf("the same record shuffled differently: %v and %v", x, y)
+		}
+	}
+
+	if got := Pick(a, []int{42}); got != 42 {
+		t.Errorf("Pick from a single element returned %d", got)
+	}
+	if Pick(a, x) != Pick(b, y) {
+		t.Error("the same record picked different elements")
+	}
+}
+
+func TestRandWeighted(t *testing.T) {
+	rec := fixtureRecord(t)
+	r, _ := NewRand(rec)
+
+	counts := make([]int, 4)
+	for i := 0; i < 10000; i++ {
+		counts[r.Weighted([]float64{1, 0, 3, 6})]++
+	}
+	if counts[1] != 0 {
+		t.Errorf("an index with no weight was chosen %d times", counts[1])
+	}
+	for i, want := range []int{1000, 0, 3000, 6000} {
+		if d := counts[i] - want; d < -300 || d > 300 {
+			t.Errorf("index %d chosen %d times, expected about %d", i, counts[i], want)
+		}
+	}
+
+	if got := WeightedPick(r, []string{"never", "always"}, []float64{0, 1}); got != "always" {
+		t.Errorf("WeightedPick returned %q", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected all-zero weights to panic")
+		}
+	}()
+	r.Weighted([]float64{0, 0})
+}
+
+func TestRandBigIntN(t *testing.T) {
+	rec := fixtureRecord(t)
+	r, _ := NewRand(rec)
+
+	// a 521-bit bound, larger than the record's output value
+	max := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 521), big.NewInt(1))
+	large := false
+	for i := 0; i < 100; i++ {
+		v := r.BigIntN(max)
+		if v.Sign() < 0 || v.Cmp(max) >= 0 {
+			t.Fatalf("BigIntN returned %s out of range", v)
+		}
+		if v.BitLen() > 512 {
+			large = true
+		}
+	}
+	if !large {
+		t.Error("BigIntN never returned a value above 512 bits")
+	}
+
+	small := big.NewInt(3)
+	var counts [3]int
+	for i := 0; i < 3000; i++ {
+		counts[r.BigIntN(small).Int64()]++
+	}
+	for v, n := range counts {
+		if n < 850 || n > 1150 {
+			t.Errorf("%d drawn %d times out of 3000", v, n)
+		}
+	}
+}
+
+func TestRandRead(t *testing.T) {
+	rec := fixtureRecord(t)
+	a, _ := NewRand(rec)
+	b, _ := NewRand(rec)
+
+	var buf [32]byte
+	if n, err := a.Read(buf[:]); n != len(buf) || err != nil {
+		t.Fatalf("Read returned %d, %v", n, err)
+	}
+	if got := b.Bytes(32); string(got) != string(buf[:]) {
+		t.Error("the same record produced different bytes")
+	}
+	if string(a.Bytes(32)) == string(buf[:]) {
+		t.Error("consecutive reads returned the same bytes")
+	}
+}
+
+func TestRandFallback(t *testing.T) {
+	rec := fixtureRecord(t)
+	failure := errors.New("beacon unreachable")
+
+	keep, _ := NewRand(rec)
+	keep.fail(failure)
+	if _, err := keep.IntE(); err != nil {
+		t.Errorf("FallbackKeepSeed returned %v", err)
+	}
+
+	strict, _ := NewRand(rec)
+	WithFallback(FallbackError)(strict)
+	strict.fail(failure)
+	if _, err := strict.IntE(); err != failure {
+		t.Errorf("FallbackError returned %v", err)
+	}
+	if _, err := strict.ReadE(make([]byte, 8)); err != failure {
+		t.Errorf("FallbackError returned %v", err)
+	}
+
+	blocking, _ := NewRand(rec)
+	WithFallback(FallbackBlock)(blocking)
+	blocking.fail(failure)
+	done := make(chan error)
+	go func() {
+		_, err := blocking.IntnE(10)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("FallbackBlock returned %v before a refresh", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	src, _ := NewSource(rec)
+	blocking.reseed(rec, src)
+	if err := <-done; err != nil {
+		t.Errorf("FallbackBlock returned %v after a refresh", err)
+	}
+
+	blocking.fail(failure)
+	go func() {
+		_, err := blocking.IntE()
+		done <- err
+	}()
+	blocking.stop()
+	if err := <-done; err != failure {
+		t.Errorf("FallbackBlock returned %v once the generator stopped", err)
+	}
+}
+
+func TestSourceUsesWholeOutputValue(t *testing.T) {
+	rec := fixtureRecord(t)
+	src, _ := NewSource(rec)
+	want := src.Uint64()
+
+	// flipping any nibble of the output value must change the stream
+	for i := range rec.Pulse.OutputValue {
+		changed := rec
+		b := []byte(rec.Pulse.OutputValue)
+		if b[i] == '0' {
+			b[i] = '1'
+		} else {
+			b[i] = '0'
+		}
+		changed.Pulse.OutputValue = string(b)
+		src, err := NewSource(changed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if src.Uint64() == want {
+			t.Fatalf("changing hex digit %d of the output value didn't change the stream", i)
+		}
+	}
+}
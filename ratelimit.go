@@ -0,0 +1,128 @@
+package beacon
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitPause is how long requests to a host rejecting one with 429 without a Retry-After header are paused
+const defaultRateLimitPause = time.Second
+
+// maxRateLimitPause bounds the pause a Retry-After header can impose, so a bogus one can't stall the client indefinitely
+const maxRateLimitPause = 10 * time.Minute
+
+// limiter is a token bucket allowing rate requests per second with bursts of up to burst requests
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// WithRateLimit limits the client to rps requests per second, allowing bursts of burst requests.
+// The limit covers every request the client makes, including retries, range iterations and watchers.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if rps <= 0 {
+			c.limiter = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter = &limiter{rate: rps, burst: float64(burst), tokens: float64(burst)}
+	}
+}
+
+// wait blocks until a request may be sent or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	var d time.Duration
+	if l.tokens < 0 {
+		d = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if d == 0 {
+		return nil
+	}
+	if err := Sleep(ctx, d); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// pauses tracks the hosts that asked the client to hold off, so that every request to them waits rather than only the one rejected:
+// the workers of a backfill and the watchers sharing the client back off together
+type pauses struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// pause holds off the requests to host for d from now, unless they already are for longer
+func (p *pauses) pause(host string, d time.Duration) {
+	d = min(d, maxRateLimitPause)
+	until := time.Now().Add(d)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.until == nil {
+		p.until = make(map[string]time.Time)
+	}
+	if until.After(p.until[host]) {
+		p.until[host] = until
+	}
+}
+
+// wait blocks until requests to host may be sent again or ctx is done
+func (p *pauses) wait(ctx context.Context, host string) error {
+	p.mu.Lock()
+	until, ok := p.until[host]
+	if ok && !until.After(time.Now()) {
+		delete(p.until, host)
+	}
+	p.mu.Unlock()
+	if d := time.Until(until); ok && d > 0 {
+		return Sleep(ctx, d)
+	}
+	return nil
+}
+
+// parseRetryAfter returns the delay the Retry-After header of a response asks for, either in seconds or as a date. A date is taken
+// relative to the response's Date header when it has one, so that the delay doesn't depend on the local clock.
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(min(secs, int(maxRateLimitPause/time.Second))) * time.Second, true
+	}
+	at, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	now := time.Now()
+	if date, err := http.ParseTime(h.Get("Date")); err == nil {
+		now = date
+	}
+	return max(at.Sub(now), 0), true
+}
@@ -0,0 +1,118 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	srv := fixtureServer(t)
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRateLimit(50, 2))
+
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		if _, err := c.GetRecord(context.Background(), srv.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 requests in the burst, 5 more at 50 per second
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("7 requests took %s, expected at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	l := &limiter{rate: 1, burst: 1, tokens: 0}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Error("expected the wait to be cancelled")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	date := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value, date string
+		want        time.Duration
+		ok          bool
+	}{
+		{"", "", 0, false},
+		{"30", "", 30 * time.Second, true},
+		{"-1", "", 0, false},
+		{"86400", "", maxRateLimitPause, true},
+		{date.Add(2 * time.Minute).Format(http.TimeFormat), date.Format(http.TimeFormat), 2 * time.Minute, true},
+		{date.Format(http.TimeFormat), date.Add(time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", "", 0, false},
+	} {
+		h := http.Header{}
+		h.Set("Retry-After", tc.value)
+		if tc.date != "" {
+			h.Set("Date", tc.date)
+		}
+		if got, ok := parseRetryAfter(h); got != tc.want || ok != tc.ok {
+			t.Errorf("Retry-After %q: got %s, %v, want %s, %v", tc.value, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	rec := fixtureRecord(t)
+	var limited atomic.Bool
+	limited.Store(true)
+	var mu sync.Mutex
+	var served []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limited.Swap(false) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		mu.Lock()
+		served = append(served, time.Now())
+		mu.Unlock()
+		json.NewEncoder(w).Encode(rec)
+	}))
+	defer srv.Close()
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+
+	start := time.Now()
+	_, err := c.GetRecord(context.Background(), srv.URL+"/pulse/1")
+	var e *Error
+	if !errors.As(err, &e) || !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrUnavailable) || e.RetryAfter != time.Second {
+		t.Fatalf("expected a rate limited error asking to retry after a second, got %v", err)
+	}
+
+	// every request of the client holds off, not only the rejected one
+	var wg sync.WaitGroup
+	for _, path := range []string{"/pulse/2", "/pulse/3"} {
+		wg.Go(func() {
+			if _, err := c.GetRecord(context.Background(), srv.URL+path); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	for _, at := range served {
+		if at.Sub(start) < 900*time.Millisecond {
+			t.Errorf("request served %s after being rate limited", at.Sub(start))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c.paused.pause("example.org", time.Minute)
+	if err := c.paused.wait(ctx, "example.org"); err == nil {
+		t.Error("expected the wait to be cancelled")
+	}
+	if err := c.paused.wait(ctx, "example.com"); err != nil {
+		t.Errorf("expected other hosts not to be paused, got %v", err)
+	}
+}
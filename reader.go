@@ -0,0 +1,43 @@
+package beacon
+
+import (
+	"crypto/sha3"
+	"fmt"
+	"io"
+)
+
+// outputBytes decodes the record's 512-bit output value
+func (rec *Record) outputBytes() ([]byte, error) {
+	var out [64]byte
+	if err := decodeFixed(out[:], rec.Pulse.OutputValue); err != nil {
+		return nil, fmt.Errorf("Couldn't decode the record's output value: %w", err)
+	}
+	return out[:], nil
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// Reader returns an unlimited, deterministic stream of bytes obtained by expanding the record's output value through SHAKE256.
+// Every reader created from the same record yields the same stream, which makes it useful for reproducible test vectors.
+//
+// WARNING: the output value is published by the beacon, so anyone can reproduce this stream. Never use it for secret keys.
+func (rec *Record) Reader() io.Reader {
+	out, err := rec.outputBytes()
+	if err != nil {
+		return errReader{err}
+	}
+	return shake(out)
+}
+
+// shake returns the SHAKE256 stream of b
+func shake(b []byte) io.Reader {
+	h := sha3.NewSHAKE256()
+	h.Write(b)
+	return h
+}
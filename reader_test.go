@@ -0,0 +1,52 @@
+package beacon
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func fixtureRecord(t *testing.T) Record {
+	buf, err := ioutil.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec Record
+	if err := json.Unmarshal(buf, &rec); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestReaderDeterministic(t *testing.T) {
+	rec := fixtureRecord(t)
+
+	a := make([]byte, 200)
+	if _, err := io.ReadFull(rec.Reader(), a); err != nil {
+		t.Fatal(err)
+	}
+
+	r := rec.Reader()
+	b := make([]byte, 200)
+	if _, err := io.ReadFull(r, b[:37]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(r, b[37:]); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(a, b) {
+		t.Error("readers from the same record produced different streams")
+	}
+}
+
+func TestReaderInvalidOutput(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.OutputValue = "not hex"
+
+	if _, err := rec.Reader().Read(make([]byte, 8)); err == nil {
+		t.Error("expected an error for an invalid output value")
+	}
+}
@@ -0,0 +1,89 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"time"
+)
+
+// maxRateLimitRetries is how many times Records retries a request the beacon rejected as rate limited or unavailable
+const maxRateLimitRetries = 5
+
+// Sleep waits for d or until ctx is done, returning the error of ctx in that case
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// nextRecord fetches the record after t, backing off while the beacon rate limits the client
+func (c *Client) nextRecord(ctx context.Context, t time.Time) (Record, error) {
+	return backoff(ctx, func() (Record, error) { return c.NextRecord(ctx, t) })
+}
+
+// Records walks the chain from the first record at or after from up to the last record at or before to, following next links.
+// Pulses missing from the chain are skipped and rate limited requests are retried after a backoff. The iteration stops after
+// yielding an error, which happens on failed requests or when ctx is cancelled. It ends silently if the beacon has no record after the last one yielded.
+// Use Events to be told about the gaps.
+func (c *Client) Records(ctx context.Context, from, to time.Time) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		for ev, err := range c.Events(ctx, from, to) {
+			if ev.Gap != nil {
+				continue
+			}
+			if !yield(ev.Record, err) {
+				return
+			}
+		}
+	}
+}
+
+// Events walks the chain as Records does, yielding an event for every record and, before a record following an outage, an event
+// describing the gap. Gaps are recognized by the StatusGap status code as well as by the time elapsed since the previous record.
+func (c *Client) Events(ctx context.Context, from, to time.Time) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		t := from.Add(-time.Second)
+		var prev *Record
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(Event{}, err)
+				return
+			}
+
+			rec, err := c.nextRecord(ctx, t)
+			if err == nil && prev != nil && !rec.Pulse.TimeStamp.After(t) {
+				// the beacon didn't move past the previous record, skip forward by index instead of asking again
+				rec, err = backoff(ctx, func() (Record, error) {
+					return c.PulseByIndex(ctx, uint64(prev.Pulse.ChainIndex), uint64(prev.Pulse.PulseIndex+1))
+				})
+			}
+			if errors.Is(err, ErrNotFound) {
+				return
+			}
+			if err != nil {
+				yield(Event{}, err)
+				return
+			}
+
+			if rec.Pulse.TimeStamp.After(to) || !rec.Pulse.TimeStamp.After(t) {
+				return
+			}
+			if prev != nil {
+				if gap, ok := gapBefore(*prev, rec); ok && !yield(Event{Gap: &gap}, nil) {
+					return
+				}
+			}
+			if !yield(Event{Record: rec}, nil) {
+				return
+			}
+			t = rec.Pulse.TimeStamp
+			prev = &rec
+		}
+	}
+}
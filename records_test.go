@@ -0,0 +1,123 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// chainServer serves recs, ordered by timestamp, on the next-record endpoint. The first limited requests are rejected with 429.
+func chainServer(t *testing.T, recs []Record, limited int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&limited, -1) >= 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		ts, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/pulse/time/next/"), 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		for _, rec := range recs {
+			if rec.Pulse.TimeStamp.Unix() > ts {
+				json.NewEncoder(w).Encode(rec)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRecords(t *testing.T) {
+	recs := fixtureChain(t, 6)
+	gapped := append(append([]Record{}, recs[:2]...), recs[3:]...)
+	srv := chainServer(t, gapped, 1)
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+
+	var got []int
+	for rec, err := range c.Records(context.Background(), recs[0].Pulse.TimeStamp, recs[4].Pulse.TimeStamp) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rec.Pulse.PulseIndex)
+	}
+
+	want := []int{1000, 1001, 1003, 1004}
+	if len(got) != len(want) {
+		t.Fatalf("got pulses %v, expected %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got pulses %v, expected %v", got, want)
+		}
+	}
+}
+
+func TestRecordsCancel(t *testing.T) {
+	recs := fixtureChain(t, 3)
+	srv := chainServer(t, recs, 0)
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var n int
+	var last error
+	for _, err := range c.Records(ctx, recs[0].Pulse.TimeStamp, recs[2].Pulse.TimeStamp.Add(time.Hour)) {
+		if err != nil {
+			last = err
+			break
+		}
+		n++
+		cancel()
+	}
+	if n != 1 || last != context.Canceled {
+		t.Errorf("expected the iteration to stop after cancellation, got %d records and %v", n, last)
+	}
+}
+
+func TestRecordsSkipForward(t *testing.T) {
+	recs := fixtureChain(t, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if pulse, ok := strings.CutPrefix(r.URL.Path, "/chain/2/pulse/"); ok {
+			n, _ := strconv.Atoi(pulse)
+			if i := n - recs[0].Pulse.PulseIndex; i >= 0 && i < len(recs) {
+				json.NewEncoder(w).Encode(recs[i])
+				return
+			}
+			http.NotFound(w, r)
+			return
+		}
+		ts, _ := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/pulse/time/next/"), 10, 64)
+		for i, rec := range recs {
+			if rec.Pulse.TimeStamp.Unix() > ts {
+				if i == 2 {
+					// a beacon stuck on the previous pulse
+					rec = recs[1]
+				}
+				json.NewEncoder(w).Encode(rec)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	var got []int
+	for rec, err := range NewClient(WithoutVerification(), WithBaseURL(srv.URL)).Records(context.Background(), recs[0].Pulse.TimeStamp, recs[3].Pulse.TimeStamp) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rec.Pulse.PulseIndex)
+	}
+	if len(got) != 4 || got[2] != 1002 || got[3] != 1003 {
+		t.Errorf("expected the stuck pulse to be skipped by index, got pulses %v", got)
+	}
+}
@@ -0,0 +1,212 @@
+// Package rediscache implements a beacon.Cache stored in Redis, so the replicas of a service share the records they fetch. When several
+// replicas miss the same record at once, one of them fetches it from the beacon while the others wait for it to appear in the cache.
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// DefaultPrefix is prepended to the keys of a cache created without WithPrefix
+const DefaultPrefix = "beacon:"
+
+// DefaultTTL is how long historical records are kept by a cache created without WithTTL
+const DefaultTTL = 24 * time.Hour
+
+// DefaultWait is how long a replica waits for another one to fetch a missing record, unless the cache is created with WithWait. It
+// exceeds the latency of a beacon request, not its timeout: a fetch that fails releases the waiting replicas at once.
+const DefaultWait = time.Second
+
+// pollInterval is how often a waiting replica looks for the record being fetched by another one
+const pollInterval = 50 * time.Millisecond
+
+// Cache is a beacon.Cache backed by Redis. It is safe for concurrent use, and by several processes sharing the same Redis server.
+type Cache struct {
+	rdb    redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+	wait   time.Duration
+	certs  *beacon.CertificateManager
+
+	mu sync.Mutex
+	// held maps the timestamps whose lock this cache took to the lock's expiry, until it puts or releases their record
+	held map[time.Time]time.Time
+}
+
+var _ beacon.CoordinatedCache = (*Cache)(nil)
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithPrefix sets the prefix of the cache's keys, DefaultPrefix by default
+func WithPrefix(prefix string) Option {
+	return func(c *Cache) {
+		c.prefix = prefix
+	}
+}
+
+// WithTTL sets how long historical records are kept, DefaultTTL by default. A zero duration keeps them forever.
+// The latest record always expires once its pulse period has elapsed.
+func WithTTL(d time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = d
+	}
+}
+
+// WithWait sets how long a replica waits for another one already fetching a missing record before fetching it itself,
+// DefaultWait by default. A zero duration disables the coordination between replicas.
+func WithWait(d time.Duration) Option {
+	return func(c *Cache) {
+		c.wait = d
+	}
+}
+
+// WithCertificates makes the cache verify the signature of records with the certificates of m, both before storing them and after
+// reading them, so a replica can't be served a record it wouldn't have accepted from the beacon
+func WithCertificates(m *beacon.CertificateManager) Option {
+	return func(c *Cache) {
+		c.certs = m
+	}
+}
+
+// New returns a cache storing records in rdb
+func New(rdb redis.UniversalClient, opts ...Option) *Cache {
+	c := &Cache{
+		rdb:    rdb,
+		prefix: DefaultPrefix,
+		ttl:    DefaultTTL,
+		wait:   DefaultWait,
+		held:   make(map[time.Time]time.Time),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Cache) key(t time.Time) string {
+	return c.prefix + "pulse:" + strconv.FormatInt(t.Unix(), 10)
+}
+
+func (c *Cache) lockKey(t time.Time) string {
+	return c.prefix + "lock:" + strconv.FormatInt(t.Unix(), 10)
+}
+
+// Get returns the record whose pulse timestamp is t, if it is cached, as GetContext does without a deadline
+func (c *Cache) Get(t time.Time) (beacon.Record, bool) {
+	return c.GetContext(context.Background(), t)
+}
+
+// GetContext returns the record whose pulse timestamp is t, if it is cached. On a miss, the first replica is told to fetch the record
+// while the others wait for it to be stored, up to the cache's wait duration or until ctx is done. Redis errors are reported as misses.
+func (c *Cache) GetContext(ctx context.Context, t time.Time) (beacon.Record, bool) {
+	if rec, ok := c.get(ctx, t); ok {
+		return rec, true
+	}
+	if c.wait <= 0 {
+		return beacon.Record{}, false
+	}
+
+	// whoever takes the lock fetches the record
+	taken, err := c.rdb.SetNX(ctx, c.lockKey(t), 1, c.wait).Result()
+	if err != nil {
+		return beacon.Record{}, false
+	}
+	if taken {
+		now := time.Now()
+		c.mu.Lock()
+		for k, expiry := range c.held {
+			if now.After(expiry) {
+				delete(c.held, k)
+			}
+		}
+		c.held[t] = now.Add(c.wait)
+		c.mu.Unlock()
+		return beacon.Record{}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.wait)
+	defer cancel()
+	tick := time.NewTicker(pollInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return beacon.Record{}, false
+		case <-tick.C:
+		}
+		if rec, ok := c.get(ctx, t); ok {
+			return rec, true
+		}
+		if n, err := c.rdb.Exists(ctx, c.lockKey(t)).Result(); err != nil || n == 0 {
+			// the fetch failed or the lock expired
+			return c.get(ctx, t)
+		}
+	}
+}
+
+// Release releases the replicas waiting for the record under t if this cache took its lock, the record couldn't be fetched
+func (c *Cache) Release(t time.Time) {
+	c.mu.Lock()
+	expiry, held := c.held[t]
+	delete(c.held, t)
+	c.mu.Unlock()
+	// an expired lock may have been taken by another replica since
+	if held && time.Now().Before(expiry) {
+		c.rdb.Del(context.Background(), c.lockKey(t))
+	}
+}
+
+func (c *Cache) get(ctx context.Context, t time.Time) (beacon.Record, bool) {
+	buf, err := c.rdb.Get(ctx, c.key(t)).Bytes()
+	if err != nil {
+		return beacon.Record{}, false
+	}
+	var rec beacon.Record
+	if err := json.Unmarshal(buf, &rec); err != nil {
+		return beacon.Record{}, false
+	}
+	if !c.verified(ctx, rec) {
+		return beacon.Record{}, false
+	}
+	return rec, true
+}
+
+func (c *Cache) verified(ctx context.Context, rec beacon.Record) bool {
+	if c.certs == nil {
+		return true
+	}
+	cert, err := c.certs.ForRecord(ctx, rec)
+	return err == nil && beacon.Verify(rec, cert) == nil
+}
+
+// Put caches rec under t, for one pulse period if it is the latest record and for the cache's TTL otherwise, and releases the
+// replicas waiting for it. Records failing verification and Redis errors are ignored.
+func (c *Cache) Put(t time.Time, rec beacon.Record) {
+	ctx := context.Background()
+	if !c.verified(ctx, rec) {
+		return
+	}
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+
+	ttl := c.ttl
+	if next := rec.NextPulseTime(); time.Now().Before(next) {
+		ttl = next.Sub(rec.Pulse.TimeStamp)
+	}
+	c.mu.Lock()
+	delete(c.held, t)
+	c.mu.Unlock()
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, c.key(t), buf, ttl)
+	pipe.Del(ctx, c.lockKey(t))
+	pipe.Exec(ctx)
+}
@@ -0,0 +1,131 @@
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-time.Hour)))
+	defer srv.Close()
+	recs := srv.Records()
+	old, last := recs[0], recs[len(recs)-1]
+
+	certs := beacon.NewCertificateManager(srv.Client())
+	c := New(rdb, WithCertificates(certs), WithTTL(time.Hour))
+	c.Put(old.Pulse.TimeStamp, old)
+	c.Put(last.Pulse.TimeStamp, last)
+	if got, ok := c.Get(old.Pulse.TimeStamp); !ok || got.Pulse.OutputValue != old.Pulse.OutputValue {
+		t.Fatal("expected the record to be cached")
+	}
+	if ttl := mr.TTL(c.key(old.Pulse.TimeStamp)); ttl != time.Hour {
+		t.Errorf("expected historical records to be kept for the TTL, got %s", ttl)
+	}
+	if ttl := mr.TTL(c.key(last.Pulse.TimeStamp)); ttl != time.Minute {
+		t.Errorf("expected the latest record to be kept for a period, got %s", ttl)
+	}
+
+	forged := old
+	forged.Pulse.TimeStamp = old.Pulse.TimeStamp.Add(-time.Hour)
+	forged.Pulse.OutputValue = last.Pulse.OutputValue
+	c.Put(forged.Pulse.TimeStamp, forged)
+	if mr.Exists(c.key(forged.Pulse.TimeStamp)) {
+		t.Error("expected a record failing verification not to be stored")
+	}
+}
+
+func TestCacheCoordinatesReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-time.Hour)))
+	defer srv.Close()
+	ts := srv.Records()[10].Pulse.TimeStamp
+
+	var requests atomic.Int32
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			defer rdb.Close()
+			c := New(rdb)
+			if _, ok := c.Get(ts); ok {
+				return
+			}
+			// this replica fetches the record
+			requests.Add(1)
+			rec, err := srv.Client().CurrentRecord(context.Background(), ts)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+			c.Put(ts, rec)
+		}()
+	}
+	wg.Wait()
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected a single replica to fetch the record, got %d", n)
+	}
+}
+
+func TestCacheReleasesFailedFetches(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	srv := beacontest.NewServer()
+	defer srv.Close()
+	fetcher, waiter := New(rdb, WithWait(time.Minute)), New(rdb, WithWait(time.Minute))
+	next := time.Now().Add(time.Hour).Truncate(time.Minute)
+
+	// the client releases the lock it took when the pulse isn't published yet
+	c := srv.Client(beacon.WithCache(fetcher))
+	if _, err := c.NextRecord(context.Background(), next); !errors.Is(err, beacon.ErrNotFound) {
+		t.Fatalf("expected the future pulse not to be found, got %v", err)
+	}
+	if mr.Exists(fetcher.lockKey(next.Add(time.Minute))) {
+		t.Error("expected the lock to be released")
+	}
+
+	if _, ok := fetcher.Get(next); ok {
+		t.Fatal("expected a miss")
+	}
+	done := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		waiter.Get(next)
+		done <- time.Since(start)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	fetcher.Release(next)
+	if d := <-done; d > 10*time.Second {
+		t.Errorf("the waiting replica was released after %s", d)
+	}
+
+	// a waiting replica gives up with its caller
+	if _, ok := fetcher.Get(next); ok {
+		t.Fatal("expected a miss")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, ok := waiter.GetContext(ctx, next); ok {
+		t.Error("expected a miss")
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("the lookup outlived its context by %s", d)
+	}
+}
@@ -0,0 +1,67 @@
+package beacon
+
+import (
+	"crypto/x509"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Era is a period during which the beacon signed its pulses with one certificate
+type Era struct {
+	CertificateID string
+	// From and To bound the timestamps of the pulses signed with the certificate, a zero To means the era is still current
+	From, To    time.Time
+	Certificate *x509.Certificate
+}
+
+// contains reports whether t falls within the era
+func (e *Era) contains(t time.Time) bool {
+	return !t.Before(e.From) && (e.To.IsZero() || t.Before(e.To))
+}
+
+// CertificateRegistry maps the beacon's signing certificates to the eras they were used in, so records from any era are verified
+// with the right key, and a certificate is never accepted for a record emitted outside of its era
+type CertificateRegistry struct {
+	eras []Era
+}
+
+// NewCertificateRegistry returns a registry of the given eras
+func NewCertificateRegistry(eras ...Era) *CertificateRegistry {
+	r := &CertificateRegistry{eras: append([]Era(nil), eras...)}
+	sort.Slice(r.eras, func(i, j int) bool { return r.eras[i].From.Before(r.eras[j].From) })
+	return r
+}
+
+// Eras returns the registry's eras, oldest first
+func (r *CertificateRegistry) Eras() []Era {
+	return append([]Era(nil), r.eras...)
+}
+
+// ForRecord returns the certificate that signed rec: the one of its certificate id, or of its era if it has none.
+// It returns ErrNotFound if no certificate is registered for the record's era.
+func (r *CertificateRegistry) ForRecord(rec Record) (*x509.Certificate, error) {
+	t := rec.Pulse.TimeStamp
+	id := rec.Pulse.CertificateID
+	for i := range r.eras {
+		e := &r.eras[i]
+		if id != "" && !strings.EqualFold(e.CertificateID, id) {
+			continue
+		}
+		if e.contains(t) {
+			return e.Certificate, nil
+		}
+	}
+	if id != "" {
+		return nil, &Error{Kind: ErrNotFound, Err: errors.New("No era of certificate " + id + " covers " + t.UTC().Format(time.RFC3339))}
+	}
+	return nil, &Error{Kind: ErrNotFound, Err: errors.New("No certificate registered for " + t.UTC().Format(time.RFC3339))}
+}
+
+// WithCertificateRegistry makes the client verify records against the certificates of r instead of fetching certificates
+func WithCertificateRegistry(r *CertificateRegistry) Option {
+	return func(c *Client) {
+		c.registry = r
+	}
+}
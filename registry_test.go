@@ -0,0 +1,53 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCertificateRegistry(t *testing.T) {
+	oldKey, oldCert, _ := testCertificate(t)
+	newKey, newCert, _ := testCertificate(t)
+	rotation := time.Date(2020, 1, 1, 0, 0, 10, 0, time.UTC)
+
+	old := fixtureRecord(t)
+	old.Pulse.CertificateID = "aa"
+	signRecord(t, oldKey, &old)
+	recent := fixtureRecord(t)
+	recent.Pulse.CertificateID = "bb"
+	recent.Pulse.TimeStamp = rotation.Add(time.Minute)
+	signRecord(t, newKey, &recent)
+
+	r := NewCertificateRegistry(
+		Era{CertificateID: "BB", From: rotation, Certificate: newCert},
+		Era{CertificateID: "AA", From: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), To: rotation, Certificate: oldCert},
+	)
+	if eras := r.Eras(); eras[0].CertificateID != "AA" {
+		t.Error("eras aren't ordered by start")
+	}
+
+	c := NewClient(WithCertificateRegistry(r))
+	ctx := context.Background()
+	for _, rec := range []Record{old, recent} {
+		if err := c.Verify(ctx, rec); err != nil {
+			t.Errorf("pulse at %s: %v", rec.Pulse.TimeStamp, err)
+		}
+	}
+
+	// the old certificate isn't accepted after its era ended
+	late := old
+	late.Pulse.TimeStamp = rotation.Add(time.Hour)
+	signRecord(t, oldKey, &late)
+	if _, err := r.ForRecord(late); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound outside of the era, got %v", err)
+	}
+
+	// records without a certificate id are matched by time
+	anonymous := recent
+	anonymous.Pulse.CertificateID = ""
+	if cert, err := r.ForRecord(anonymous); err != nil || cert != newCert {
+		t.Errorf("expected the current era's certificate, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries failed requests. Delays grow exponentially from BaseDelay up to MaxDelay, with full jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, 1 disables retries
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// RetryableStatus lists the HTTP status codes worth retrying. Network errors and attempts exceeding the client's timeout are always
+	// retried while the request's context isn't done, malformed responses never.
+	RetryableStatus []int
+}
+
+// DefaultRetryPolicy retries transient server errors and rate limiting a few times within a few seconds
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     4,
+	BaseDelay:       500 * time.Millisecond,
+	MaxDelay:        10 * time.Second,
+	RetryableStatus: []int{429, 500, 502, 503, 504},
+}
+
+// WithRetry makes the client retry failed requests according to p. Without it requests are attempted once. A retry waits at least as
+// long as the Retry-After header of the failed response asks, and a 429 holds off every request of the client to that host meanwhile.
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// Delay returns how long to wait before the given retry, starting at 0
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	// doubling stops at MaxDelay, before it could overflow
+	d := p.BaseDelay
+	for i := 0; i < retry && d > 0 && d < p.MaxDelay && d <= math.MaxInt64/2; i++ {
+		d <<= 1
+	}
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		return false
+	}
+	code := statusCode(err)
+	if code == 0 {
+		return true
+	}
+	for _, c := range p.RetryableStatus {
+		if code == c {
+			return true
+		}
+	}
+	return false
+}
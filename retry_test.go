@@ -0,0 +1,118 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetry(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		switch {
+		case r.URL.Path == "/pulse/time/0":
+			http.NotFound(w, r)
+		case n <= 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write(buf)
+		}
+	}))
+	defer srv.Close()
+
+	policy := DefaultRetryPolicy
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRetry(policy))
+
+	if _, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("expected 3 attempts, got %d", hits)
+	}
+
+	atomic.StoreInt32(&hits, 10)
+	if _, err := c.CurrentRecord(context.Background(), time.Unix(0, 0)); err == nil {
+		t.Fatal("expected a 404 to fail")
+	}
+	if hits != 11 {
+		t.Errorf("expected a 404 not to be retried, got %d attempts", hits-10)
+	}
+}
+
+func TestClientRetryTimeout(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/pulse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits int32
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+			return
+		}
+		w.Write(buf)
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	policy := DefaultRetryPolicy
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRetry(policy), WithTimeout(50*time.Millisecond))
+	if _, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 2 {
+		t.Errorf("expected the timed out attempt to be retried, got %d attempts", n)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	atomic.StoreInt32(&hits, 0)
+	slow := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRetry(policy), WithTimeout(time.Second))
+	if _, err := slow.CurrentRecord(ctx, time.Unix(1577836800, 0)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline to end the retries, got %v", err)
+	}
+	// the fetch is cancelled with the caller, so no retry follows in the background
+	time.Sleep(10 * policy.MaxDelay)
+	if n := atomic.LoadInt32(&hits); n != 1 {
+		t.Errorf("expected a single attempt, the caller's deadline ending the retries, got %d", n)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	for _, retry := range []int{0, 3, 4, 34, 63, 64, 1000} {
+		for range 20 {
+			if d := p.Delay(retry); d < 0 || d > p.MaxDelay || retry == 0 && d > p.BaseDelay {
+				t.Fatalf("unexpected delay %s before retry %d", d, retry)
+			}
+		}
+	}
+	// doubling the delay of late retries stops before it overflows and wraps around to a shorter one, 4.2e18ns for the 41st
+	p.MaxDelay = math.MaxInt64
+	var longest time.Duration
+	for range 100 {
+		longest = max(longest, p.Delay(41))
+	}
+	if longest < p.MaxDelay/10*6 {
+		t.Errorf("expected the delays of late retries to keep growing, the longest was %s", longest)
+	}
+}
@@ -0,0 +1,122 @@
+package beacon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Schedule tells when a scheduled job is due next
+type Schedule interface {
+	// Next returns the earliest pulse timestamp after t at which the job is due
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc adapts a function to the Schedule interface
+type ScheduleFunc func(t time.Time) time.Time
+
+// Next calls f(t)
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// EveryPulse schedules a job on every pulse
+func EveryPulse() Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Add(time.Nanosecond)
+	})
+}
+
+// Every schedules a job on the first pulse of every interval of length d, the intervals being aligned on midnight UTC when d divides a day
+func Every(d time.Duration) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time {
+		return t.Truncate(d).Add(d)
+	})
+}
+
+// Daily schedules a job on the first pulse at or after hour:minute UTC every day
+func Daily(hour, minute int) Schedule {
+	return ScheduleFunc(func(t time.Time) time.Time {
+		t = t.UTC()
+		next := time.Date(t.Year(), t.Month(), t.Day(), hour, minute, 0, 0, time.UTC)
+		if !next.After(t) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	})
+}
+
+// JobFunc is a scheduled job, called with the verified pulse that triggered it
+type JobFunc func(ctx context.Context, rec Record) error
+
+type job struct {
+	name  string
+	sched Schedule
+	fn    JobFunc
+	// next is the timestamp from which the job is due, zero until the scheduler saw a pulse
+	next time.Time
+}
+
+// Scheduler runs jobs on the pulses of a beacon as they are published, like cron does on the clock. Jobs are only ever triggered by
+// pulses the client verified. It is safe for concurrent use.
+type Scheduler struct {
+	client *Client
+
+	mu   sync.Mutex
+	jobs []*job
+}
+
+// NewScheduler returns a scheduler running its jobs on the pulses of c
+func NewScheduler(c *Client) *Scheduler {
+	return &Scheduler{client: c}
+}
+
+// Add schedules fn, named name in logs. The job is first due at the first pulse the scheduler sees, or after it if the schedule says so:
+// a daily job added after its hour waits for the next day.
+func (s *Scheduler) Add(name string, sched Schedule, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, &job{name: name, sched: sched, fn: fn})
+}
+
+// Start watches the beacon and runs the due jobs on every new pulse once it's verified, until ctx is done or the returned watcher is
+// stopped. The jobs due on a pulse run one after the other, in the order they were added, from the watcher's goroutine: a slow job
+// delays the next poll. A failed job is logged and scheduled again as if it succeeded; a pulse failing verification is logged and
+// triggers no job. A job whose time passed while the beacon was unreachable runs once, on the first pulse after it.
+func (s *Scheduler) Start(ctx context.Context, opts ...WatcherOption) *Watcher {
+	run := func(rec Record) {
+		if err := s.client.Verify(ctx, rec); err != nil {
+			s.client.log.Warn("Scheduler skipped an unverified pulse", pulseAttrs(rec), "err", err)
+			return
+		}
+		s.dispatch(ctx, rec)
+	}
+	return s.client.Watch(ctx, append(opts, WithCallback(run))...)
+}
+
+// dispatch runs the jobs due at rec
+func (s *Scheduler) dispatch(ctx context.Context, rec Record) {
+	ts := rec.Pulse.TimeStamp
+	s.mu.Lock()
+	var due []*job
+	for _, j := range s.jobs {
+		if j.next.IsZero() {
+			j.next = j.sched.Next(ts.Add(-time.Nanosecond))
+		}
+		if !ts.Before(j.next) {
+			due = append(due, j)
+			j.next = j.sched.Next(ts)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, j := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.client.log.Debug("Running scheduled job", "job", j.name, pulseAttrs(rec))
+		if err := j.fn(ctx, rec); err != nil {
+			s.client.log.Warn("Scheduled job failed", "job", j.name, pulseAttrs(rec), "err", err)
+		}
+	}
+}
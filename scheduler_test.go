@@ -0,0 +1,124 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedules(t *testing.T) {
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	tests := []struct {
+		name  string
+		sched Schedule
+		after string
+		want  string
+	}{
+		{"pulse", EveryPulse(), "2024-03-01T08:59:00Z", "2024-03-01T08:59:00.000000001Z"},
+		{"hourly", Every(time.Hour), "2024-03-01T08:59:00Z", "2024-03-01T09:00:00Z"},
+		{"hourly on the hour", Every(time.Hour), "2024-03-01T09:00:00Z", "2024-03-01T10:00:00Z"},
+		{"daily before", Daily(9, 0), "2024-03-01T08:59:00Z", "2024-03-01T09:00:00Z"},
+		{"daily at", Daily(9, 0), "2024-03-01T09:00:00Z", "2024-03-02T09:00:00Z"},
+		{"daily after", Daily(9, 0), "2024-02-29T15:00:00Z", "2024-03-01T09:00:00Z"},
+		{"daily in another zone", Daily(9, 0), "2024-03-01T09:30:00+01:00", "2024-03-01T09:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.sched.Next(at(tt.after)); !got.Equal(at(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerDispatch(t *testing.T) {
+	s := NewScheduler(NewClient())
+	var got []string
+	record := func(name string) JobFunc {
+		return func(ctx context.Context, rec Record) error {
+			got = append(got, rec.Pulse.TimeStamp.Format("Jan 2 15:04 ")+name)
+			return errors.New("ignored")
+		}
+	}
+	s.Add("pulse", EveryPulse(), record("pulse"))
+	s.Add("daily", Daily(9, 0), record("daily"))
+
+	rec := fixtureRecord(t)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, ts := range []time.Duration{
+		8*time.Hour + 59*time.Minute,
+		9*time.Hour + 1*time.Minute, // the 09:00 pulse is missing
+		9*time.Hour + 2*time.Minute,
+		33 * time.Hour, // the next day's
+	} {
+		rec.Pulse.TimeStamp = day.Add(ts)
+		s.dispatch(context.Background(), rec)
+	}
+
+	want := []string{"Mar 1 08:59 pulse", "Mar 1 09:01 pulse", "Mar 1 09:01 daily", "Mar 1 09:02 pulse", "Mar 2 09:00 pulse", "Mar 2 09:00 daily"}
+	if len(got) != len(want) {
+		t.Fatalf("got jobs %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got jobs %q, want %q", got, want)
+			break
+		}
+	}
+
+	// a daily job added past its hour waits for the next day
+	got = nil
+	s.Add("late", Daily(9, 0), record("late"))
+	rec.Pulse.TimeStamp = day.Add(34 * time.Hour)
+	s.dispatch(context.Background(), rec)
+	if len(got) != 1 || got[0] != "Mar 2 10:00 pulse" {
+		t.Errorf("got jobs %q", got)
+	}
+}
+
+func TestSchedulerStart(t *testing.T) {
+	var forge atomic.Bool
+	c := signingClient(t, 50*time.Millisecond, &forge)
+
+	var mu sync.Mutex
+	var got []Record
+	s := NewScheduler(c)
+	s.Add("pulse", EveryPulse(), func(ctx context.Context, rec Record) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, rec)
+		return nil
+	})
+	count := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got)
+	}
+
+	forge.Store(true)
+	w := s.Start(context.Background(), WithRetryInterval(10*time.Millisecond))
+	defer w.Stop()
+	time.Sleep(120 * time.Millisecond)
+	if n := count(); n != 0 {
+		t.Fatalf("%d jobs ran on forged pulses", n)
+	}
+
+	forge.Store(false)
+	waitFor(t, "a job to run", func() bool { return count() > 1 })
+	mu.Lock()
+	defer mu.Unlock()
+	for _, rec := range got {
+		if err := c.Verify(context.Background(), rec); err != nil {
+			t.Error(err)
+		}
+	}
+}
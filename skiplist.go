@@ -0,0 +1,165 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// skiplistLevels are the list value types linking pulses, from the finest to the coarsest. The list value of a given type of the first
+// pulse of a period references the first pulse of the preceding period, so the first pulses of every hour, day, month and year form shortcuts over the chain.
+var skiplistLevels = []string{"previous", "hour", "day", "month", "year"}
+
+// levelStart returns the start of the skiplist period of the given level containing t
+func levelStart(t time.Time, level int) time.Time {
+	t = t.UTC()
+	switch level {
+	case 1:
+		return t.Truncate(time.Hour)
+	case 2:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case 3:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// levelNext returns the start of the skiplist period of the given level following the one containing t
+func levelNext(t time.Time, level int) time.Time {
+	start := levelStart(t, level)
+	switch level {
+	case 1:
+		return start.Add(time.Hour)
+	case 2:
+		return start.AddDate(0, 0, 1)
+	case 3:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(1, 0, 0)
+	}
+}
+
+// skiplistStep fetches the next pulse of the skiplist at the given level: the next pulse for level 0, or else the first pulse of the next period
+func (c *Client) skiplistStep(ctx context.Context, cur Record, level int) (Record, error) {
+	if level == 0 {
+		return c.NextRecord(ctx, cur.Pulse.TimeStamp)
+	}
+	return c.NextRecord(ctx, levelNext(cur.Pulse.TimeStamp, level).Add(-time.Second))
+}
+
+// SkiplistPath fetches a path of pulses from old to new in which every pulse references the output value of the one before it,
+// climbing the hour, day, month and year shortcuts of Beacon 2.0 instead of walking every link. The path holds at most a few hundred
+// pulses whatever the distance between old and new. Check it with VerifySkiplist.
+func (c *Client) SkiplistPath(ctx context.Context, old, new Record) ([]Record, error) {
+	if !old.Pulse.TimeStamp.Before(new.Pulse.TimeStamp) {
+		return nil, errors.New("The old pulse must precede the new one")
+	}
+
+	path := []Record{old}
+	cur := old
+	err := skiplistWalk(old.Pulse.TimeStamp, new.Pulse.TimeStamp, func(level int) (time.Time, error) {
+		next, err := c.skiplistStep(ctx, cur, level)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !next.Pulse.TimeStamp.After(cur.Pulse.TimeStamp) {
+			return time.Time{}, errors.New("The beacon returned a pulse out of order")
+		}
+		cur = next
+		path = append(path, cur)
+		return cur.Pulse.TimeStamp, nil
+	})
+	if err != nil {
+		return path, err
+	}
+
+	if !strings.EqualFold(cur.Pulse.OutputValue, new.Pulse.OutputValue) {
+		return path, errors.New("The path doesn't lead to the new pulse")
+	}
+	return path, nil
+}
+
+// skiplistWalk walks the skiplist from the pulse at old to the one at new, calling advance to step from the current pulse at the
+// given level, as skiplistStep does, and to learn the timestamp of the pulse it reached
+func skiplistWalk(old, new time.Time, advance func(level int) (time.Time, error)) error {
+	cur := old
+	step := func(level int) error {
+		next, err := advance(level)
+		cur = next
+		return err
+	}
+
+	// climb until cur shares a period with new, then descend level by level
+	top := len(skiplistLevels) - 1
+	for level := 1; level < len(skiplistLevels); level++ {
+		if levelStart(cur, level).Equal(levelStart(new, level)) {
+			top = level - 1
+			break
+		}
+		for end := levelNext(cur, level); cur.Before(end); {
+			if err := step(level - 1); err != nil {
+				return err
+			}
+		}
+	}
+	for level := top; level > 0; level-- {
+		for levelStart(cur, level).Before(levelStart(new, level)) {
+			if err := step(level); err != nil {
+				return err
+			}
+		}
+	}
+	for cur.Before(new) {
+		if err := step(0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skiplistLength estimates how many pulses SkiplistPath fetches from the pulse at old to the one at new, assuming the beacon
+// pulsed every period in between
+func skiplistLength(old, new time.Time, period time.Duration) int {
+	n := 0
+	cur := old
+	skiplistWalk(old, new, func(level int) (time.Time, error) {
+		n++
+		if level == 0 {
+			cur = cur.Add(period)
+		} else {
+			cur = levelNext(cur, level)
+		}
+		return cur, nil
+	})
+	return n
+}
+
+// references reports whether rec has a list value referencing the output value of prev, and its uri when both are known
+func (rec *Record) references(prev Record) bool {
+	for _, v := range rec.Pulse.ListValues {
+		if !strings.EqualFold(v.Value, prev.Pulse.OutputValue) {
+			continue
+		}
+		if v.URI == "" || prev.Pulse.URI == "" || v.URI == prev.Pulse.URI {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifySkiplist checks that every pulse of path, ordered from oldest to newest, references the output value of the pulse before it
+// through one of its list values. Once the last pulse has been verified, this proves the first one belongs to its chain.
+// The returned error is a *LinkError identifying the first broken link.
+func VerifySkiplist(path []Record) error {
+	for i := 1; i < len(path); i++ {
+		if !path[i].Pulse.TimeStamp.After(path[i-1].Pulse.TimeStamp) {
+			return &LinkError{Index: i, Err: errors.New("Pulses are out of order")}
+		}
+		if !path[i].references(path[i-1]) {
+			return &LinkError{Index: i, Err: errors.New("No list value references the previous pulse's output value")}
+		}
+	}
+	return nil
+}
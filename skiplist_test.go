@@ -0,0 +1,45 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestSkiplistPath(t *testing.T) {
+	// six weeks of pulses every half hour, crossing at least one month boundary
+	origin := time.Now().UTC().AddDate(0, 0, -42).Truncate(24 * time.Hour).Add(-2 * time.Hour)
+	srv := beacontest.NewServer(beacontest.WithOrigin(origin), beacontest.WithPeriod(30*time.Minute))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	old, err := c.CurrentRecord(ctx, origin.Add(45*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := c.CurrentRecord(ctx, origin.Add(40*24*time.Hour+5*time.Hour+35*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := c.SkiplistPath(ctx, old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := beacon.VerifySkiplist(path); err != nil {
+		t.Fatal(err)
+	}
+	walked := new.Pulse.PulseIndex - old.Pulse.PulseIndex
+	if len(path) >= walked {
+		t.Errorf("the path of %d pulses is no shorter than the %d links between them", len(path), walked)
+	}
+
+	path[len(path)/2].Pulse.OutputValue = path[0].Pulse.OutputValue
+	if err := beacon.VerifySkiplist(path); err == nil {
+		t.Error("expected a tampered path to fail")
+	}
+}
@@ -0,0 +1,74 @@
+package beacon
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// BeaconSource is a provider of public randomness pulses. Client implements it for the NIST beacon and for beacons speaking the same
+// 2.0 protocol; the drand subpackage adapts the League of Entropy network.
+type BeaconSource interface {
+	// Last returns the latest pulse
+	Last(ctx context.Context) (Record, error)
+	// At returns the pulse for the given time
+	At(ctx context.Context, t time.Time) (Record, error)
+	// Next returns the first pulse after the given time
+	Next(ctx context.Context, t time.Time) (Record, error)
+	// Previous returns the last pulse before the given time
+	Previous(ctx context.Context, t time.Time) (Record, error)
+}
+
+var _ BeaconSource = (*Client)(nil)
+
+// Base URLs of other public beacons implementing the NIST Beacon 2.0 protocol
+const (
+	UChileBaseURL  = "https://random.uchile.cl/beacon/2.0"
+	InmetroBaseURL = "https://beacon.inmetro.gov.br/beacon/2.0"
+)
+
+// knownBeacons maps the names of the public beacons implementing the NIST Beacon 2.0 protocol to their base URL
+var knownBeacons = map[string]string{
+	"nist":    DefaultBaseURL,
+	"uchile":  UChileBaseURL,
+	"inmetro": InmetroBaseURL,
+}
+
+// KnownBeaconURL returns the base URL of a public beacon by name, "nist", "uchile" or "inmetro", so configurations can switch beacons by name
+func KnownBeaconURL(name string) (string, bool) {
+	url, ok := knownBeacons[strings.ToLower(name)]
+	return url, ok
+}
+
+// NewUChileClient returns a client for the Random UChile beacon. Its pulses follow the 2.0 format and are verified with the
+// certificates it publishes, fetched from it like NIST's.
+func NewUChileClient(opts ...Option) *Client {
+	return NewClient(append([]Option{WithBaseURL(UChileBaseURL)}, opts...)...)
+}
+
+// NewInmetroClient returns a client for the Brazilian beacon operated by Inmetro. Its pulses follow the 2.0 format and are verified
+// with the certificates it publishes, which are issued under the ICP-Brasil infrastructure: pass its roots with WithCertificateRoots
+// to reject certificates it didn't issue.
+func NewInmetroClient(opts ...Option) *Client {
+	return NewClient(append([]Option{WithBaseURL(InmetroBaseURL)}, opts...)...)
+}
+
+// Last is LastRecord, so Client implements BeaconSource
+func (c *Client) Last(ctx context.Context) (Record, error) {
+	return c.LastRecord(ctx)
+}
+
+// At is CurrentRecord, so Client implements BeaconSource
+func (c *Client) At(ctx context.Context, t time.Time) (Record, error) {
+	return c.CurrentRecord(ctx, t)
+}
+
+// Next is NextRecord, so Client implements BeaconSource
+func (c *Client) Next(ctx context.Context, t time.Time) (Record, error) {
+	return c.NextRecord(ctx, t)
+}
+
+// Previous is PreviousRecord, so Client implements BeaconSource
+func (c *Client) Previous(ctx context.Context, t time.Time) (Record, error) {
+	return c.PreviousRecord(ctx, t)
+}
@@ -0,0 +1,24 @@
+package beacon
+
+// Bits of a pulse's status code
+const (
+	// StatusNewChain marks the first pulse of a new chain, it has no predecessor to link to
+	StatusNewChain = 1
+	// StatusGap marks a pulse emitted more than one period after its predecessor, after an outage. The chain is still intact.
+	StatusGap = 2
+)
+
+// IsNewChainStart reports whether rec is the first pulse of a new chain
+func (rec *Record) IsNewChainStart() bool {
+	return rec.Pulse.StatusCode&StatusNewChain != 0
+}
+
+// IsGap reports whether pulses are missing before rec because the beacon suffered an outage
+func (rec *Record) IsGap() bool {
+	return rec.Pulse.StatusCode&StatusGap != 0
+}
+
+// IsRegular reports whether rec directly follows its predecessor, one period after it
+func (rec *Record) IsRegular() bool {
+	return rec.Pulse.StatusCode == 0
+}
@@ -0,0 +1,21 @@
+package beacon
+
+import "testing"
+
+func TestStatusHelpers(t *testing.T) {
+	rec := fixtureRecord(t)
+	for _, tt := range []struct {
+		code                int
+		regular, start, gap bool
+	}{
+		{0, true, false, false},
+		{StatusNewChain, false, true, false},
+		{StatusGap, false, false, true},
+		{StatusNewChain | StatusGap, false, true, true},
+	} {
+		rec.Pulse.StatusCode = tt.code
+		if rec.IsRegular() != tt.regular || rec.IsNewChainStart() != tt.start || rec.IsGap() != tt.gap {
+			t.Errorf("status %d: regular=%v, new chain=%v, gap=%v", tt.code, rec.IsRegular(), rec.IsNewChainStart(), rec.IsGap())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package beacon
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// hashFramed returns the SHA-512 of parts, each prefixed by its length as a big-endian uint32
+func hashFramed(parts ...[]byte) []byte {
+	h := sha512.New()
+	var n [4]byte
+	for _, b := range parts {
+		binary.BigEndian.PutUint32(n[:], uint32(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// TimestampProof binds a document hash to a pulse. Its binding is the SHA-512 of the document hash and the pulse's output value, which
+// nobody knew before the pulse was emitted: publishing the binding along with the document proves that it was committed no earlier than
+// the pulse. The later publication itself, in a ledger, a signed log or a dated message, bounds when the document existed at the latest.
+// It marshals to JSON for publication.
+type TimestampProof struct {
+	DocumentHash string    `json:"documentHash"`
+	PulseURI     string    `json:"pulseUri"`
+	ChainIndex   int       `json:"chainIndex"`
+	PulseIndex   int       `json:"pulseIndex"`
+	TimeStamp    time.Time `json:"timeStamp"`
+	OutputValue  string    `json:"outputValue"`
+	Binding      string    `json:"binding"`
+}
+
+func timestampBinding(docHash []byte, rec Record) (string, error) {
+	out, err := rec.outputBytes()
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(hashFramed(docHash, out))), nil
+}
+
+// Prove binds docHash, the hash of a document computed with any function, to rec. Use the latest pulse to prove the freshest bound.
+func Prove(docHash []byte, rec Record) (*TimestampProof, error) {
+	if len(docHash) == 0 {
+		return nil, errors.New("No document hash to prove")
+	}
+	binding, err := timestampBinding(docHash, rec)
+	if err != nil {
+		return nil, err
+	}
+	return &TimestampProof{
+		DocumentHash: strings.ToUpper(hex.EncodeToString(docHash)),
+		PulseURI:     rec.Pulse.URI,
+		ChainIndex:   rec.Pulse.ChainIndex,
+		PulseIndex:   rec.Pulse.PulseIndex,
+		TimeStamp:    rec.Pulse.TimeStamp.UTC(),
+		OutputValue:  rec.Pulse.OutputValue,
+		Binding:      binding,
+	}, nil
+}
+
+// Statement describes what the proof establishes
+func (p *TimestampProof) Statement() string {
+	return fmt.Sprintf("Document %s was committed no earlier than pulse %d/%d, emitted at %s", fingerprint(p.DocumentHash), p.ChainIndex, p.PulseIndex, p.TimeStamp.Format(time.RFC3339))
+}
+
+// VerifyProof checks p against rec, a re-fetched or archived copy of the pulse it references, and that its binding matches the document
+// hash and the pulse. The signature of rec isn't checked, use Client.VerifyProof to fetch and verify the pulse.
+func VerifyProof(p *TimestampProof, rec Record) error {
+	if rec.Pulse.ChainIndex != p.ChainIndex || rec.Pulse.PulseIndex != p.PulseIndex || !rec.Pulse.TimeStamp.Equal(p.TimeStamp) ||
+		!strings.EqualFold(rec.Pulse.OutputValue, p.OutputValue) || (p.PulseURI != "" && rec.Pulse.URI != "" && rec.Pulse.URI != p.PulseURI) {
+		return errors.New("The proof references another pulse")
+	}
+	docHash, err := hex.DecodeString(p.DocumentHash)
+	if err != nil {
+		return fmt.Errorf("Couldn't decode the proof's document hash: %w", err)
+	}
+	binding, err := timestampBinding(docHash, rec)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(binding, p.Binding) {
+		return errors.New("The binding doesn't match the document hash and the pulse")
+	}
+	return nil
+}
+
+// VerifyProof fetches and verifies the pulse p references, then checks p against it
+func (c *Client) VerifyProof(ctx context.Context, p *TimestampProof) error {
+	rec, err := c.PulseByIndex(ctx, uint64(p.ChainIndex), uint64(p.PulseIndex))
+	if err != nil {
+		return err
+	}
+	if err := c.Verify(ctx, rec); err != nil {
+		return err
+	}
+	return VerifyProof(p, rec)
+}
@@ -0,0 +1,58 @@
+package beacon_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestTimestampProof(t *testing.T) {
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-time.Hour)))
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	rec, err := c.LastRecord(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := sha256.Sum256([]byte("minutes of the board meeting"))
+	proof, err := beacon.Prove(doc[:], rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(proof.Statement(), "no earlier than pulse") {
+		t.Errorf("unexpected statement %q", proof.Statement())
+	}
+
+	// the proof survives publication
+	buf, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var published beacon.TimestampProof
+	if err := json.Unmarshal(buf, &published); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.VerifyProof(ctx, &published); err != nil {
+		t.Fatal(err)
+	}
+
+	other := sha256.Sum256([]byte("forged minutes"))
+	forged := published
+	forged.DocumentHash = strings.ToUpper(hex.EncodeToString(other[:]))
+	if err := beacon.VerifyProof(&forged, rec); err == nil {
+		t.Error("expected a proof for another document to fail")
+	}
+	prev := srv.Records()[len(srv.Records())-2]
+	if err := beacon.VerifyProof(&published, prev); err == nil {
+		t.Error("expected a proof checked against another pulse to fail")
+	}
+}
@@ -0,0 +1,158 @@
+package beacon
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultTimeout bounds every request of a client created without WithTimeout
+const DefaultTimeout = 30 * time.Second
+
+// transportConfig holds the settings of the transport built for clients without a user supplied http client
+type transportConfig struct {
+	proxy               func(*http.Request) (*url.URL, error)
+	dialTimeout         time.Duration
+	tlsHandshakeTimeout time.Duration
+	idleConnTimeout     time.Duration
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	// pins are the SHA-256 hashes of the public keys the server's certificate chain must include one of
+	pins [][sha256.Size]byte
+}
+
+var defaultTransport = transportConfig{
+	dialTimeout:         10 * time.Second,
+	tlsHandshakeTimeout: 10 * time.Second,
+	idleConnTimeout:     90 * time.Second,
+	maxIdleConns:        10,
+	maxIdleConnsPerHost: 2,
+}
+
+func (t transportConfig) build() *http.Transport {
+	proxy := t.proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	var tlsConfig *tls.Config
+	if len(t.pins) > 0 {
+		tlsConfig = &tls.Config{VerifyConnection: t.verifyPins}
+	}
+	return &http.Transport{
+		TLSClientConfig: tlsConfig,
+		Proxy:           proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   t.dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2: true,
+		// compression is negotiated by the client itself, so that it also applies over user supplied http clients
+		DisableCompression:  true,
+		TLSHandshakeTimeout: t.tlsHandshakeTimeout,
+		IdleConnTimeout:     t.idleConnTimeout,
+		MaxIdleConns:        t.maxIdleConns,
+		MaxIdleConnsPerHost: t.maxIdleConnsPerHost,
+	}
+}
+
+// WithDialTimeout bounds how long establishing a connection may take. It is ignored if WithHTTPClient is used.
+func WithDialTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.dialTimeout = d
+	}
+}
+
+// WithTLSHandshakeTimeout bounds how long the TLS handshake may take. It is ignored if WithHTTPClient is used.
+func WithTLSHandshakeTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport.tlsHandshakeTimeout = d
+	}
+}
+
+// WithIdleConns tunes the pool of idle connections kept for reuse: how many in total, how many per host, and for how long.
+// It is ignored if WithHTTPClient is used.
+func WithIdleConns(max, maxPerHost int, timeout time.Duration) Option {
+	return func(c *Client) {
+		c.transport.maxIdleConns = max
+		c.transport.maxIdleConnsPerHost = maxPerHost
+		c.transport.idleConnTimeout = timeout
+	}
+}
+
+// acceptEncoding lists the content codings requested from the beacon unless the client is created with WithoutCompression
+const acceptEncoding = "gzip, deflate"
+
+// WithoutCompression stops the client from asking for compressed responses. Compression is negotiated by default, including over
+// an http client given with WithHTTPClient, and saves most of the bandwidth of backfilling large ranges.
+func WithoutCompression() Option {
+	return func(c *Client) {
+		c.noCompression = true
+	}
+}
+
+// decompress wraps the body of r according to its Content-Encoding
+func decompress(r *http.Response) (io.ReadCloser, error) {
+	switch enc := r.Header.Get("Content-Encoding"); enc {
+	case "", "identity":
+		return r.Body, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r.Body)
+	case "deflate":
+		return zlib.NewReader(r.Body)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", enc)
+	}
+}
+
+// SPKIHash returns the base64 encoded SHA-256 hash of the public key of cert, the form of the pins given to WithTLSPins
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WithTLSPins makes the client only accept TLS connections whose certificate chain includes a key with one of the given SPKI hashes,
+// as computed by SPKIHash, on top of the usual verification. This stops a compromised certificate authority from impersonating the beacon
+// to replay old, validly signed pulses. Pin a backup key too, or the client breaks when the beacon's key is rotated. A malformed pin matches
+// no key, and the option is ignored if WithHTTPClient is used.
+func WithTLSPins(pins ...string) Option {
+	return func(c *Client) {
+		for _, pin := range pins {
+			var sum [sha256.Size]byte
+			if buf, err := base64.StdEncoding.DecodeString(pin); err == nil && len(buf) == sha256.Size {
+				sum = [sha256.Size]byte(buf)
+			}
+			c.transport.pins = append(c.transport.pins, sum)
+		}
+	}
+}
+
+// WithTLSCertificate pins the public key of cert, the beacon's TLS server certificate or one of its issuers, as WithTLSPins does
+func WithTLSCertificate(cert *x509.Certificate) Option {
+	return WithTLSPins(SPKIHash(cert))
+}
+
+// verifyPins checks that the verified chain of a connection includes a pinned key
+func (t transportConfig) verifyPins(cs tls.ConnectionState) error {
+	// unverified certificates sent by the server prove nothing
+	for _, chain := range cs.VerifiedChains {
+		for _, cert := range chain {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			for _, pin := range t.pins {
+				if sum == pin {
+					return nil
+				}
+			}
+		}
+	}
+	return errors.New("The server's certificate chain doesn't include a pinned key")
+}
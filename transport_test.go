@@ -0,0 +1,136 @@
+package beacon
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransportOptions(t *testing.T) {
+	c := NewClient(WithDialTimeout(time.Second), WithTLSHandshakeTimeout(2*time.Second), WithIdleConns(5, 1, time.Minute))
+	tr, ok := c.http.Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("expected the client to build its own transport")
+	}
+	if tr.TLSHandshakeTimeout != 2*time.Second || tr.MaxIdleConns != 5 || tr.MaxIdleConnsPerHost != 1 || tr.IdleConnTimeout != time.Minute {
+		t.Errorf("transport options weren't applied: %+v", tr)
+	}
+
+	cli := &http.Client{}
+	if c := NewClient(WithHTTPClient(cli), WithDialTimeout(time.Second)); c.http != cli {
+		t.Error("expected the supplied http client to be used as is")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithTimeout(20*time.Millisecond))
+	if _, err := c.LastRecord(context.Background()); err == nil {
+		t.Error("expected a hung request to time out")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	rec := fixtureRecord(t)
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var accepted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Type", "application/json")
+		var zw io.WriteCloser
+		switch {
+		case strings.Contains(accepted, "deflate") && r.URL.Query().Has("deflate"):
+			w.Header().Set("Content-Encoding", "deflate")
+			zw = zlib.NewWriter(w)
+		case strings.Contains(accepted, "gzip"):
+			w.Header().Set("Content-Encoding", "gzip")
+			zw = gzip.NewWriter(w)
+		default:
+			w.Write(buf)
+			return
+		}
+		zw.Write(buf)
+		zw.Close()
+	}))
+	defer srv.Close()
+
+	for _, c := range []*Client{NewClient(WithoutVerification(), WithBaseURL(srv.URL)), NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithHTTPClient(&http.Client{}))} {
+		for _, url := range []string{srv.URL + "/pulse/1", srv.URL + "/pulse/1?deflate"} {
+			got, err := c.GetRecord(context.Background(), url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Pulse.OutputValue != rec.Pulse.OutputValue {
+				t.Errorf("%s: the record wasn't decompressed", url)
+			}
+		}
+		if accepted != acceptEncoding {
+			t.Errorf("got Accept-Encoding %q", accepted)
+		}
+	}
+
+	if _, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithoutCompression()).GetRecord(context.Background(), srv.URL+"/pulse/1"); err != nil {
+		t.Fatal(err)
+	}
+	if accepted != "" {
+		t.Errorf("expected no compression to be negotiated, got Accept-Encoding %q", accepted)
+	}
+}
+
+func TestTLSPins(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	_, other, _ := testCertificate(t)
+
+	tests := []struct {
+		name string
+		opt  Option
+		ok   bool
+	}{
+		{"no pin", WithTLSPins(), true},
+		{"server key", WithTLSCertificate(srv.Certificate()), true},
+		{"backup key", WithTLSPins(SPKIHash(other), SPKIHash(srv.Certificate())), true},
+		{"other key", WithTLSCertificate(other), false},
+		{"malformed", WithTLSPins("not a pin"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), tt.opt)
+			tr := c.http.Transport.(*http.Transport)
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{}
+			}
+			tr.TLSClientConfig.RootCAs = roots
+
+			_, err := c.CurrentRecord(context.Background(), time.Unix(1577836800, 0))
+			if ok := errors.Is(err, ErrNotFound); ok != tt.ok {
+				t.Errorf("expected the connection to succeed: %t, got %v", tt.ok, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+)
+
+// hexValueSize is the length of the hex encoding of a record's 512-bit values
+const hexValueSize = 2 * 64
+
+// WithLenientParsing makes the client accept records with malformed fields, as long as they decode, instead of rejecting them
+// as ErrMalformedResponse. It is meant for beacons known to serve non conforming records, whose verification will usually fail.
+func WithLenientParsing() Option {
+	return func(c *Client) {
+		c.lenient = true
+	}
+}
+
+// checkHex checks that the field name holds the hex encoding of a value, of size characters unless size is 0
+func checkHex(name, s string, size int) error {
+	if size > 0 && len(s) != size {
+		return fmt.Errorf("Invalid %s: expected %d hex characters, got %d", name, size, len(s))
+	}
+	if s == "" {
+		return fmt.Errorf("Invalid %s: empty value", name)
+	}
+	if err := scanHex(s); err != nil {
+		return fmt.Errorf("Invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// Validate checks that rec is well-formed: its indexes and period are positive, its timestamp is set, its 512-bit values are
+// 128 hex characters long and its signature is hex encoded. Every malformed field is reported. It doesn't check the signature, see Verify.
+func (rec *Record) Validate() error {
+	p := &rec.Pulse
+	var errs []error
+	if p.Version == "" {
+		errs = append(errs, errors.New("Invalid version: empty value"))
+	}
+	if p.Period <= 0 {
+		errs = append(errs, fmt.Errorf("Invalid period: %d", p.Period))
+	}
+	if p.ChainIndex <= 0 {
+		errs = append(errs, fmt.Errorf("Invalid chainIndex: %d", p.ChainIndex))
+	}
+	if p.PulseIndex <= 0 {
+		errs = append(errs, fmt.Errorf("Invalid pulseIndex: %d", p.PulseIndex))
+	}
+	if p.TimeStamp.IsZero() {
+		errs = append(errs, errors.New("Invalid timeStamp: empty value"))
+	}
+	if p.StatusCode < 0 {
+		errs = append(errs, fmt.Errorf("Invalid statusCode: %d", p.StatusCode))
+	}
+
+	for _, f := range [...]struct {
+		name, value string
+		size        int
+	}{
+		{"certificateId", p.CertificateID, hexValueSize},
+		{"localRandomValue", p.LocalRandomValue, hexValueSize},
+		{"external.sourceId", p.External.SourceID, hexValueSize},
+		{"external.value", p.External.Value, hexValueSize},
+		{"precommitmentValue", p.PrecommitmentValue, hexValueSize},
+		{"signatureValue", p.SignatureValue, 0},
+		{"outputValue", p.OutputValue, hexValueSize},
+	} {
+		if err := checkHex(f.name, f.value, f.size); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i, v := range p.ListValues {
+		// the name is only formatted for malformed values, so that validating well-formed records doesn't allocate
+		if err := checkHex("list value", v.Value, hexValueSize); err != nil {
+			errs = append(errs, checkHex(fmt.Sprintf("listValues[%d].value", i), v.Value, hexValueSize))
+		}
+	}
+	return errors.Join(errs...)
+}
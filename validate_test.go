@@ -0,0 +1,80 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordValidate(t *testing.T) {
+	rec := fixtureRecord(t)
+	if err := rec.Validate(); err != nil {
+		t.Fatalf("the fixture is invalid: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		tamper func(*Record)
+		field  string
+	}{
+		"short output":       {func(r *Record) { r.Pulse.OutputValue = r.Pulse.OutputValue[1:] }, "outputValue"},
+		"bad hex":            {func(r *Record) { r.Pulse.LocalRandomValue = "Z" + r.Pulse.LocalRandomValue[1:] }, "localRandomValue"},
+		"empty signature":    {func(r *Record) { r.Pulse.SignatureValue = "" }, "signatureValue"},
+		"odd signature":      {func(r *Record) { r.Pulse.SignatureValue += "0" }, "signatureValue"},
+		"long list value":    {func(r *Record) { r.Pulse.ListValues[2].Value += "00" }, "listValues[2].value"},
+		"missing pulseIndex": {func(r *Record) { r.Pulse.PulseIndex = 0 }, "pulseIndex"},
+		"missing timestamp":  {func(r *Record) { r.Pulse.TimeStamp = time.Time{} }, "timeStamp"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := fixtureRecord(t)
+			tc.tamper(&r)
+			err := r.Validate()
+			if err == nil || !strings.Contains(err.Error(), "Invalid "+tc.field+":") {
+				t.Errorf("expected %s to be reported, got %v", tc.field, err)
+			}
+		})
+	}
+
+	var empty Record
+	if err := empty.Validate(); err == nil || strings.Count(err.Error(), "\n") < 5 {
+		t.Errorf("expected every field of an empty record to be reported, got %v", err)
+	}
+}
+
+func TestClientStrictParsing(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.PrecommitmentValue = rec.Pulse.PrecommitmentValue[:64]
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	_, err = NewClient(WithoutVerification(), WithBaseURL(srv.URL)).CurrentRecord(ctx, rec.Pulse.TimeStamp)
+	if !errors.Is(err, ErrMalformedResponse) || !strings.Contains(err.Error(), "precommitmentValue") {
+		t.Errorf("expected the malformed record to be rejected, got %v", err)
+	}
+	_, err = NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithRawResponses()).CurrentRecord(ctx, rec.Pulse.TimeStamp)
+	if !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("expected the malformed raw record to be rejected, got %v", err)
+	}
+	if _, err := ParseRecord(buf); err == nil {
+		t.Error("ParseRecord accepted the malformed record")
+	}
+
+	got, err := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithLenientParsing()).CurrentRecord(ctx, rec.Pulse.TimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Pulse.PrecommitmentValue != rec.Pulse.PrecommitmentValue {
+		t.Error("the lenient client altered the record")
+	}
+}
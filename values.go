@@ -0,0 +1,157 @@
+package beacon
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Values holds the binary values of a record decoded into fixed-size arrays, for code processing many records such as archive
+// backfills: a Values can be reused across records, decoding into it doesn't allocate once its signature has grown to the size of
+// the records' signatures.
+type Values struct {
+	CertificateID      [64]byte
+	LocalRandomValue   [64]byte
+	ExternalSourceID   [64]byte
+	ExternalValue      [64]byte
+	PrecommitmentValue [64]byte
+	OutputValue        [64]byte
+	// ListValues are the previous, hour, day, month and year list values, in that order, zero if the record lacks one.
+	// Bit i of ListValuesSet tells whether ListValues[i] was set, a zero value being a valid one.
+	ListValues    [5][64]byte
+	ListValuesSet uint8
+	// Signature is sized after the signing key, 512 bytes for the 4096-bit keys of the NIST beacon's pulses
+	Signature []byte
+}
+
+// fromHexChar decodes a hex digit
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// decodeHex decodes s into dst, which must be len(s)/2 bytes long, without the allocations of hex.DecodeString.
+// Its errors are those of the hex package.
+func decodeHex(dst []byte, s string) error {
+	if len(s)%2 == 1 {
+		return hex.ErrLength
+	}
+	for i := 0; i < len(s); i += 2 {
+		a, ok := fromHexChar(s[i])
+		if !ok {
+			return hex.InvalidByteError(s[i])
+		}
+		b, ok := fromHexChar(s[i+1])
+		if !ok {
+			return hex.InvalidByteError(s[i+1])
+		}
+		dst[i/2] = a<<4 | b
+	}
+	return nil
+}
+
+// scanHex checks that s is hex encoded, with the errors of the hex package, without decoding it
+func scanHex(s string) error {
+	for i := 0; i < len(s); i++ {
+		if _, ok := fromHexChar(s[i]); !ok {
+			return hex.InvalidByteError(s[i])
+		}
+	}
+	if len(s)%2 == 1 {
+		return hex.ErrLength
+	}
+	return nil
+}
+
+// decodeFixed decodes s into dst, failing unless it is exactly the hex encoding of len(dst) bytes
+func decodeFixed(dst []byte, s string) error {
+	if len(s) != 2*len(dst) {
+		return fmt.Errorf("expected %d hex characters, got %d", 2*len(dst), len(s))
+	}
+	return decodeHex(dst, s)
+}
+
+// DecodeValues decodes the record's hex values into v without allocating, failing if one doesn't have its expected size
+func (rec *Record) DecodeValues(v *Values) error {
+	p := &rec.Pulse
+	for _, f := range [...]struct {
+		dst        []byte
+		name, hexv string
+	}{
+		{v.CertificateID[:], "certificate id", p.CertificateID},
+		{v.LocalRandomValue[:], "local random value", p.LocalRandomValue},
+		{v.ExternalSourceID[:], "external source id", p.External.SourceID},
+		{v.ExternalValue[:], "external value", p.External.Value},
+		{v.PrecommitmentValue[:], "precommitment value", p.PrecommitmentValue},
+		{v.OutputValue[:], "output value", p.OutputValue},
+	} {
+		if err := decodeFixed(f.dst, f.hexv); err != nil {
+			return fmt.Errorf("Couldn't decode the record's %s: %w", f.name, err)
+		}
+	}
+	n := len(p.SignatureValue) / 2
+	if cap(v.Signature) < n {
+		v.Signature = make([]byte, n)
+	}
+	v.Signature = v.Signature[:n]
+	if err := decodeHex(v.Signature, p.SignatureValue); err != nil {
+		return fmt.Errorf("Couldn't decode the record's signature: %w", err)
+	}
+
+	v.ListValues = [5][64]byte{}
+	v.ListValuesSet = 0
+	for i, typ := range listValueOrder {
+		lv, ok := rec.ListValue(typ)
+		if !ok {
+			continue
+		}
+		if err := decodeFixed(v.ListValues[i][:], lv.Value); err != nil {
+			return fmt.Errorf("Couldn't decode the record's %s list value: %w", typ, err)
+		}
+		v.ListValuesSet |= 1 << i
+	}
+	return nil
+}
+
+// PreviousOutputValue returns the output value of the previous pulse, its previous list value, unless the record lacked one
+func (v *Values) PreviousOutputValue() ([64]byte, bool) {
+	return v.ListValues[0], v.HasListValue(0)
+}
+
+// HasListValue reports whether ListValues[i] was set by the record
+func (v *Values) HasListValue(i int) bool {
+	return v.ListValuesSet&(1<<i) != 0
+}
+
+// encodeHex encodes b in upper case, as the beacon does
+func encodeHex(b []byte) string {
+	return strings.ToUpper(hex.EncodeToString(b))
+}
+
+// EncodeValues sets the record's hex values to those of v, reversing DecodeValues. Only the list values the record already has are set,
+// as v lacks the URIs of the others. The record's raw bytes no longer match it, so they are dropped.
+func (rec *Record) EncodeValues(v *Values) {
+	p := &rec.Pulse
+	p.CertificateID = encodeHex(v.CertificateID[:])
+	p.LocalRandomValue = encodeHex(v.LocalRandomValue[:])
+	p.External.SourceID = encodeHex(v.ExternalSourceID[:])
+	p.External.Value = encodeHex(v.ExternalValue[:])
+	p.PrecommitmentValue = encodeHex(v.PrecommitmentValue[:])
+	p.OutputValue = encodeHex(v.OutputValue[:])
+	p.SignatureValue = encodeHex(v.Signature)
+	for i, typ := range listValueOrder {
+		for j := range p.ListValues {
+			if p.ListValues[j].Type == typ {
+				p.ListValues[j].Value = encodeHex(v.ListValues[i][:])
+			}
+		}
+	}
+	rec.raw = nil
+}
@@ -0,0 +1,144 @@
+package beacon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeValues(t *testing.T) {
+	rec := fixtureRecord(t)
+	var v Values
+	if err := rec.DecodeValues(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		got  []byte
+		want string
+	}{
+		"certificate id":      {v.CertificateID[:], rec.Pulse.CertificateID},
+		"local random value":  {v.LocalRandomValue[:], rec.Pulse.LocalRandomValue},
+		"precommitment value": {v.PrecommitmentValue[:], rec.Pulse.PrecommitmentValue},
+		"output value":        {v.OutputValue[:], rec.Pulse.OutputValue},
+		"signature":           {v.Signature, rec.Pulse.SignatureValue},
+	} {
+		want, _ := hex.DecodeString(tc.want)
+		if !bytes.Equal(tc.got, want) {
+			t.Errorf("the %s was decoded into %x, expected %x", name, tc.got, want)
+		}
+	}
+	for i, typ := range listValueOrder {
+		lv, ok := rec.ListValue(typ)
+		if v.HasListValue(i) != ok {
+			t.Errorf("expected the %s list value to be reported set: %v", typ, ok)
+		}
+		if !ok {
+			continue
+		}
+		if want, _ := hex.DecodeString(lv.Value); !bytes.Equal(v.ListValues[i][:], want) {
+			t.Errorf("the %s list value was decoded into %x, expected %x", typ, v.ListValues[i], want)
+		}
+	}
+	if prev, ok := v.PreviousOutputValue(); !ok || !strings.EqualFold(hex.EncodeToString(prev[:]), rec.PreviousOutputValue()) {
+		t.Errorf("unexpected previous output value %x", prev)
+	}
+	zero := fixtureRecord(t)
+	for i := range zero.Pulse.ListValues {
+		if zero.Pulse.ListValues[i].Type == "previous" {
+			zero.Pulse.ListValues[i].Value = strings.Repeat("0", 128)
+		}
+	}
+	if err := zero.DecodeValues(&v); err != nil {
+		t.Fatal(err)
+	}
+	if prev, ok := v.PreviousOutputValue(); !ok || prev != [64]byte{} {
+		t.Error("expected a zero previous output value to be reported set")
+	}
+	first := fixtureRecord(t)
+	first.Pulse.ListValues = nil
+	if err := first.DecodeValues(&v); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.PreviousOutputValue(); ok || v.ListValuesSet != 0 {
+		t.Error("expected the missing list values to be reported unset")
+	}
+
+	if allocs := testing.AllocsPerRun(10, func() { rec.DecodeValues(&v) }); allocs != 0 {
+		t.Errorf("decoding allocated %v times", allocs)
+	}
+
+	// the signatures of the NIST beacon's 4096-bit keys are twice as long as those of the fixture
+	long := fixtureRecord(t)
+	long.Pulse.SignatureValue = strings.Repeat("A5", 512)
+	if err := long.DecodeValues(&v); err != nil || !bytes.Equal(v.Signature, bytes.Repeat([]byte{0xa5}, 512)) {
+		t.Errorf("couldn't decode a 512-byte signature: %v", err)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { rec.DecodeValues(&v); long.DecodeValues(&v) }); allocs != 0 {
+		t.Errorf("decoding signatures of both sizes allocated %v times", allocs)
+	}
+	if err := rec.DecodeValues(&v); err != nil || len(v.Signature) != len(rec.Pulse.SignatureValue)/2 {
+		t.Errorf("expected the signature to shrink back to %d bytes, got %d: %v", len(rec.Pulse.SignatureValue)/2, len(v.Signature), err)
+	}
+
+	rec.Pulse.OutputValue = rec.Pulse.OutputValue[2:]
+	if err := rec.DecodeValues(&v); err == nil || !strings.Contains(err.Error(), "output value") {
+		t.Errorf("expected the short output value to be reported, got %v", err)
+	}
+	rec = fixtureRecord(t)
+	rec.Pulse.SignatureValue = "zz" + rec.Pulse.SignatureValue[2:]
+	var invalid hex.InvalidByteError
+	if err := rec.DecodeValues(&v); !errors.As(err, &invalid) {
+		t.Errorf("expected a hex.InvalidByteError, got %v", err)
+	}
+}
+
+func TestEncodeValues(t *testing.T) {
+	want := fixtureRecord(t)
+	var v Values
+	if err := want.DecodeValues(&v); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := fixtureRecord(t)
+	p := &rec.Pulse
+	for _, s := range []*string{&p.CertificateID, &p.LocalRandomValue, &p.External.SourceID, &p.External.Value, &p.PrecommitmentValue, &p.OutputValue, &p.SignatureValue} {
+		*s = ""
+	}
+	for i := range p.ListValues {
+		p.ListValues[i].Value = ""
+	}
+	rec.EncodeValues(&v)
+	if !strings.EqualFold(p.OutputValue, want.Pulse.OutputValue) || !strings.EqualFold(p.SignatureValue, want.Pulse.SignatureValue) ||
+		!strings.EqualFold(p.External.Value, want.Pulse.External.Value) || !strings.EqualFold(rec.PreviousOutputValue(), want.PreviousOutputValue()) {
+		t.Error("expected the values to be encoded back into the record")
+	}
+	var again Values
+	if err := rec.DecodeValues(&again); err != nil || !reflect.DeepEqual(again, v) {
+		t.Errorf("expected the encoded values to decode identically: %v", err)
+	}
+}
+
+func TestSerializationAllocs(t *testing.T) {
+	key, cert, _ := testCertificate(t)
+	rec := fixtureRecord(t)
+	signRecord(t, key, &rec)
+	if err := Verify(rec, cert); err != nil {
+		t.Fatal(err)
+	}
+
+	// A handful of allocations, not one per value
+	if allocs := testing.AllocsPerRun(10, func() { rec.ComputeOutputValue() }); allocs > 8 {
+		t.Errorf("computing the output value allocated %v times", allocs)
+	}
+}
+
+func TestValidateAllocs(t *testing.T) {
+	rec := fixtureRecord(t)
+	if allocs := testing.AllocsPerRun(10, func() { rec.Validate() }); allocs != 0 {
+		t.Errorf("validating a well-formed record allocated %v times", allocs)
+	}
+}
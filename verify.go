@@ -0,0 +1,173 @@
+package beacon
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// TimeStampFormat is the layout the beacon uses to serialize timestamps
+const TimeStampFormat = "2006-01-02T15:04:05.000Z"
+
+// serializedSizeHint is the size of the serialization of a typical record and its signature, so that serializers allocate once
+const serializedSizeHint = 1280
+
+type serializer struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (s *serializer) uint32(v int) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	s.buf.Write(b[:])
+}
+
+func (s *serializer) uint64(v int) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	s.buf.Write(b[:])
+}
+
+func (s *serializer) bytes(b []byte) {
+	s.uint32(len(b))
+	s.buf.Write(b)
+}
+
+func (s *serializer) string(v string) {
+	s.uint32(len(v))
+	s.buf.WriteString(v)
+}
+
+// hex decodes v straight into the buffer
+func (s *serializer) hex(name, v string) {
+	n := len(v) / 2
+	s.uint32(n)
+	s.buf.Grow(n)
+	b := s.buf.AvailableBuffer()[:n]
+	if err := decodeHex(b, v); err != nil && s.err == nil {
+		s.err = fmt.Errorf("Couldn't decode the record's %s: %w", name, err)
+	}
+	s.buf.Write(b)
+}
+
+// listValueOrder is the order in which the reference serialization lists the standard list values
+var listValueOrder = []string{"previous", "hour", "day", "month", "year"}
+
+// canonicalListValues iterates over the record's list values in the order of the reference serialization: the standard types first,
+// whatever their order in the JSON document, then any other type in document order
+func (rec *Record) canonicalListValues(yield func(ListValue)) {
+	for _, typ := range listValueOrder {
+		if v, ok := rec.ListValue(typ); ok {
+			yield(v)
+		}
+	}
+	for _, v := range rec.Pulse.ListValues {
+		if !slices.Contains(listValueOrder, v.Type) {
+			yield(v)
+		}
+	}
+}
+
+// serializeSigned serializes the bytes of the record covered by its signature into s
+func (rec *Record) serializeSigned(s *serializer) {
+	p := &rec.Pulse
+	s.buf.Grow(serializedSizeHint)
+	s.string(p.URI)
+	s.string(p.Version)
+	s.uint32(p.CipherSuite)
+	s.uint32(p.Period)
+	s.hex("certificate id", p.CertificateID)
+	s.uint64(p.ChainIndex)
+	s.uint64(p.PulseIndex)
+	var ts [len(TimeStampFormat) + 8]byte
+	s.bytes(p.TimeStamp.UTC().AppendFormat(ts[:0], TimeStampFormat))
+	s.hex("local random value", p.LocalRandomValue)
+	s.hex("external source id", p.External.SourceID)
+	s.uint32(p.External.StatusCode)
+	s.hex("external value", p.External.Value)
+	rec.canonicalListValues(func(v ListValue) {
+		if s.err == nil {
+			s.hex(v.Type+" list value", v.Value)
+		}
+	})
+	s.hex("precommitment value", p.PrecommitmentValue)
+	s.uint32(p.StatusCode)
+}
+
+// SignedBytes reconstructs the exact byte serialization of the record covered by its signature, following the Beacon 2.0 reference:
+// uri, version, cipherSuite, period, certificateId, chainIndex, pulseIndex, timeStamp, localRandomValue, external sourceId, statusCode and value,
+// the previous, hour, day, month and year list values, precommitmentValue and statusCode.
+// Strings are prefixed with their 4 byte big-endian length, hex values are decoded and prefixed with their length, and integers are encoded big-endian.
+func (rec *Record) SignedBytes() ([]byte, error) {
+	s := &serializer{}
+	rec.serializeSigned(s)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.buf.Bytes(), nil
+}
+
+// serializeOutput serializes the record and its signature, the input of its output value, returning the signed bytes and the signature
+// as slices of the serialization
+func (rec *Record) serializeOutput(s *serializer) (signed, sig []byte) {
+	rec.serializeSigned(s)
+	n := s.buf.Len()
+	s.hex("signature", rec.Pulse.SignatureValue)
+	buf := s.buf.Bytes()
+	return buf[:n], buf[n+4:]
+}
+
+// ComputeOutputValue computes what the record's output value should be: the SHA-512 hash of its signed bytes followed by its signature
+func (rec *Record) ComputeOutputValue() ([]byte, error) {
+	s := &serializer{}
+	rec.serializeOutput(s)
+	if s.err != nil {
+		return nil, s.err
+	}
+	sum := sha512.Sum512(s.buf.Bytes())
+	return sum[:], nil
+}
+
+// Verify checks an already fetched record against the certificate that signed it, without any network access.
+// It validates the RSA signature over the record's signed bytes and that the output value is the hash of the record and its signature,
+// reporting failures of either check as ErrSignatureInvalid.
+func Verify(rec Record, cert *x509.Certificate) error {
+	if rec.Pulse.CipherSuite != 0 {
+		return errors.New(fmt.Sprintf("Unsupported cipher suite: %d", rec.Pulse.CipherSuite))
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("The certificate doesn't hold an RSA public key")
+	}
+
+	s := &serializer{}
+	signed, sig := rec.serializeOutput(s)
+	if s.err != nil {
+		return s.err
+	}
+	sum := sha512.Sum512(signed)
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA512, sum[:], sig); err != nil {
+		return &Error{Kind: ErrSignatureInvalid, Err: err}
+	}
+
+	var out [64]byte
+	if err := decodeFixed(out[:], rec.Pulse.OutputValue); err != nil {
+		return fmt.Errorf("Couldn't decode the record's output value: %w", err)
+	}
+	if sha512.Sum512(s.buf.Bytes()) != out {
+		return &Error{Kind: ErrSignatureInvalid, Err: errors.New("The output value doesn't match the hash of the record")}
+	}
+	return nil
+}
+
+// Verify checks rec against the certificate that signed it, without any network access, as the package-level Verify does
+func (rec *Record) Verify(cert *x509.Certificate) error {
+	return Verify(*rec, cert)
+}
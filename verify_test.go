@@ -0,0 +1,224 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func signRecord(t *testing.T, key *rsa.PrivateKey, rec *Record) {
+	signed, err := rec.SignedBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha512.Sum512(signed)
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA512, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Pulse.SignatureValue = strings.ToUpper(hex.EncodeToString(sig))
+
+	out, err := rec.ComputeOutputValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Pulse.OutputValue = strings.ToUpper(hex.EncodeToString(out))
+}
+
+// signingClient returns a client of a beacon publishing a signed pulse every period, pinned to its certificate and configured with
+// opts. The pulses served fail verification while forge is set.
+func signingClient(t *testing.T, period time.Duration, forge *atomic.Bool, opts ...Option) *Client {
+	key, cert, _ := testCertificate(t)
+	rec := fixtureRecord(t)
+	rec.Pulse.Period = int(period / time.Millisecond)
+	start := time.Now().Truncate(period)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := rec
+		r2.Pulse.TimeStamp = time.Now().Truncate(period)
+		r2.Pulse.PulseIndex = int(r2.Pulse.TimeStamp.Sub(start)/period) + 1
+		signRecord(t, key, &r2)
+		if forge.Load() {
+			r2.Pulse.LocalRandomValue = r2.Pulse.PrecommitmentValue
+		}
+		json.NewEncoder(w).Encode(r2)
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(append([]Option{WithBaseURL(srv.URL), WithCertificate(cert)}, opts...)...)
+}
+
+func TestFetchVerification(t *testing.T) {
+	var forge atomic.Bool
+	c := signingClient(t, time.Minute, &forge)
+	ctx := context.Background()
+	if _, err := c.LastRecord(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	forge.Store(true)
+	if _, err := c.LastRecord(ctx); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("expected a forged pulse to be rejected as it is fetched, got %v", err)
+	}
+	unverified := signingClient(t, time.Minute, &forge, WithoutVerification())
+	if _, err := unverified.LastRecord(ctx); err != nil {
+		t.Errorf("expected the forged pulse to be returned without verification, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	key, cert, _ := testCertificate(t)
+	rec := fixtureRecord(t)
+	signRecord(t, key, &rec)
+
+	if err := Verify(rec, cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Verify(cert); err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := rec
+	tampered.Pulse.StatusCode = 1
+	if err := Verify(tampered, cert); err == nil {
+		t.Error("expected a tampered record to fail verification")
+	}
+
+	tampered = rec
+	tampered.Pulse.OutputValue = strings.Repeat("0", 128)
+	if err := Verify(tampered, cert); err == nil {
+		t.Error("expected a wrong output value to fail verification")
+	}
+
+	_, other, _ := testCertificate(t)
+	if err := Verify(rec, other); err == nil {
+		t.Error("expected verification with another certificate to fail")
+	}
+}
+
+func TestSignedBytesLayout(t *testing.T) {
+	rec := fixtureRecord(t)
+	signed, err := rec.SignedBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the reference serialization, built field by field from the fixture's document rather than by the code under test
+	p := &rec.Pulse
+	var want []byte
+	str := func(b []byte) { want = append(binary.BigEndian.AppendUint32(want, uint32(len(b))), b...) }
+	hx := func(v string) {
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		str(b)
+	}
+	str([]byte(p.URI))
+	str([]byte(p.Version))
+	want = binary.BigEndian.AppendUint32(want, uint32(p.CipherSuite))
+	want = binary.BigEndian.AppendUint32(want, uint32(p.Period))
+	hx(p.CertificateID)
+	want = binary.BigEndian.AppendUint64(want, uint64(p.ChainIndex))
+	want = binary.BigEndian.AppendUint64(want, uint64(p.PulseIndex))
+	str([]byte(p.TimeStamp.UTC().Format("2006-01-02T15:04:05.000Z")))
+	hx(p.LocalRandomValue)
+	hx(p.External.SourceID)
+	want = binary.BigEndian.AppendUint32(want, uint32(p.External.StatusCode))
+	hx(p.External.Value)
+	for _, typ := range []string{"previous", "hour", "day", "month", "year"} {
+		v, ok := rec.ListValue(typ)
+		if !ok {
+			t.Fatalf("the fixture lacks the %s list value", typ)
+		}
+		hx(v.Value)
+	}
+	hx(p.PrecommitmentValue)
+	want = binary.BigEndian.AppendUint32(want, uint32(p.StatusCode))
+	if !bytes.Equal(signed, want) {
+		t.Errorf("the serialization differs from the reference layout:\n%X\nexpected\n%X", signed, want)
+	}
+
+	// the list values are serialized in the reference order whatever their order in the document
+	reordered := rec
+	reordered.Pulse.ListValues = append([]ListValue(nil), rec.Pulse.ListValues...)
+	slices.Reverse(reordered.Pulse.ListValues)
+	got, err := reordered.SignedBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, signed) {
+		t.Error("reordering the list values changed the serialization")
+	}
+}
+
+func TestPublishedPulses(t *testing.T) {
+	paths, _ := filepath.Glob("testdata/published/*.json")
+	if len(paths) == 0 {
+		t.Skip("no published pulse in testdata/published")
+	}
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec, err := ParseRecord(buf)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		pem, err := os.ReadFile(filepath.Join("testdata/published", strings.ToLower(rec.Pulse.CertificateID)+".pem"))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		cert, err := ParseCertificatePEM(pem)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !matchesID(cert, rec.Pulse.CertificateID) {
+			t.Errorf("%s: the certificate doesn't hash to the pulse's certificate id", path)
+		}
+		if err := Verify(rec, cert); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+		if out, err := rec.ComputeOutputValue(); err != nil || !strings.EqualFold(hex.EncodeToString(out), rec.Pulse.OutputValue) {
+			t.Errorf("%s: the output value isn't reproduced: %v", path, err)
+		}
+	}
+}
+
+func TestRecordEqual(t *testing.T) {
+	rec := fixtureRecord(t)
+	same := fixtureRecord(t)
+	same.Pulse.OutputValue = strings.ToLower(same.Pulse.OutputValue)
+	same.Pulse.TimeStamp = same.Pulse.TimeStamp.Local()
+	if !rec.Equal(same) {
+		t.Error("expected records differing only in hex case and time zone to be equal")
+	}
+
+	other := fixtureRecord(t)
+	other.Pulse.ListValues = slices.Clone(other.Pulse.ListValues)
+	other.Pulse.ListValues[0].Value = strings.Repeat("0", 128)
+	if rec.Equal(other) {
+		t.Error("expected records with different list values to differ")
+	}
+	other = fixtureRecord(t)
+	other.Pulse.External.StatusCode = 1
+	if rec.Equal(other) {
+		t.Error("expected records with different external values to differ")
+	}
+}
@@ -0,0 +1,54 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// NextPulseTime returns when the pulse following rec is due, one period after it
+func (rec *Record) NextPulseTime() time.Time {
+	return rec.Pulse.TimeStamp.Add(recordPeriod(*rec))
+}
+
+// Interval returns the pulse period of rec, DefaultPeriod if it has none
+func (rec *Record) Interval() time.Duration {
+	return recordPeriod(*rec)
+}
+
+// RetryInterval returns how long to wait before polling again for the pulse following rec when it is late: a twelfth of the period,
+// at most DefaultRetryInterval
+func (rec *Record) RetryInterval() time.Duration {
+	return retryInterval(recordPeriod(*rec))
+}
+
+// retryInterval returns how often a late pulse is polled for on a beacon pulsing every period
+func retryInterval(period time.Duration) time.Duration {
+	return min(DefaultRetryInterval, period/12)
+}
+
+// WaitForPulse sleeps until the pulse following the latest one is due, plus slack to leave the beacon time to publish it, then fetches it.
+// If the pulse is late it keeps retrying, every Record.RetryInterval, until ctx is done.
+func (c *Client) WaitForPulse(ctx context.Context, slack time.Duration) (Record, error) {
+	last, err := c.LastRecord(ctx)
+	if err != nil && !errors.Is(err, ErrStale) {
+		return last, err
+	}
+
+	wait := time.Until(last.NextPulseTime()) + slack
+	retry := last.RetryInterval()
+	for {
+		if err := Sleep(ctx, wait); err != nil {
+			return Record{}, err
+		}
+		rec, err := c.NextRecord(ctx, last.Pulse.TimeStamp)
+		if err == nil {
+			return rec, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return rec, err
+		}
+		c.log.Debug("The next pulse is late, retrying", "retry_in", retry)
+		wait = retry
+	}
+}
@@ -0,0 +1,33 @@
+package beacon_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+func TestWaitForPulse(t *testing.T) {
+	srv := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-5*time.Second)), beacontest.WithPeriod(time.Second))
+	defer srv.Close()
+	c := srv.Client()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	last, err := c.LastRecord(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := last.Pulse.TimeStamp.Add(time.Second); !last.NextPulseTime().Equal(want) {
+		t.Errorf("NextPulseTime = %s, expected %s", last.NextPulseTime(), want)
+	}
+
+	rec, err := c.WaitForPulse(ctx, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Pulse.PulseIndex <= last.Pulse.PulseIndex {
+		t.Errorf("got pulse %d, expected one after %d", rec.Pulse.PulseIndex, last.Pulse.PulseIndex)
+	}
+}
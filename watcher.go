@@ -0,0 +1,171 @@
+package beacon
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// DefaultRetryInterval bounds how long a Watcher waits before polling again for a late pulse or after a failed poll. By default it waits
+// a twelfth of the pulse period, doubling on each consecutive failure up to the pulse period.
+const DefaultRetryInterval = 5 * time.Second
+
+// Watcher polls the beacon once per pulse interval and delivers every new record, either to a callback or on its Records channel
+type Watcher struct {
+	client *Client
+	jitter time.Duration
+	retry  time.Duration
+	fn     func(Record)
+	gapFn  func(Gap)
+	errFn  func(Record, error)
+
+	records chan Record
+	cancel  context.CancelFunc
+	done    chan struct{}
+	once    sync.Once
+}
+
+// WatcherOption configures a Watcher
+type WatcherOption func(*Watcher)
+
+// WithJitter delays every poll by a random duration up to d, so many watchers don't hit the beacon at the same instant
+func WithJitter(d time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.jitter = d
+	}
+}
+
+// WithRetryInterval sets how long the watcher waits before retrying after a failed poll or polling again for a late pulse,
+// instead of deriving it from the pulse period
+func WithRetryInterval(d time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.retry = d
+	}
+}
+
+// WithCallback makes the watcher call fn with every new record instead of sending it on the Records channel.
+// fn is called from the watcher's goroutine, the next poll waits for it to return.
+func WithCallback(fn func(Record)) WatcherOption {
+	return func(w *Watcher) {
+		w.fn = fn
+	}
+}
+
+// WithGapCallback makes the watcher call fn before delivering a record when pulses are missing before it: the beacon flagged an
+// outage with the StatusGap status code, or more than a period elapsed since the previous record delivered, e.g. while the beacon
+// was unreachable. fn is called from the watcher's goroutine.
+func WithGapCallback(fn func(Gap)) WatcherOption {
+	return func(w *Watcher) {
+		w.gapFn = fn
+	}
+}
+
+// WithErrorCallback makes the watcher call fn with the error of every failed poll before it retries, along with the record fetched
+// despite the error: the latest one, while the beacon is late and the error is ErrStale. fn is called from the watcher's goroutine.
+func WithErrorCallback(fn func(Record, error)) WatcherOption {
+	return func(w *Watcher) {
+		w.errFn = fn
+	}
+}
+
+// Watch starts a watcher delivering every new record of the beacon until ctx is done or Stop is called
+func (c *Client) Watch(ctx context.Context, opts ...WatcherOption) *Watcher {
+	w := &Watcher{
+		client:  c,
+		records: make(chan Record),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	go w.run(ctx)
+	return w
+}
+
+// Records returns the channel new records are delivered on. It is closed once the watcher stops.
+func (w *Watcher) Records() <-chan Record {
+	return w.records
+}
+
+// Stop stops the watcher and waits for its goroutine to exit
+func (w *Watcher) Stop() {
+	w.once.Do(w.cancel)
+	<-w.done
+}
+
+func (w *Watcher) run(ctx context.Context) {
+	defer close(w.done)
+	defer close(w.records)
+	w.client.log.Debug("Watcher started")
+	defer w.client.log.Debug("Watcher stopped")
+
+	var last *Record
+	failures := 0
+	for {
+		var wait time.Duration
+		rec, err := w.client.LastRecord(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		period := w.client.pulsePeriod()
+		retry := w.retry
+		if retry <= 0 {
+			retry = retryInterval(period)
+		}
+		if err != nil {
+			wait = retry << failures
+			if wait > period || wait <= 0 {
+				wait = period
+			}
+			// requests wait for the pause a rate limited poll asked for anyway, but polling sooner would be pointless
+			wait = max(wait, retryAfter(err))
+			failures++
+			w.client.log.Warn("Watcher poll failed, restarting", "failures", failures, "retry_in", wait, "err", err)
+			if w.errFn != nil {
+				w.errFn(rec, err)
+			}
+		} else {
+			failures = 0
+			if last == nil || rec.Pulse.TimeStamp.After(last.Pulse.TimeStamp) {
+				if last != nil && w.gapFn != nil {
+					if gap, ok := gapBefore(*last, rec); ok {
+						w.client.log.Info("Beacon resumed after a gap", "missing", gap.Missing, pulseAttrs(rec))
+						w.gapFn(gap)
+					}
+				}
+				last = &rec
+				if !w.deliver(ctx, rec) {
+					return
+				}
+			}
+			wait = time.Until(rec.Pulse.TimeStamp.Add(recordPeriod(rec)))
+			if wait <= 0 {
+				// the next pulse is late
+				wait = retry
+			}
+		}
+
+		if w.jitter > 0 {
+			wait += rand.N(w.jitter)
+		}
+		if Sleep(ctx, wait) != nil {
+			return
+		}
+	}
+}
+
+func (w *Watcher) deliver(ctx context.Context, rec Record) bool {
+	if w.fn != nil {
+		w.fn(rec)
+		return true
+	}
+	select {
+	case w.records <- rec:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
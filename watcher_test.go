@@ -0,0 +1,114 @@
+package beacon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.Period = 50
+	start := time.Now().Truncate(50 * time.Millisecond)
+
+	var fail int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fail, -1) >= 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r2 := rec
+		r2.Pulse.TimeStamp = time.Now().Truncate(50 * time.Millisecond)
+		r2.Pulse.PulseIndex = int(r2.Pulse.TimeStamp.Sub(start) / (50 * time.Millisecond))
+		json.NewEncoder(w).Encode(r2)
+	}))
+	defer srv.Close()
+
+	errs := make(chan error, 1)
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL))
+	w := c.Watch(context.Background(), WithRetryInterval(10*time.Millisecond), WithJitter(5*time.Millisecond),
+		WithErrorCallback(func(_ Record, err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}))
+
+	prev := -1
+	for i := 0; i < 3; i++ {
+		select {
+		case got := <-w.Records():
+			if got.Pulse.PulseIndex <= prev {
+				t.Errorf("pulse %d delivered after %d", got.Pulse.PulseIndex, prev)
+			}
+			prev = got.Pulse.PulseIndex
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a record")
+		}
+	}
+
+	w.Stop()
+	if _, ok := <-w.Records(); ok {
+		t.Error("expected the records channel to be closed after Stop")
+	}
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrUnavailable) {
+			t.Errorf("expected the failed poll to be reported as unavailable, got %v", err)
+		}
+	default:
+		t.Error("the failed poll wasn't reported")
+	}
+}
+
+func TestWatcherGaps(t *testing.T) {
+	rec := fixtureRecord(t)
+	rec.Pulse.Period = 50
+	start := time.Now().Truncate(50 * time.Millisecond)
+
+	var polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := rec
+		r2.Pulse.TimeStamp = start
+		if atomic.AddInt32(&polls, 1) > 1 {
+			// the beacon resumes after missing three pulses
+			r2.Pulse.PulseIndex++
+			r2.Pulse.TimeStamp = start.Add(200 * time.Millisecond)
+			r2.Pulse.StatusCode = StatusGap
+		}
+		json.NewEncoder(w).Encode(r2)
+	}))
+	defer srv.Close()
+
+	gaps := make(chan Gap, 1)
+	c := NewClient(WithoutVerification(), WithBaseURL(srv.URL), WithoutStaleness())
+	w := c.Watch(context.Background(), WithRetryInterval(10*time.Millisecond), WithGapCallback(func(g Gap) { gaps <- g }))
+	defer w.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case got := <-w.Records():
+			if i == 1 && !got.IsGap() {
+				t.Error("expected the record after the gap")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a record")
+		}
+		if i == 0 && len(gaps) != 0 {
+			t.Error("a gap was reported before the first record")
+		}
+	}
+	select {
+	case g := <-gaps:
+		if g.Missing != 3 || !g.Start.Equal(start) {
+			t.Errorf("expected three pulses missing after %s, got %+v", start, g)
+		}
+	default:
+		t.Error("the gap wasn't reported")
+	}
+}
@@ -0,0 +1,184 @@
+// Package webhook POSTs every new pulse, and alerts when the beacon skips pulses or goes stale, to webhook URLs.
+// Payloads are signed with HMAC-SHA256 so receivers can authenticate them without any polling logic of their own.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+)
+
+// SignatureHeader is the header carrying the payload's signature, "sha256=" followed by the hex HMAC-SHA256 of the body
+const SignatureHeader = "X-Beacon-Signature"
+
+// Event types
+const (
+	EventPulse = "pulse"
+	// EventGap is sent along with a pulse emitted after the beacon skipped some
+	EventGap = "gap"
+	// EventStale is sent once when the beacon stops emitting pulses on time
+	EventStale = "stale"
+)
+
+// Event is the JSON payload posted to the webhooks
+type Event struct {
+	Type    string         `json:"type"`
+	Time    time.Time      `json:"time"`
+	Record  *beacon.Record `json:"record,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+// Notifier watches the beacon and posts events to its webhooks
+type Notifier struct {
+	client *beacon.Client
+	secret []byte
+	urls   []string
+	http   *http.Client
+	retry  beacon.RetryPolicy
+}
+
+// Option configures a Notifier
+type Option func(*Notifier)
+
+// WithHTTPClient sets the http client used to post events
+func WithHTTPClient(c *http.Client) Option {
+	return func(n *Notifier) {
+		n.http = c
+	}
+}
+
+// WithRetry sets how failed deliveries are retried, it defaults to beacon.DefaultRetryPolicy
+func WithRetry(p beacon.RetryPolicy) Option {
+	return func(n *Notifier) {
+		n.retry = p
+	}
+}
+
+// New returns a notifier fetching pulses with c and posting events to urls, signed with secret
+func New(c *beacon.Client, secret []byte, urls []string, opts ...Option) *Notifier {
+	n := &Notifier{
+		client: c,
+		secret: secret,
+		urls:   urls,
+		http:   http.DefaultClient,
+		retry:  beacon.DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Sign returns the value of the signature header for body
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether signature, the value of the signature header, authenticates body
+func VerifySignature(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(strings.ToLower(signature)))
+}
+
+// post delivers body to url, retrying server errors, timeouts, rate limiting and network failures
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	for i := 0; ; i++ {
+		retryable, err := n.postOnce(ctx, url, body)
+		if err == nil || !retryable || i+1 >= n.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		if beacon.Sleep(ctx, n.retry.Delay(i)) != nil {
+			return err
+		}
+	}
+}
+
+// postOnce delivers body to url once, reporting whether a failed delivery is worth retrying: a webhook rejecting the event with a
+// client error other than a timeout or rate limiting would reject it again
+func (n *Notifier) postOnce(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+		return retryable, errors.New(fmt.Sprintf("Webhook %s answered with status %d", url, resp.StatusCode))
+	}
+	return false, nil
+}
+
+// Notify posts ev to every webhook, it returns the errors of the failed deliveries
+func (n *Notifier) Notify(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, url := range n.urls {
+		if err := n.post(ctx, url, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// queueSize is how many events Run queues while the webhooks are slow to take them, before holding off the watcher
+const queueSize = 64
+
+// Run watches the beacon and notifies the webhooks until ctx is done. Events are delivered in order by another goroutine than the
+// watcher's, so slow webhooks don't delay polling. Failed deliveries are dropped after their retries.
+func (n *Notifier) Run(ctx context.Context) {
+	queue := make(chan Event, queueSize)
+	enqueue := func(ev Event) {
+		select {
+		case queue <- ev:
+		case <-ctx.Done():
+		}
+	}
+	stale := false
+	var gap *beacon.Gap
+	w := n.client.Watch(ctx,
+		beacon.WithErrorCallback(func(rec beacon.Record, err error) {
+			if errors.Is(err, beacon.ErrStale) && !stale {
+				enqueue(Event{Type: EventStale, Time: time.Now().UTC(), Record: &rec, Message: err.Error()})
+				stale = true
+			}
+		}),
+		beacon.WithGapCallback(func(g beacon.Gap) { gap = &g }),
+		beacon.WithCallback(func(rec beacon.Record) {
+			stale = false
+			if gap != nil {
+				msg := fmt.Sprintf("%d pulses were skipped before this one", gap.Missing)
+				enqueue(Event{Type: EventGap, Time: time.Now().UTC(), Record: &rec, Message: msg})
+				gap = nil
+			}
+			enqueue(Event{Type: EventPulse, Time: time.Now().UTC(), Record: &rec})
+		}))
+	defer w.Stop()
+	for {
+		select {
+		case ev := <-queue:
+			n.Notify(ctx, ev)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
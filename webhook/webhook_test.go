@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	beacon "github.com/sherlach/go-nist-beacon"
+	"github.com/sherlach/go-nist-beacon/beacontest"
+)
+
+var testRetry = beacon.RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+// receiver collects the authenticated events it receives, failing the first delivery
+func receiver(t *testing.T, secret []byte) (*httptest.Server, <-chan Event) {
+	events := make(chan Event, 16)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !VerifySignature(secret, body, r.Header.Get(SignatureHeader)) {
+			t.Error("received a payload with an invalid signature")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var ev Event
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Error(err)
+		}
+		events <- ev
+	}))
+	t.Cleanup(srv.Close)
+	return srv, events
+}
+
+func next(t *testing.T, events <-chan Event) Event {
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestNotifierPulses(t *testing.T) {
+	secret := []byte("s3cret")
+	upstream := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-5*time.Second)), beacontest.WithPeriod(time.Second))
+	defer upstream.Close()
+	hook, events := receiver(t, secret)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go New(upstream.Client(), secret, []string{hook.URL}, WithRetry(testRetry)).Run(ctx)
+
+	first := next(t, events)
+	second := next(t, events)
+	if first.Type != EventPulse || second.Type != EventPulse {
+		t.Fatalf("got %q and %q events", first.Type, second.Type)
+	}
+	if second.Record.Pulse.PulseIndex <= first.Record.Pulse.PulseIndex {
+		t.Errorf("pulse %d was notified after pulse %d", second.Record.Pulse.PulseIndex, first.Record.Pulse.PulseIndex)
+	}
+}
+
+func TestNotifierStale(t *testing.T) {
+	secret := []byte("s3cret")
+	upstream := beacontest.NewServer()
+	defer upstream.Close()
+	upstream.SetStale(30 * time.Minute)
+	hook, events := receiver(t, secret)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go New(upstream.Client(), secret, []string{hook.URL}, WithRetry(testRetry)).Run(ctx)
+
+	if ev := next(t, events); ev.Type != EventStale || ev.Record == nil {
+		t.Errorf("expected a stale event with the last record, got %+v", ev)
+	}
+}
+
+func TestNotifierSlowWebhook(t *testing.T) {
+	upstream := beacontest.NewServer(beacontest.WithOrigin(time.Now().Add(-5*time.Second)), beacontest.WithPeriod(time.Second))
+	defer upstream.Close()
+	release := make(chan struct{})
+	events := make(chan Event, 16)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var ev Event
+		json.NewDecoder(r.Body).Decode(&ev)
+		events <- ev
+	}))
+	defer hook.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go New(upstream.Client(), nil, []string{hook.URL}, WithRetry(testRetry)).Run(ctx)
+
+	// the pulses emitted while the webhook hangs are queued rather than missed by a stalled watcher
+	time.Sleep(2500 * time.Millisecond)
+	close(release)
+	prev := next(t, events)
+	for range 2 {
+		ev := next(t, events)
+		if ev.Type != EventPulse || ev.Record.Pulse.PulseIndex != prev.Record.Pulse.PulseIndex+1 {
+			t.Fatalf("expected pulse %d, got a %s event for pulse %d", prev.Record.Pulse.PulseIndex+1, ev.Type, ev.Record.Pulse.PulseIndex)
+		}
+		prev = ev
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	for status, attempts := range map[int]int32{
+		http.StatusBadRequest:          1,
+		http.StatusNotFound:            1,
+		http.StatusRequestTimeout:      3,
+		http.StatusTooManyRequests:     3,
+		http.StatusInternalServerError: 3,
+	} {
+		var calls atomic.Int32
+		hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(status)
+		}))
+		n := New(nil, nil, []string{hook.URL}, WithRetry(testRetry))
+		if err := n.Notify(context.Background(), Event{Type: EventPulse}); err == nil {
+			t.Errorf("expected the delivery answered with %d to fail", status)
+		}
+		if got := calls.Load(); got != attempts {
+			t.Errorf("expected %d attempts for status %d, got %d", attempts, status, got)
+		}
+		hook.Close()
+	}
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"type":"pulse"}`)
+	sig := Sign([]byte("a"), body)
+	if !VerifySignature([]byte("a"), body, sig) {
+		t.Error("the signature didn't verify")
+	}
+	if VerifySignature([]byte("b"), body, sig) {
+		t.Error("the signature verified with the wrong secret")
+	}
+}